@@ -0,0 +1,101 @@
+package apperrors
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Kinds of errors, test for them with errors.Is. Errors of no kind are internal failures and their message is never shown to clients.
+var (
+	ErrNotFound        = errors.New("not found")
+	ErrValidation      = errors.New("validation failed")
+	ErrConflict        = errors.New("conflict")
+	ErrUnauthorized    = errors.New("unauthorized")
+	ErrForbidden       = errors.New("forbidden")
+	ErrTooManyRequests = errors.New("too many requests")
+	ErrInternal        = errors.New("internal server error")
+)
+
+// statuses maps every kind to the HTTP status it is reported with
+var statuses = []struct {
+	kind   error
+	status int
+}{
+	{ErrNotFound, http.StatusNotFound},
+	{ErrValidation, http.StatusBadRequest},
+	{ErrConflict, http.StatusConflict},
+	{ErrUnauthorized, http.StatusUnauthorized},
+	{ErrForbidden, http.StatusForbidden},
+	{ErrTooManyRequests, http.StatusTooManyRequests},
+	{ErrInternal, http.StatusInternalServerError},
+}
+
+// FieldError tells the client what is wrong with one field of its request
+type FieldError struct {
+	Field   string `json:"field" example:"price"`
+	Message string `json:"message" example:"must be greater than 0"`
+}
+
+// Error is a domain error. Its message and fields are meant for the client, its cause only for the logs.
+type Error struct {
+	Kind    error
+	Message string
+	Fields  []FieldError
+	Cause   error
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() []error {
+	if e.Cause == nil {
+		return []error{e.Kind}
+	}
+	return []error{e.Kind, e.Cause}
+}
+
+func NotFound(message string) error {
+	return &Error{Kind: ErrNotFound, Message: message}
+}
+
+func Validation(message string) error {
+	return &Error{Kind: ErrValidation, Message: message}
+}
+
+func Validationf(format string, args ...interface{}) error {
+	return Validation(fmt.Sprintf(format, args...))
+}
+
+// Invalid reports every field of a request that breaks a rule
+func Invalid(fields ...FieldError) error {
+	return &Error{Kind: ErrValidation, Message: "request has invalid fields", Fields: fields}
+}
+
+func Conflict(message string) error {
+	return &Error{Kind: ErrConflict, Message: message}
+}
+
+func Unauthorized(message string) error {
+	return &Error{Kind: ErrUnauthorized, Message: message}
+}
+
+func Forbidden(message string) error {
+	return &Error{Kind: ErrForbidden, Message: message}
+}
+
+// Internal wraps a failure of the server itself, the client only learns that something went wrong
+func Internal(cause error) error {
+	return &Error{Kind: ErrInternal, Message: ErrInternal.Error(), Cause: cause}
+}
+
+// Status returns the HTTP status err is reported with, errors of no kind are 500
+func Status(err error) int {
+	for _, s := range statuses {
+		if errors.Is(err, s.kind) {
+			return s.status
+		}
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,55 @@
+package apperrors
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestProblemFor(t *testing.T) {
+	driverErr := errors.New("server selection error: context deadline exceeded, current topology: mongodb://admin:secret@db")
+
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		detail string
+	}{
+		{"not found", NotFound("food not found"), http.StatusNotFound, "food not found"},
+		{"validation", Validationf("%s must be positive", "price"), http.StatusBadRequest, "price must be positive"},
+		{"conflict", Conflict("email already exists"), http.StatusConflict, "email already exists"},
+		{"unauthorized", Unauthorized("Invalid API key"), http.StatusUnauthorized, "Invalid API key"},
+		{"forbidden", Forbidden("no access"), http.StatusForbidden, "no access"},
+		{"wrapped", fmt.Errorf("placing order: %w", NotFound("table not found")), http.StatusNotFound, "placing order: table not found"},
+		{"internal", Internal(driverErr), http.StatusInternalServerError, "internal server error"},
+		{"no kind", driverErr, http.StatusInternalServerError, "internal server error"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			problem := ProblemFor(test.err, "/foods/42")
+			if problem.Status != test.status || problem.Detail != test.detail {
+				t.Fatalf("got %d %q, want %d %q", problem.Status, problem.Detail, test.status, test.detail)
+			}
+			if problem.Title != http.StatusText(test.status) || problem.Type != "about:blank" || problem.Instance != "/foods/42" {
+				t.Fatalf("unexpected problem %+v", problem)
+			}
+		})
+	}
+}
+
+func TestInternalKeepsItsCause(t *testing.T) {
+	cause := errors.New("connection refused")
+	err := Internal(cause)
+	if !errors.Is(err, ErrInternal) || !errors.Is(err, cause) {
+		t.Fatalf("expected %v to be internal and wrap its cause", err)
+	}
+}
+
+func TestProblemListsInvalidFields(t *testing.T) {
+	problem := ProblemFor(Invalid(FieldError{Field: "price", Message: "must be greater than 0"}), "/foods")
+	if problem.Status != http.StatusBadRequest || len(problem.Errors) != 1 || problem.Errors[0].Field != "price" {
+		t.Fatalf("unexpected problem %+v", problem)
+	}
+}
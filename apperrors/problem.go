@@ -0,0 +1,37 @@
+package apperrors
+
+import (
+	"errors"
+	"net/http"
+)
+
+// ContentType is the media type of a Problem body
+const ContentType = "application/problem+json"
+
+// Problem is the RFC 7807 body every error response is sent with
+type Problem struct {
+	Type     string `json:"type" example:"about:blank"`
+	Title    string `json:"title" example:"Not Found"`
+	Status   int    `json:"status" example:"404"`
+	Detail   string `json:"detail,omitempty" example:"food not found"`
+	Instance string `json:"instance,omitempty" example:"/foods/42"`
+	// Errors lists the invalid fields of a request that failed validation
+	Errors []FieldError `json:"errors,omitempty"`
+}
+
+// ProblemFor describes err for the client. Only messages of errors with a kind other than internal are passed on.
+func ProblemFor(err error, instance string) Problem {
+	status := Status(err)
+	problem := Problem{Type: "about:blank", Title: http.StatusText(status), Status: status, Instance: instance}
+	if status < http.StatusInternalServerError {
+		problem.Detail = err.Error()
+	} else {
+		problem.Detail = ErrInternal.Error()
+	}
+
+	var domain *Error
+	if errors.As(err, &domain) && status < http.StatusInternalServerError {
+		problem.Errors = domain.Fields
+	}
+	return problem
+}
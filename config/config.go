@@ -0,0 +1,380 @@
+// Package config loads the settings of the server from defaults, an optional file, the environment and flags
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ShahSau/culinary-bliss/money"
+	"github.com/joho/godotenv"
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// Config is every setting the server reads at startup
+type Config struct {
+	Server   Server   `yaml:"server" toml:"server"`
+	Database Database `yaml:"database" toml:"database"`
+	Cors     Cors     `yaml:"cors" toml:"cors"`
+	Swagger  Swagger  `yaml:"swagger" toml:"swagger"`
+	Mail     Mail     `yaml:"mail" toml:"mail"`
+	Tokens   Tokens   `yaml:"tokens" toml:"tokens"`
+	// AppURL is where the front end lives, emails link to its pages
+	AppURL string `yaml:"app_url" toml:"app_url"`
+	// Currency is the ISO 4217 code every price and total is in
+	Currency string `yaml:"currency" toml:"currency"`
+}
+
+type Server struct {
+	Port int `yaml:"port" toml:"port"`
+	// ShutdownTimeout is how long in-flight requests get to finish after SIGTERM
+	ShutdownTimeout Duration `yaml:"shutdown_timeout" toml:"shutdown_timeout"`
+	// TrustedProxies are the addresses or CIDR ranges of the proxies whose X-Forwarded-For is believed.
+	// With none, the client address is the address of the connection.
+	TrustedProxies []string `yaml:"trusted_proxies" toml:"trusted_proxies"`
+}
+
+type Database struct {
+	URI  string `yaml:"uri" toml:"uri"`
+	Name string `yaml:"name" toml:"name"`
+}
+
+type Cors struct {
+	Origins []string `yaml:"origins" toml:"origins"`
+}
+
+type Swagger struct {
+	Host    string   `yaml:"host" toml:"host"`
+	Schemes []string `yaml:"schemes" toml:"schemes"`
+}
+
+type Mail struct {
+	// Driver is "smtp" to send real mail or "log" to write messages to LogFile, or the standard logger when it is empty
+	Driver  string `yaml:"driver" toml:"driver"`
+	LogFile string `yaml:"log_file" toml:"log_file"`
+	From    string `yaml:"from" toml:"from"`
+	SMTP    SMTP   `yaml:"smtp" toml:"smtp"`
+}
+
+type SMTP struct {
+	Host     string `yaml:"host" toml:"host"`
+	Port     string `yaml:"port" toml:"port"`
+	Username string `yaml:"username" toml:"username"`
+	Password string `yaml:"password" toml:"password"`
+}
+
+type Tokens struct {
+	KeyDir      string   `yaml:"key_dir" toml:"key_dir"`
+	SigningAlg  string   `yaml:"signing_alg" toml:"signing_alg"`
+	KeyRotation Duration `yaml:"key_rotation" toml:"key_rotation"`
+	Issuer      string   `yaml:"issuer" toml:"issuer"`
+	Audience    string   `yaml:"audience" toml:"audience"`
+}
+
+// Duration is a time.Duration written like "720h" in files
+type Duration struct {
+	time.Duration
+}
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+func (d *Duration) UnmarshalText(text []byte) error {
+	duration, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	d.Duration = duration
+	return nil
+}
+
+// Default is the configuration before any file, environment variable or flag is applied
+func Default() Config {
+	return Config{
+		Server:   Server{Port: 8080, ShutdownTimeout: Duration{15 * time.Second}},
+		Database: Database{Name: "CulinaryBiliss"},
+		Cors:     Cors{Origins: []string{"https://culinary-bliss.onrender.com", "http://localhost:3000", "http://localhost:8080"}},
+		Swagger:  Swagger{Host: "culinary-bliss.onrender.com", Schemes: []string{"https"}},
+		Mail:     Mail{Driver: "log"},
+		Tokens: Tokens{
+			KeyDir:      "keys",
+			SigningAlg:  "RS256",
+			KeyRotation: Duration{30 * 24 * time.Hour},
+			Issuer:      "culinary-bliss",
+			Audience:    "culinary-bliss-api",
+		},
+		AppURL:   "http://localhost:3000",
+		Currency: "USD",
+	}
+}
+
+// Load registers the configuration flags on flags, parses args and returns the validated configuration.
+// When only validation fails the configuration is returned along with the *ValidationError.
+// Later sources win: defaults, then the file named by -config or CONFIG_FILE, then environment variables
+// (a .env file is read into the environment if there is one), then flags.
+func Load(flags *flag.FlagSet, args []string) (Config, error) {
+	if err := godotenv.Load(); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return Config{}, fmt.Errorf("reading .env: %w", err)
+	}
+	return load(flags, args, os.LookupEnv)
+}
+
+func load(flags *flag.FlagSet, args []string, lookupEnv func(string) (string, bool)) (Config, error) {
+	file := flags.String("config", "", "path of a YAML or TOML configuration file")
+	port := flags.Int("port", 0, "port the server listens on")
+	dbURI := flags.String("db-uri", "", "MongoDB connection string")
+	dbName := flags.String("db-name", "", "MongoDB database name")
+	appURL := flags.String("app-url", "", "URL of the front end linked from emails")
+	if err := flags.Parse(args); err != nil {
+		return Config{}, err
+	}
+
+	cfg := Default()
+
+	if *file == "" {
+		*file, _ = lookupEnv("CONFIG_FILE")
+	}
+	if *file != "" {
+		if err := cfg.readFile(*file); err != nil {
+			return Config{}, err
+		}
+	}
+
+	if err := cfg.applyEnv(lookupEnv); err != nil {
+		return Config{}, err
+	}
+
+	flags.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "port":
+			cfg.Server.Port = *port
+		case "db-uri":
+			cfg.Database.URI = *dbURI
+		case "db-name":
+			cfg.Database.Name = *dbName
+		case "app-url":
+			cfg.AppURL = *appURL
+		}
+	})
+
+	return cfg, cfg.Validate()
+}
+
+// readFile merges the file into the configuration, the format is picked by its extension
+func (c *Config) readFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading config file: %w", err)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, c)
+	case ".toml":
+		err = toml.Unmarshal(data, c)
+	default:
+		return fmt.Errorf("config file %s must end in .yaml, .yml or .toml", path)
+	}
+	if err != nil {
+		return fmt.Errorf("parsing config file %s: %w", path, err)
+	}
+	return nil
+}
+
+// applyEnv overrides the configuration with the environment variables that are set
+func (c *Config) applyEnv(lookupEnv func(string) (string, bool)) error {
+	values := map[string]*string{
+		"DB_HOST":         &c.Database.URI,
+		"DB_NAME":         &c.Database.Name,
+		"SWAGGER_HOST":    &c.Swagger.Host,
+		"APP_URL":         &c.AppURL,
+		"MAIL_DRIVER":     &c.Mail.Driver,
+		"MAIL_LOG_FILE":   &c.Mail.LogFile,
+		"MAIL_FROM":       &c.Mail.From,
+		"SMTP_HOST":       &c.Mail.SMTP.Host,
+		"SMTP_PORT":       &c.Mail.SMTP.Port,
+		"SMTP_USERNAME":   &c.Mail.SMTP.Username,
+		"SMTP_PASSWORD":   &c.Mail.SMTP.Password,
+		"JWT_KEY_DIR":     &c.Tokens.KeyDir,
+		"JWT_SIGNING_ALG": &c.Tokens.SigningAlg,
+		"JWT_ISSUER":      &c.Tokens.Issuer,
+		"JWT_AUDIENCE":    &c.Tokens.Audience,
+		"CURRENCY":        &c.Currency,
+	}
+	for name, field := range values {
+		if value, ok := lookupEnv(name); ok && value != "" {
+			*field = value
+		}
+	}
+
+	lists := map[string]*[]string{
+		"CORS_ORIGINS":    &c.Cors.Origins,
+		"SWAGGER_SCHEMES": &c.Swagger.Schemes,
+		"TRUSTED_PROXIES": &c.Server.TrustedProxies,
+	}
+	for name, field := range lists {
+		if value, ok := lookupEnv(name); ok && value != "" {
+			*field = splitList(value)
+		}
+	}
+
+	if value, ok := lookupEnv("PORT"); ok && value != "" {
+		port, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("PORT must be a number, got %q", value)
+		}
+		c.Server.Port = port
+	}
+
+	if value, ok := lookupEnv("SHUTDOWN_TIMEOUT"); ok && value != "" {
+		if err := c.Server.ShutdownTimeout.UnmarshalText([]byte(value)); err != nil {
+			return fmt.Errorf("SHUTDOWN_TIMEOUT must be a duration like 15s, got %q", value)
+		}
+	}
+
+	if value, ok := lookupEnv("JWT_KEY_ROTATION"); ok && value != "" {
+		if err := c.Tokens.KeyRotation.UnmarshalText([]byte(value)); err != nil {
+			return fmt.Errorf("JWT_KEY_ROTATION must be a duration like 720h, got %q", value)
+		}
+	}
+
+	return nil
+}
+
+// Validate reports every setting that is missing or wrong at once
+func (c Config) Validate() error {
+	var problems []string
+	add := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	if c.Server.Port < 1 || c.Server.Port > 65535 {
+		add("server.port must be between 1 and 65535, got %d", c.Server.Port)
+	}
+	if c.Server.ShutdownTimeout.Duration <= 0 {
+		add("server.shutdown_timeout must be positive")
+	}
+	for _, proxy := range c.Server.TrustedProxies {
+		if _, _, err := net.ParseCIDR(proxy); err != nil && net.ParseIP(proxy) == nil {
+			add("server.trusted_proxies: %q is not an IP address or CIDR range", proxy)
+		}
+	}
+
+	if c.Database.URI == "" {
+		add("database.uri is required, set it in the config file, DB_HOST or -db-uri")
+	} else if !strings.HasPrefix(c.Database.URI, "mongodb://") && !strings.HasPrefix(c.Database.URI, "mongodb+srv://") {
+		add("database.uri must start with mongodb:// or mongodb+srv://")
+	}
+	if c.Database.Name == "" {
+		add("database.name is required")
+	}
+
+	for _, origin := range c.Cors.Origins {
+		if !isHTTPURL(origin) {
+			add("cors.origins: %q is not an http(s) URL", origin)
+		}
+	}
+
+	for _, scheme := range c.Swagger.Schemes {
+		if scheme != "http" && scheme != "https" {
+			add("swagger.schemes: %q must be http or https", scheme)
+		}
+	}
+
+	switch c.Mail.Driver {
+	case "log":
+	case "smtp":
+		if c.Mail.SMTP.Host == "" || c.Mail.SMTP.Port == "" || c.Mail.From == "" {
+			add("mail.smtp.host, mail.smtp.port and mail.from are required when mail.driver is smtp")
+		}
+	default:
+		add("mail.driver must be log or smtp, got %q", c.Mail.Driver)
+	}
+
+	if c.Tokens.SigningAlg != "RS256" && c.Tokens.SigningAlg != "EdDSA" {
+		add("tokens.signing_alg must be RS256 or EdDSA, got %q", c.Tokens.SigningAlg)
+	}
+	if c.Tokens.KeyDir == "" {
+		add("tokens.key_dir is required")
+	}
+	if c.Tokens.KeyRotation.Duration < 0 {
+		add("tokens.key_rotation cannot be negative, use 0 to turn rotation off")
+	}
+	if c.Tokens.Issuer == "" || c.Tokens.Audience == "" {
+		add("tokens.issuer and tokens.audience are required")
+	}
+
+	if !isHTTPURL(c.AppURL) {
+		add("app_url: %q is not an http(s) URL", c.AppURL)
+	}
+	if !money.IsCurrency(c.Currency) {
+		add("currency: %q is not a supported ISO 4217 currency code", c.Currency)
+	}
+
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// ValidationError lists every problem Validate found
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid configuration:\n  - " + strings.Join(e.Problems, "\n  - ")
+}
+
+// Redacted returns a copy that is safe to print, with passwords replaced
+func (c Config) Redacted() Config {
+	if c.Mail.SMTP.Password != "" {
+		c.Mail.SMTP.Password = redacted
+	}
+	u, err := url.Parse(c.Database.URI)
+	if err != nil {
+		// a URI that does not parse may still hold a password, so none of it is shown
+		c.Database.URI = redacted
+		return c
+	}
+	if u.User != nil {
+		if _, ok := u.User.Password(); ok {
+			u.User = url.UserPassword(u.User.Username(), redacted)
+			c.Database.URI = u.String()
+		}
+	}
+	return c
+}
+
+const redacted = "REDACTED"
+
+// YAML returns the configuration the way a config file would contain it
+func (c Config) YAML() (string, error) {
+	data, err := yaml.Marshal(c)
+	return string(data), err
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func isHTTPURL(value string) bool {
+	u, err := url.Parse(value)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/ShahSau/culinary-bliss/config"
+)
+
+// runConfig is the config subcommand. `config print` shows the configuration the server would start with, without secrets.
+func runConfig(args []string) error {
+	if len(args) == 0 || args[0] != "print" {
+		return fmt.Errorf("usage: culinary-bliss config print [flags]")
+	}
+
+	// an invalid configuration is still printed, it shows where the problems come from
+	cfg, err := config.Load(flag.NewFlagSet("config print", flag.ExitOnError), args[1:])
+	var invalid *config.ValidationError
+	if err != nil && !errors.As(err, &invalid) {
+		return err
+	}
+
+	out, yamlErr := cfg.Redacted().YAML()
+	if yamlErr != nil {
+		return yamlErr
+	}
+	fmt.Print(out)
+	return err
+}
@@ -0,0 +1,80 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/ShahSau/culinary-bliss/types"
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary		Create an API Key
+// @Description	Create a key for a machine client, scoped to a restaurant and a set of the permissions the order, order item and restaurant routes ask for. The key is only returned once, send it in the X-API-Key header.
+// @Tags			Admin
+// @Accept			json
+// @Produce		json
+// @Security		BearerAuth
+// @param Authorization header string true "Token"
+// @Param 		 api_key body types.ApiKey true "API Key"
+// @Success		201	{object}	string
+// @Failure		400	{object}	apperrors.Problem
+// @Router			/api-keys [post]
+func (ctl *Controller) CreateApiKey(c *gin.Context) {
+	var req types.ApiKey
+	if err := bindJSON(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	apiKey, key, err := ctl.svc.CreateApiKey(c.Request.Context(), actorFrom(c), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"error": false, "message": "API key created successfully, store it now as it will not be shown again", "data": apiKey, "api_key": key, "status": http.StatusCreated, "success": true})
+}
+
+// @Summary		Get all API Keys
+// @Description	Get every API key, including revoked ones. The keys themselves are never returned, only their prefix.. Filter with field=value or field[op]=value on name, restaurant_id, revoked, created_at
+// @Tags			Admin
+// @Accept			json
+// @Produce		json
+// @Security		BearerAuth
+// @param Authorization header string true "Token"
+// @Param limit query int false "Page size, 1 to 100, 20 by default"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param sort query string false "Comma separated fields to sort by, - for descending: name, created_at"
+// @Success		200	{object}	string
+// @Failure		400	{object}	apperrors.Problem
+// @Failure		500	{object}	apperrors.Problem
+// @Router			/api-keys [get]
+func (ctl *Controller) GetApiKeys(c *gin.Context) {
+	page, err := ctl.svc.GetApiKeys(c.Request.Context(), actorFrom(c), listFrom(c))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, listed("API keys retrieved successfully", page))
+}
+
+// @Summary		Revoke an API Key
+// @Description	Stop an API key from authenticating
+// @Tags			Admin
+// @Accept			json
+// @Produce		json
+// @Security		BearerAuth
+// @param Authorization header string true "Token"
+// @Param 		 id path string true "API Key ID"
+// @Success		200	{object}	string
+// @Failure		404	{object}	apperrors.Problem
+// @Router			/api-keys/{id} [delete]
+func (ctl *Controller) RevokeApiKey(c *gin.Context) {
+	apiKey, err := ctl.svc.RevokeApiKey(c.Request.Context(), actorFrom(c), c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"error": false, "message": "API key revoked successfully", "data": apiKey, "status": http.StatusOK, "success": true})
+}
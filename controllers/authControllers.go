@@ -1,87 +1,246 @@
 package controllers
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
 
-	"github.com/ShahSau/culinary-bliss/models"
 	"github.com/ShahSau/culinary-bliss/services"
 	"github.com/ShahSau/culinary-bliss/types"
 	"github.com/gin-gonic/gin"
 )
 
 // @Summary		User Login
-// @Description	user can login by giving their email and password
+// @Description	user can login by giving their email and password. If the account uses two-factor authentication the response only holds an mfa_token to pass to /login/mfa.
 // @Tags			Auth
 // @Accept			json
 // @Produce		    json
 // @Param           user body types.Loginuser true "User"
 // @Success		200	{object}	string
-// @Failure		500	{object}	string
+// @Failure		401	{object}	apperrors.Problem
+// @Failure		429	{object}	apperrors.Problem
 // @Router			/login [post]
-func Login(c *gin.Context) {
+func (ctl *Controller) Login(c *gin.Context) {
 	var user types.Loginuser
-	if err := c.ShouldBindJSON(&user); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if err := bindJSON(c, &user); err != nil {
+		c.Error(err)
 		return
 	}
 
-	foundUser, token, refreshToken, err := services.LoginUser(user, c)
+	foundUser, token, refreshToken, err := ctl.svc.LoginUser(c.Request.Context(), actorFrom(c), user)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		var throttled *services.LoginThrottledError
+		if errors.As(err, &throttled) {
+			c.Header("Retry-After", strconv.Itoa(int(throttled.RetryAfter.Seconds())))
+		}
+		var mfaRequired *services.MfaRequiredError
+		if errors.As(err, &mfaRequired) {
+			c.JSON(http.StatusOK, gin.H{"error": false, "message": "Two-factor authentication required", "mfa_required": true, "mfa_token": mfaRequired.Token, "mfa_enrollment_required": mfaRequired.EnrollmentRequired, "status": http.StatusOK, "success": true})
+			return
+		}
+		c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"error": false, "message": "User logged in successfully", "data": foundUser, "token": token, "refreshToken": refreshToken, "status": http.StatusOK, "success": true})
+	c.JSON(http.StatusOK, gin.H{"error": false, "message": "User logged in successfully", "data": types.NewUser(foundUser), "token": token, "refreshToken": refreshToken, "status": http.StatusOK, "success": true})
 }
 
 // @Summary		User Signup
-// @Description	user can signup by giving their details
+// @Description	user can signup by giving their details. The account can only login after the emailed verification link is used.
 // @Tags			Auth
 // @Accept			json
 // @Produce		    json
 // @Param 		 user body types.RegisterUser true "User"
 // @Success		201	{object}	string
-// @Failure		500	{object}	string
+// @Failure		500	{object}	apperrors.Problem
 // @Router			/register [post]
-func Register(c *gin.Context) {
-	var user models.User
-	if err := c.ShouldBindJSON(&user); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+func (ctl *Controller) Register(c *gin.Context) {
+	var user types.RegisterUser
+	if err := bindJSON(c, &user); err != nil {
+		c.Error(err)
 		return
 	}
 
-	createdUser, err := services.RegisterUser(user, c)
+	createdUser, err := ctl.svc.RegisterUser(c.Request.Context(), actorFrom(c), user)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusCreated, gin.H{"error": false, "message": "User created successfully", "data": createdUser, "status": http.StatusCreated, "success": true})
+	c.JSON(http.StatusCreated, gin.H{"error": false, "message": "User created successfully, please check your email to verify your account", "data": types.NewUser(createdUser), "status": http.StatusCreated, "success": true})
 }
 
 // @Summary		User Logout
-// @Description	user can logout by giving their user_id
+// @Description	revokes the token of the current session so it can no longer be used
 // @Tags			Auth
 // @Accept			json
 // @Produce		    json
-// @Param			user_id body string true "User ID"
+// @Security		BearerAuth
+// @param Authorization header string true "Token"
 // @Success		200	{object}	string
-// @Failure		500	{object}	string
+// @Failure		500	{object}	apperrors.Problem
 // @Router			/logout [post]
-func Logout(c *gin.Context) {
-	var user struct {
-		User_id string `json:"user_id" binding:"required"`
+func (ctl *Controller) Logout(c *gin.Context) {
+	err := ctl.svc.LogoutUser(c.Request.Context(), actorFrom(c))
+	if err != nil {
+		c.Error(err)
+		return
 	}
-	if err := c.ShouldBindJSON(&user); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+
+	c.JSON(http.StatusOK, gin.H{"error": false, "message": "User logged out successfully", "status": http.StatusOK, "success": true})
+}
+
+// @Summary		Refresh Token
+// @Description	exchange a refresh token for a new access and refresh token. Each refresh token can only be used once.
+// @Tags			Auth
+// @Accept			json
+// @Produce		    json
+// @Param           refresh_token body types.RefreshToken true "Refresh Token"
+// @Success		200	{object}	string
+// @Failure		401	{object}	apperrors.Problem
+// @Router			/token/refresh [post]
+func (ctl *Controller) RefreshToken(c *gin.Context) {
+	var req types.RefreshToken
+	if err := bindJSON(c, &req); err != nil {
+		c.Error(err)
 		return
 	}
 
-	err := services.LogoutUser(user.User_id)
+	token, refreshToken, err := ctl.svc.RefreshTokens(c.Request.Context(), actorFrom(c), req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"error": false, "message": "User logged out successfully", "status": http.StatusOK, "success": true})
+	c.JSON(http.StatusOK, gin.H{"error": false, "message": "Token refreshed successfully", "token": token, "refreshToken": refreshToken, "status": http.StatusOK, "success": true})
+}
+
+// @Summary		Forgot Password
+// @Description	email a single use password reset link to the user
+// @Tags			Auth
+// @Accept			json
+// @Produce		    json
+// @Param           email body types.ForgotPassword true "Email"
+// @Success		200	{object}	string
+// @Failure		500	{object}	apperrors.Problem
+// @Router			/password/forgot [post]
+func (ctl *Controller) ForgotPassword(c *gin.Context) {
+	var req types.ForgotPassword
+	if err := bindJSON(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	err := ctl.svc.ForgotPassword(c.Request.Context(), actorFrom(c), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"error": false, "message": "If an account with that email exists, a password reset link has been sent", "status": http.StatusOK, "success": true})
+}
+
+// @Summary		Reset Password with Token
+// @Description	set a new password using the token from a password reset email
+// @Tags			Auth
+// @Accept			json
+// @Produce		    json
+// @Param           reset body types.TokenPasswordReset true "Reset"
+// @Success		200	{object}	string
+// @Failure		400	{object}	apperrors.Problem
+// @Router			/password/reset [post]
+func (ctl *Controller) ResetPasswordWithToken(c *gin.Context) {
+	var req types.TokenPasswordReset
+	if err := bindJSON(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	err := ctl.svc.ResetPasswordWithToken(c.Request.Context(), actorFrom(c), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"error": false, "message": "Password reset successfully", "status": http.StatusOK, "success": true})
+}
+
+// @Summary		Verify Email
+// @Description	activate an account using the token from the verification email
+// @Tags			Auth
+// @Accept			json
+// @Produce		    json
+// @Param           verification body types.VerifyEmail true "Verification"
+// @Success		200	{object}	string
+// @Failure		400	{object}	apperrors.Problem
+// @Router			/verify-email [post]
+func (ctl *Controller) VerifyEmail(c *gin.Context) {
+	var req types.VerifyEmail
+	if err := bindJSON(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	user, err := ctl.svc.VerifyEmail(c.Request.Context(), actorFrom(c), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"error": false, "message": "Email verified successfully, you can now login", "data": types.NewUser(user), "status": http.StatusOK, "success": true})
+}
+
+// @Summary		Two-Factor Login
+// @Description	finish a login with the mfa_token from /login and a code from the authenticator app or a recovery code. If the login also completed an enrollment the recovery codes are returned once.
+// @Tags			Auth
+// @Accept			json
+// @Produce		    json
+// @Param           mfa body types.MfaLogin true "Two-factor code"
+// @Success		200	{object}	string
+// @Failure		401	{object}	apperrors.Problem
+// @Failure		429	{object}	apperrors.Problem
+// @Router			/login/mfa [post]
+func (ctl *Controller) LoginMfa(c *gin.Context) {
+	var req types.MfaLogin
+	if err := bindJSON(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	user, token, refreshToken, recoveryCodes, err := ctl.svc.CompleteMfaLogin(c.Request.Context(), actorFrom(c), req)
+	if err != nil {
+		var throttled *services.LoginThrottledError
+		if errors.As(err, &throttled) {
+			c.Header("Retry-After", strconv.Itoa(int(throttled.RetryAfter.Seconds())))
+		}
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"error": false, "message": "User logged in successfully", "data": types.NewUser(user), "token": token, "refreshToken": refreshToken, "recovery_codes": recoveryCodes, "status": http.StatusOK, "success": true})
+}
+
+// @Summary		Two-Factor Login Enrollment
+// @Description	get a new authenticator secret during login when the role requires two-factor authentication and the user has not set it up yet
+// @Tags			Auth
+// @Accept			json
+// @Produce		    json
+// @Param           mfa body types.MfaToken true "Two-factor token"
+// @Success		200	{object}	string
+// @Failure		401	{object}	apperrors.Problem
+// @Router			/login/mfa/enroll [post]
+func (ctl *Controller) LoginMfaEnroll(c *gin.Context) {
+	var req types.MfaToken
+	if err := bindJSON(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	secret, uri, err := ctl.svc.StartMfaLoginEnrollment(c.Request.Context(), actorFrom(c), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"error": false, "message": "Scan the secret with your authenticator app and login with a code", "data": gin.H{"secret": secret, "otpauth_uri": uri}, "status": http.StatusOK, "success": true})
 }
@@ -3,27 +3,30 @@ package controllers
 import (
 	"net/http"
 
-	"github.com/ShahSau/culinary-bliss/models"
-	"github.com/ShahSau/culinary-bliss/services"
+	"github.com/ShahSau/culinary-bliss/types"
 	"github.com/gin-gonic/gin"
 )
 
 // @Summary Get all categories
-// @Description Get all categories
+// @Description Get all categories. Filter with field=value or field[op]=value on title, created_at
 // @Tags Global
 // @Accept json
 // @Produce json
+// @Param limit query int false "Page size, 1 to 100, 20 by default"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param sort query string false "Comma separated fields to sort by, - for descending: title, created_at"
 // @Success		200	{object}	string
-// @Failure		500	{object}	string
+// @Failure		400	{object}	apperrors.Problem
+// @Failure		500	{object}	apperrors.Problem
 // @Router			/categories [get]
-func GetCategories(c *gin.Context) {
-	categories, err := services.GetCategories(c)
+func (ctl *Controller) GetCategories(c *gin.Context) {
+	page, err := ctl.svc.GetCategories(c.Request.Context(), actorFrom(c), listFrom(c))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"data": categories})
+	c.JSON(http.StatusOK, listed("Categories retrieved successfully", page))
 }
 
 // @Summary Get a category
@@ -33,13 +36,13 @@ func GetCategories(c *gin.Context) {
 // @Produce json
 // @param id path string true "Category ID"
 // @Success		200	{object}	string
-// @Failure		500	{object}	string
+// @Failure		500	{object}	apperrors.Problem
 // @Router			/categeory/{id} [get]
-func GetCategoryByID(c *gin.Context) {
+func (ctl *Controller) GetCategoryByID(c *gin.Context) {
 	id := c.Param("id")
-	category, err := services.GetCategoryByID(id, c)
+	category, err := ctl.svc.GetCategoryByID(c.Request.Context(), actorFrom(c), id)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -55,18 +58,18 @@ func GetCategoryByID(c *gin.Context) {
 // @param Authorization header string true "Token"
 // @Param category body types.Category true "Category"
 // @Success		200	{object}	string
-// @Failure		500	{object}	string
+// @Failure		500	{object}	apperrors.Problem
 // @Router			/categories [post]
-func CreateCategory(c *gin.Context) {
-	var category models.Category
-	if err := c.ShouldBindJSON(&category); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+func (ctl *Controller) CreateCategory(c *gin.Context) {
+	var category types.Category
+	if err := bindJSON(c, &category); err != nil {
+		c.Error(err)
 		return
 	}
 
-	createdCategory, err := services.CreateCategory(category, c)
+	createdCategory, err := ctl.svc.CreateCategory(c.Request.Context(), actorFrom(c), category)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -81,21 +84,21 @@ func CreateCategory(c *gin.Context) {
 // @Security BearerAuth
 // @param Authorization header string true "Token"
 // @param id path string true "Category ID"
-// @Param category body types.Category true "Category"
+// @Param category body types.CategoryUpdate true "Category"
 // @Success		200	{object}	string
-// @Failure		500	{object}	string
+// @Failure		500	{object}	apperrors.Problem
 // @Router			/categeory/{id} [put]
-func UpdateCategory(c *gin.Context) {
+func (ctl *Controller) UpdateCategory(c *gin.Context) {
 	id := c.Param("id")
-	var updatedCategory models.Category
-	if err := c.ShouldBindJSON(&updatedCategory); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	var updatedCategory types.CategoryUpdate
+	if err := bindJSON(c, &updatedCategory); err != nil {
+		c.Error(err)
 		return
 	}
 
-	category, err := services.UpdateCategory(id, updatedCategory, c)
+	category, err := ctl.svc.UpdateCategory(c.Request.Context(), actorFrom(c), id, updatedCategory)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -111,13 +114,13 @@ func UpdateCategory(c *gin.Context) {
 // @param Authorization header string true "Token"
 // @param id path string true "Category ID"
 // @Success		200	{object}	string
-// @Failure		500	{object}	string
+// @Failure		500	{object}	apperrors.Problem
 // @Router			/categeory/{id} [delete]
-func DeleteCategory(c *gin.Context) {
+func (ctl *Controller) DeleteCategory(c *gin.Context) {
 	id := c.Param("id")
-	err := services.DeleteCategory(id, c)
+	err := ctl.svc.DeleteCategory(c.Request.Context(), actorFrom(c), id)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
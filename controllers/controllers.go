@@ -0,0 +1,86 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+
+	"github.com/ShahSau/culinary-bliss/apperrors"
+	"github.com/ShahSau/culinary-bliss/listing"
+	"github.com/ShahSau/culinary-bliss/services"
+	"github.com/ShahSau/culinary-bliss/types"
+	"github.com/gin-gonic/gin"
+)
+
+// Controller turns HTTP requests into calls on the service it was given
+type Controller struct {
+	svc *services.Service
+}
+
+func New(svc *services.Service) *Controller {
+	return &Controller{svc: svc}
+}
+
+// actorFrom collects who is making the request from what the auth middleware stored on the context
+func actorFrom(c *gin.Context) services.Actor {
+	return services.Actor{
+		User_id:          c.GetString("user_id"),
+		Email:            c.GetString("email"),
+		Role:             c.GetString("role"),
+		Permissions:      c.GetStringSlice("permissions"),
+		Api_key_id:       c.GetString("api_key_id"),
+		Restaurant_id:    c.GetString("restaurant_id"),
+		Token_id:         c.GetString("jti"),
+		Family_id:        c.GetString("family_id"),
+		Token_expires_at: c.GetTime("token_expires_at"),
+		Ip_address:       c.ClientIP(),
+		User_agent:       c.Request.UserAgent(),
+	}
+}
+
+// listFrom passes the query of a list request on to the service, which checks it against what the list can be filtered and sorted by
+func listFrom(c *gin.Context) types.ListRequest {
+	return types.ListRequest{Query: c.Request.URL.Query()}
+}
+
+// listed is the response to a list request
+func listed[T any](message string, page listing.Page[T]) gin.H {
+	return gin.H{"error": false, "message": message, "data": page.Items, "total": page.Total, "limit": page.Limit, "next_cursor": page.NextCursor, "status": http.StatusOK, "success": true}
+}
+
+// bindJSON decodes the request body into req, reporting a value of the wrong type as an error on its field.
+// The services validate the decoded request.
+func bindJSON(c *gin.Context, req interface{}) error {
+	err := c.ShouldBindJSON(req)
+	if err == nil {
+		return nil
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return apperrors.Invalid(apperrors.FieldError{Field: typeErr.Field, Message: "must be " + jsonKind(typeErr.Type)})
+	}
+	return apperrors.Validation(err.Error())
+}
+
+// jsonKind names the JSON value a Go type is decoded from
+func jsonKind(t reflect.Type) string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "an integer"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.String:
+		return "a string"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	default:
+		return "an object"
+	}
+}
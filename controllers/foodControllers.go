@@ -1,33 +1,30 @@
 package controllers
 
 import (
-	"math"
 	"net/http"
 
-	"github.com/ShahSau/culinary-bliss/models"
-	"github.com/ShahSau/culinary-bliss/services"
+	"github.com/ShahSau/culinary-bliss/types"
 	"github.com/gin-gonic/gin"
 )
 
 // @Summary GetFoods
-// @Description Get all foods
+// @Description Get all foods. Filter with field=value or field[op]=value on name, price, menu_id, created_at
 // @Tags Global
 // @Produce json
-// @Param 		 recordPerPage query int false "Record Per Page"
-// @Param 		 page query int false "Page"
-// @Param 		 startIndex query int false "Start Index"
+// @Param limit query int false "Page size, 1 to 100, 20 by default"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param sort query string false "Comma separated fields to sort by, - for descending: name, price, created_at"
 // @Success 200 {object} string
-// @Failure 400 {object} string
+// @Failure 400 {object} apperrors.Problem
 // @Router /foods [get]
-func GetFoods(c *gin.Context) {
-	response, err := services.GetFoods(c)
+func (ctl *Controller) GetFoods(c *gin.Context) {
+	page, err := ctl.svc.GetFoods(c.Request.Context(), actorFrom(c), listFrom(c))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"data": response.AllFoods, "page": response.Page, "recordPerPage": response.RecordPerPage, "startIndex": response.StartIndex})
-
+	c.JSON(http.StatusOK, listed("Foods retrieved successfully", page))
 }
 
 // @Summary Get Food
@@ -37,13 +34,13 @@ func GetFoods(c *gin.Context) {
 // @Produce json
 // @Param id path string true "Food ID"
 // @Success 200 {object}  string
-// @Failure 400 {object} string
+// @Failure 400 {object} apperrors.Problem
 // @Router /food/{id} [get]
-func GetFood(c *gin.Context) {
+func (ctl *Controller) GetFood(c *gin.Context) {
 	foodId := c.Param("id")
-	food, err := services.GetFoodByID(foodId, c)
+	food, err := ctl.svc.GetFoodByID(c.Request.Context(), actorFrom(c), foodId)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -63,20 +60,20 @@ func GetFood(c *gin.Context) {
 // @Produce json
 // @Security		BearerAuth
 // @param Authorization header string true "Token"
-// @Param food body models.Food true "Food Object"
+// @Param food body types.Food true "Food Object"
 // @Success 201 {object} string
-// @Failure 400 {object} string
+// @Failure 400 {object} apperrors.Problem
 // @Router /food [post]
-func CreateFood(c *gin.Context) {
-	var reqfood models.Food
-	if err := c.ShouldBindJSON(&reqfood); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+func (ctl *Controller) CreateFood(c *gin.Context) {
+	var reqfood types.Food
+	if err := bindJSON(c, &reqfood); err != nil {
+		c.Error(err)
 		return
 	}
 
-	food, err := services.CreateFood(reqfood, c)
+	food, err := ctl.svc.CreateFood(c.Request.Context(), actorFrom(c), reqfood)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -97,23 +94,23 @@ func CreateFood(c *gin.Context) {
 // @Security		BearerAuth
 // @param Authorization header string true "Token"
 // @Param id path string true "Food ID"
-// @Param food body models.Food true "Food Object"
+// @Param food body types.FoodUpdate true "Food Object"
 // @Success 202 {object} string
-// @Failure 400 {object} string
+// @Failure 400 {object} apperrors.Problem
 // @Router /food/{id} [put]
-func UpdateFood(c *gin.Context) {
-	var food models.Food
+func (ctl *Controller) UpdateFood(c *gin.Context) {
+	var food types.FoodUpdate
 
 	foodId := c.Param("id")
 
-	if err := c.ShouldBindJSON(&food); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if err := bindJSON(c, &food); err != nil {
+		c.Error(err)
 		return
 	}
 
-	updateObj, err := services.UpdateFood(foodId, food, c)
+	updateObj, err := ctl.svc.UpdateFood(c.Request.Context(), actorFrom(c), foodId, food)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 	c.JSON(http.StatusAccepted, gin.H{
@@ -135,14 +132,14 @@ func UpdateFood(c *gin.Context) {
 // @param Authorization header string true "Token"
 // @Param id path string true "Food ID"
 // @Success 202 {object} string
-// @Failure 400 {object} string
+// @Failure 400 {object} apperrors.Problem
 // @Router /food/{id} [delete]
-func DeleteFood(c *gin.Context) {
+func (ctl *Controller) DeleteFood(c *gin.Context) {
 	foodId := c.Param("id")
 
-	_, err := services.DeleteFood(foodId, c)
+	_, err := ctl.svc.DeleteFood(c.Request.Context(), actorFrom(c), foodId)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -154,8 +151,3 @@ func DeleteFood(c *gin.Context) {
 		"data":    nil,
 	})
 }
-
-func toFixed(num float64, precision int) float64 {
-	output := math.Pow(10, float64(precision))
-	return float64(int(num*output)) / output
-}
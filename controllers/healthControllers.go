@@ -0,0 +1,86 @@
+package controllers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// readinessTimeout bounds each dependency check so a hung dependency still gets reported
+const readinessTimeout = 2 * time.Second
+
+// Check is a dependency the server needs to serve requests
+type Check struct {
+	Name string
+	Run  func(ctx context.Context) error
+}
+
+// CheckResult is how a dependency answered its readiness check. Why a check failed is only logged, the probe is not authenticated.
+type CheckResult struct {
+	Status string `json:"status"`
+}
+
+// Readiness is the answer of the readiness probe, whether the instance is ready or not
+type Readiness struct {
+	// Status is "ready" when every check is ok and "unavailable" otherwise
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks"`
+}
+
+// Health answers the liveness and readiness probes of the orchestrator
+type Health struct {
+	checks []Check
+}
+
+func NewHealth(checks ...Check) *Health {
+	return &Health{checks: checks}
+}
+
+// @Summary Liveness
+// @Description Answers as long as the process is serving requests, it checks no dependencies
+// @Tags Global
+// @Produce json
+// @Success 200 {object} string
+// @Router /healthz [get]
+func (h *Health) Healthz(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"error": false, "message": "ok", "status": http.StatusOK, "success": true})
+}
+
+// @Summary Readiness
+// @Description Checks every dependency and answers 503 when one of them is down, so no traffic is sent to this instance
+// @Tags Global
+// @Produce json
+// @Success 200 {object} Readiness
+// @Failure 503 {object} Readiness
+// @Router /readyz [get]
+func (h *Health) Readyz(c *gin.Context) {
+	readiness := Readiness{Status: "ready", Checks: map[string]CheckResult{}}
+	for _, check := range h.checks {
+		result := runCheck(c.Request.Context(), check)
+		if result.Status != "ok" {
+			readiness.Status = "unavailable"
+		}
+		readiness.Checks[check.Name] = result
+	}
+
+	if readiness.Status != "ready" {
+		c.JSON(http.StatusServiceUnavailable, readiness)
+		return
+	}
+	c.JSON(http.StatusOK, readiness)
+}
+
+func runCheck(ctx context.Context, check Check) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, readinessTimeout)
+	defer cancel()
+
+	start := time.Now()
+	if err := check.Run(ctx); err != nil {
+		log.Printf("Readiness check %s failed after %s: %v", check.Name, time.Since(start), err)
+		return CheckResult{Status: "down"}
+	}
+	return CheckResult{Status: "ok"}
+}
@@ -0,0 +1,49 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestReadyzAnswersTheSameShapeUpAndDown(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	up := Check{Name: "up", Run: func(ctx context.Context) error { return nil }}
+	down := Check{Name: "down", Run: func(ctx context.Context) error { return errors.New("connection refused") }}
+
+	tests := []struct {
+		name   string
+		checks []Check
+		code   int
+		status string
+	}{
+		{name: "ready", checks: []Check{up}, code: http.StatusOK, status: "ready"},
+		{name: "unavailable", checks: []Check{up, down}, code: http.StatusServiceUnavailable, status: "unavailable"},
+	}
+	for _, test := range tests {
+		router := gin.New()
+		router.GET("/readyz", NewHealth(test.checks...).Readyz)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+		var readiness Readiness
+		if err := json.Unmarshal(recorder.Body.Bytes(), &readiness); err != nil {
+			t.Fatal(err)
+		}
+		if recorder.Code != test.code || readiness.Status != test.status || len(readiness.Checks) != len(test.checks) {
+			t.Fatalf("%s: unexpected answer %d %s", test.name, recorder.Code, recorder.Body)
+		}
+		if result := readiness.Checks["down"]; test.status == "unavailable" && result.Status != "down" {
+			t.Fatalf("%s: expected the failed check to be reported, got %+v", test.name, result)
+		}
+		if strings.Contains(recorder.Body.String(), "connection refused") {
+			t.Fatalf("%s: expected the error to be kept from the client, got %s", test.name, recorder.Body)
+		}
+	}
+}
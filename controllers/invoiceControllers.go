@@ -3,29 +3,31 @@ package controllers
 import (
 	"net/http"
 
-	"github.com/ShahSau/culinary-bliss/models"
-	"github.com/ShahSau/culinary-bliss/services"
+	"github.com/ShahSau/culinary-bliss/types"
 	"github.com/gin-gonic/gin"
 )
 
 // @Summary Get Invoices
-// @Description Get Invoices
+// @Description Get Invoices. Filter with field=value or field[op]=value on order_id, payment_status, payment_method, total_amount, payment_due_date, created_at
 // @Tags Admin
 // @Accept json
 // @Produce json
 // @Security		BearerAuth
 // @param Authorization header string true "Token"
+// @Param limit query int false "Page size, 1 to 100, 20 by default"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param sort query string false "Comma separated fields to sort by, - for descending: total_amount, payment_due_date, created_at"
 // @Success 200 {object} models.Invoice
-// @Failure 400 {object} string
+// @Failure 400 {object} apperrors.Problem
 // @Router /invoice [get]
-func GetInvoices(c *gin.Context) {
-	results, err := services.GetInvoices(c)
+func (ctl *Controller) GetInvoices(c *gin.Context) {
+	page, err := ctl.svc.GetInvoices(c.Request.Context(), actorFrom(c), listFrom(c))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"error": false, "message": "Invoice retrived successfully", "data": results, "status": http.StatusOK, "success": true})
+	c.JSON(http.StatusOK, listed("Invoices retrieved successfully", page))
 }
 
 // @Summary Get Invoice
@@ -37,18 +39,14 @@ func GetInvoices(c *gin.Context) {
 // @param Authorization header string true "Token"
 // @Param id path string true "Invoice ID"
 // @Success 200 {object} models.Invoice
-// @Failure 400 {object} string
+// @Failure 400 {object} apperrors.Problem
 // @Router /invoice/{id} [get]
-func GetInvoice(c *gin.Context) {
+func (ctl *Controller) GetInvoice(c *gin.Context) {
 	var invoiceID = c.Param("id")
 
-	invoice, err := services.GetInvoiceByID(c, invoiceID)
+	invoice, err := ctl.svc.GetInvoiceByID(c.Request.Context(), actorFrom(c), invoiceID)
 	if err != nil {
-		if err.Error() == "invoice not found" {
-			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -64,18 +62,18 @@ func GetInvoice(c *gin.Context) {
 // @param Authorization header string true "Token"
 // @Param invoice body types.Invoice true "Invoice"
 // @Success 201 {object} models.Invoice
-// @Failure 400 {object} string
+// @Failure 400 {object} apperrors.Problem
 // @Router /invoice [post]
-func CreateInvoice(c *gin.Context) {
-	var reqInvoice models.Invoice
-	if err := c.ShouldBindJSON(&reqInvoice); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+func (ctl *Controller) CreateInvoice(c *gin.Context) {
+	var reqInvoice types.Invoice
+	if err := bindJSON(c, &reqInvoice); err != nil {
+		c.Error(err)
 		return
 	}
 
-	invoice, err := services.CreateInvoice(c, reqInvoice)
+	invoice, err := ctl.svc.CreateInvoice(c.Request.Context(), actorFrom(c), reqInvoice)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -91,22 +89,22 @@ func CreateInvoice(c *gin.Context) {
 // @Security		BearerAuth
 // @param Authorization header string true "Token"
 // @Param id path string true "Invoice ID"
-// @Param invoice body types.Invoice true "Invoice"
+// @Param invoice body types.InvoiceUpdate true "Invoice"
 // @Success 200 {object} models.Invoice
-// @Failure 400 {object} string
+// @Failure 400 {object} apperrors.Problem
 // @Router /invoice/{id} [put]
-func UpdateInvoice(c *gin.Context) {
+func (ctl *Controller) UpdateInvoice(c *gin.Context) {
 	var invoiceID = c.Param("id")
-	var reqinvoice models.Invoice
+	var reqinvoice types.InvoiceUpdate
 
-	if err := c.ShouldBindJSON(&reqinvoice); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if err := bindJSON(c, &reqinvoice); err != nil {
+		c.Error(err)
 		return
 	}
 
-	updateObj, err := services.UpdateInvoice(c, invoiceID, reqinvoice)
+	updateObj, err := ctl.svc.UpdateInvoice(c.Request.Context(), actorFrom(c), invoiceID, reqinvoice)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"error": false, "message": "Invoice updated successfully", "status": http.StatusOK, "success": true, "data": updateObj})
@@ -122,14 +120,14 @@ func UpdateInvoice(c *gin.Context) {
 // @param Authorization header string true "Token"
 // @Param id path string true "Invoice ID"
 // @Success 200 {object} string
-// @Failure 400 {object} string
+// @Failure 400 {object} apperrors.Problem
 // @Router /invoice/{id} [delete]
-func DeleteInvoice(c *gin.Context) {
+func (ctl *Controller) DeleteInvoice(c *gin.Context) {
 	var invoiceID = c.Param("id")
 
-	err := services.DeleteInvoice(c, invoiceID)
+	err := ctl.svc.DeleteInvoice(c.Request.Context(), actorFrom(c), invoiceID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
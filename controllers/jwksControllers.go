@@ -0,0 +1,20 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/ShahSau/culinary-bliss/tokens"
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary JSON Web Key Set
+// @Description Public keys other services can verify Culinary Bliss tokens with. Tokens name their key in the kid header.
+// @Tags Global
+// @Produce json
+// @Success		200	{object}	tokens.JWKS
+// @Router			/.well-known/jwks.json [get]
+func GetJWKS(c *gin.Context) {
+	// a new key signs tokens right after rotation, verifiers should refetch when they see a kid they do not know
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, tokens.GetJWKS())
+}
@@ -1,34 +1,32 @@
 package controllers
 
 import (
-	"log"
 	"net/http"
 
-	"github.com/ShahSau/culinary-bliss/models"
-	"github.com/ShahSau/culinary-bliss/services"
+	"github.com/ShahSau/culinary-bliss/types"
 	"github.com/gin-gonic/gin"
 )
 
 // @Summary Get all menus
-// @Description Get all menus
+// @Description Get all menus. Filter with field=value or field[op]=value on name, start_date, end_date, created_at
 // @Tags Global
 // @Accept json
 // @Produce json
-// @Param 		 recordPerPage query int false "Record Per Page"
-// @Param 		 page query int false "Page"
-// @Param 		 startIndex query int false "Start Index"
+// @Param limit query int false "Page size, 1 to 100, 20 by default"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param sort query string false "Comma separated fields to sort by, - for descending: name, start_date, end_date, created_at"
 // @Success 200 {object} string
-// @Failure 500 {object} string
+// @Failure 400 {object} apperrors.Problem
+// @Failure 500 {object} apperrors.Problem
 // @Router /menu [get]
-func GetMenus(c *gin.Context) {
-	response, err := services.GetMenus(c)
+func (ctl *Controller) GetMenus(c *gin.Context) {
+	page, err := ctl.svc.GetMenus(c.Request.Context(), actorFrom(c), listFrom(c))
 	if err != nil {
-		log.Fatal(err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"data": response.AllMenus, "page": response.Page, "recordPerPage": response.RecordPerPage, "startIndex": response.StartIndex})
+	c.JSON(http.StatusOK, listed("Menus retrieved successfully", page))
 }
 
 // @Summary Get a menu
@@ -38,15 +36,15 @@ func GetMenus(c *gin.Context) {
 // @Produce json
 // @Param id path string true "Menu ID"
 // @Success 200 {object} string
-// @Failure 500 {object} string
+// @Failure 404 {object} apperrors.Problem
+// @Failure 500 {object} apperrors.Problem
 // @Router /menu/{id} [get]
-func GetMenu(c *gin.Context) {
+func (ctl *Controller) GetMenu(c *gin.Context) {
 	var menuID = c.Param("id")
 
-	menu, err := services.GetMenuByID(menuID, c)
+	menu, err := ctl.svc.GetMenuByID(c.Request.Context(), actorFrom(c), menuID)
 	if err != nil {
-		log.Fatal(err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -69,19 +67,19 @@ func GetMenu(c *gin.Context) {
 // @param Authorization header string true "Token"
 // @Param menu body types.Menu true "Menu object"
 // @Success 201 {object} string
-// @Failure 500 {object} string
+// @Failure 500 {object} apperrors.Problem
 // @Router /menu [post]
-func CreateMenu(c *gin.Context) {
-	var menu models.Menu
+func (ctl *Controller) CreateMenu(c *gin.Context) {
+	var menu types.Menu
 
-	if err := c.ShouldBindJSON(&menu); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if err := bindJSON(c, &menu); err != nil {
+		c.Error(err)
 		return
 	}
 
-	reqMenu, err := services.CreateMenu(menu, c)
+	reqMenu, err := ctl.svc.CreateMenu(c.Request.Context(), actorFrom(c), menu)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -96,21 +94,21 @@ func CreateMenu(c *gin.Context) {
 // @Security		BearerAuth
 // @param Authorization header string true "Token"
 // @Param id path string true "Menu ID"
-// @Param menu body types.Menu true "Menu object"
+// @Param menu body types.MenuUpdate true "Menu object"
 // @Success 200 {object} string
-// @Failure 500 {object} string
+// @Failure 500 {object} apperrors.Problem
 // @Router /menu/{id} [put]
-func UpdateMenu(c *gin.Context) {
-	var reqMenu models.Menu
+func (ctl *Controller) UpdateMenu(c *gin.Context) {
+	var reqMenu types.MenuUpdate
 
-	if err := c.ShouldBindJSON(&reqMenu); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if err := bindJSON(c, &reqMenu); err != nil {
+		c.Error(err)
 		return
 	}
 
-	menu, err := services.UpdateMenu(c.Param("id"), reqMenu, c)
+	menu, err := ctl.svc.UpdateMenu(c.Request.Context(), actorFrom(c), c.Param("id"), reqMenu)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -126,14 +124,14 @@ func UpdateMenu(c *gin.Context) {
 // @param Authorization header string true "Token"
 // @Param id path string true "Menu ID"
 // @Success 200 {object} string
-// @Failure 500 {object} string
+// @Failure 500 {object} apperrors.Problem
 // @Router /menu/{id} [delete]
-func DeleteMenu(c *gin.Context) {
+func (ctl *Controller) DeleteMenu(c *gin.Context) {
 	menuId := c.Param("id")
 
-	err := services.DeleteMenu(menuId, c)
+	err := ctl.svc.DeleteMenu(c.Request.Context(), actorFrom(c), menuId)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
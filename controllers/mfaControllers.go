@@ -0,0 +1,85 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/ShahSau/culinary-bliss/types"
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary		Enroll in Two-Factor Authentication
+// @Description	create a new authenticator secret for the current user. It is only used once confirmed with a code.
+// @Tags			MFA
+// @Accept			json
+// @Produce		json
+// @Security		BearerAuth
+// @param Authorization header string true "Token"
+// @Success		200	{object}	string
+// @Failure		400	{object}	apperrors.Problem
+// @Router			/mfa/enroll [post]
+func (ctl *Controller) EnrollMfa(c *gin.Context) {
+	secret, uri, err := ctl.svc.EnrollMfa(c.Request.Context(), actorFrom(c))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"error": false, "message": "Scan the secret with your authenticator app and confirm with a code", "data": gin.H{"secret": secret, "otpauth_uri": uri}, "status": http.StatusOK, "success": true})
+}
+
+// @Summary		Confirm Two-Factor Authentication
+// @Description	turn on two-factor authentication with a code from the authenticator app. The recovery codes are only returned once.
+// @Tags			MFA
+// @Accept			json
+// @Produce		json
+// @Security		BearerAuth
+// @param Authorization header string true "Token"
+// @Param 		 code body types.MfaCode true "Code"
+// @Success		200	{object}	string
+// @Failure		400	{object}	apperrors.Problem
+// @Failure		401	{object}	apperrors.Problem
+// @Router			/mfa/confirm [post]
+func (ctl *Controller) ConfirmMfa(c *gin.Context) {
+	var req types.MfaCode
+	if err := bindJSON(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	recoveryCodes, err := ctl.svc.ConfirmMfa(c.Request.Context(), actorFrom(c), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"error": false, "message": "Two-factor authentication enabled, store the recovery codes somewhere safe", "data": gin.H{"recovery_codes": recoveryCodes}, "status": http.StatusOK, "success": true})
+}
+
+// @Summary		Disable Two-Factor Authentication
+// @Description	turn off two-factor authentication for the current user. Not allowed when the user's role requires it.
+// @Tags			MFA
+// @Accept			json
+// @Produce		json
+// @Security		BearerAuth
+// @param Authorization header string true "Token"
+// @Param 		 code body types.MfaCode true "Code"
+// @Success		200	{object}	string
+// @Failure		400	{object}	apperrors.Problem
+// @Failure		401	{object}	apperrors.Problem
+// @Failure		403	{object}	apperrors.Problem
+// @Router			/mfa/disable [post]
+func (ctl *Controller) DisableMfa(c *gin.Context) {
+	var req types.MfaCode
+	if err := bindJSON(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	err := ctl.svc.DisableMfa(c.Request.Context(), actorFrom(c), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"error": false, "message": "Two-factor authentication disabled", "status": http.StatusOK, "success": true})
+}
@@ -3,33 +3,33 @@ package controllers
 import (
 	"net/http"
 
-	"github.com/ShahSau/culinary-bliss/models"
-	"github.com/ShahSau/culinary-bliss/services"
+	"github.com/ShahSau/culinary-bliss/helpers"
+	"github.com/ShahSau/culinary-bliss/types"
 	"github.com/gin-gonic/gin"
 )
 
 // @Summary Get all orders
-// @Description Get all orders
+// @Description Get all orders. Filter with field=value or field[op]=value on order_status, table_id, total_amount, order_date, created_at
 // @Tags Admin
 // @Accept json
 // @Produce json
 // @Security		BearerAuth
 // @param Authorization header string true "Token"
-// @Param 		 recordPerPage query int false "Record Per Page"
-// @Param 		 page query int false "Page"
-// @Param 		 startIndex query int false "Start Index"
+// @Param limit query int false "Page size, 1 to 100, 20 by default"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param sort query string false "Comma separated fields to sort by, - for descending: total_amount, order_date, created_at"
 // @Success 200 {object} string
-// @Failure 500 {object} string
+// @Failure 400 {object} apperrors.Problem
+// @Failure 500 {object} apperrors.Problem
 // @Router /orders [get]
-func GetOrders(c *gin.Context) {
-
-	response, err := services.GetOrders(c)
+func (ctl *Controller) GetOrders(c *gin.Context) {
+	page, err := ctl.svc.GetOrders(c.Request.Context(), actorFrom(c), listFrom(c))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"data": response.AllOrders, "page": response.Page, "recordPerPage": response.RecordPerPage, "startIndex": response.StartIndex})
+	c.JSON(http.StatusOK, listed("Orders retrieved successfully", page))
 }
 
 // @Summary Get a order
@@ -41,14 +41,14 @@ func GetOrders(c *gin.Context) {
 // @param Authorization header string true "Token"
 // @Param id path string true "Order ID"
 // @Success 200 {object} string
-// @Failure 500 {object} string
+// @Failure 500 {object} apperrors.Problem
 // @Router /order/{id} [get]
-func GetOrder(c *gin.Context) {
+func (ctl *Controller) GetOrder(c *gin.Context) {
 	order_id := c.Param("id")
 
-	order, err := services.GetOrderById(c, order_id)
+	order, err := ctl.svc.GetOrderById(c.Request.Context(), actorFrom(c), order_id)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -56,59 +56,109 @@ func GetOrder(c *gin.Context) {
 
 }
 
+// @Summary Get the details of an order
+// @Description Get an order with its table, its items joined to their food (name, image, unit price, quantity, line total) and the payment due
+// @Tags User
+// @Accept json
+// @Produce json
+// @Security		BearerAuth
+// @param Authorization header string true "Token"
+// @Param id path string true "Order ID"
+// @Success 200 {object} models.OrderDetails
+// @Failure 404 {object} apperrors.Problem
+// @Failure 500 {object} apperrors.Problem
+// @Router /orders/{id}/details [get]
+func (ctl *Controller) GetOrderDetails(c *gin.Context) {
+	details, err := ctl.svc.GetOrderDetails(c.Request.Context(), actorFrom(c), c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"error": false, "message": "Order details retrieved successfully", "data": details, "status": http.StatusOK, "success": true})
+}
+
 // @Summary Create a order
-// @Description Create a order
+// @Description Open an order for a table and mark the table occupied
 // @Tags User
 // @Accept json
 // @Produce json
 // @Security		BearerAuth
 // @param Authorization header string true "Token"
-// @Param table_id body string true "Table ID"
-// @Param order_status body string true "Order Status"
-// @Param total_amount body string true "Total Amount"
+// @Param order body types.Order true "Order"
 // @Success 201 {object} string
-// @Failure 500 {object} string
+// @Failure 500 {object} apperrors.Problem
 // @Router /order [post]
-func CreateOrder(c *gin.Context) {
-	var orderReq models.Order
+func (ctl *Controller) CreateOrder(c *gin.Context) {
+	var orderReq types.Order
 
-	if err := c.ShouldBindJSON(&orderReq); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if err := bindJSON(c, &orderReq); err != nil {
+		c.Error(err)
 		return
 	}
 
-	order, err := services.CreateOrder(c, orderReq)
+	order, err := ctl.svc.CreateOrder(c.Request.Context(), actorFrom(c), orderReq)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
 	c.JSON(http.StatusCreated, gin.H{"error": false, "message": "Order created successfully", "data": order, "status": http.StatusCreated, "success": true})
 }
 
+// @Summary Place an order with its items
+// @Description Open an order for a table together with its items in one call. Every food has to be on a menu being served, items are priced on the server and the table is marked occupied.
+// @Tags User
+// @Accept json
+// @Produce json
+// @Security		BearerAuth
+// @param Authorization header string true "Token"
+// @Param order body types.OrderItemPack true "Table ID and items"
+// @Success 201 {object} types.PlacedOrder
+// @Failure 400 {object} apperrors.Problem
+// @Failure 404 {object} apperrors.Problem
+// @Failure 500 {object} apperrors.Problem
+// @Router /orders/with-items [post]
+func (ctl *Controller) PlaceOrder(c *gin.Context) {
+	var pack types.OrderItemPack
+
+	if err := bindJSON(c, &pack); err != nil {
+		c.Error(err)
+		return
+	}
+
+	placed, err := ctl.svc.PlaceOrder(c.Request.Context(), actorFrom(c), pack)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"error": false, "message": "Order placed successfully", "data": placed, "status": http.StatusCreated, "success": true})
+}
+
 // @Summary Update a order
-// @Description Update a order
+// @Description Move an order to another table. An open order occupies the new table and frees the old one once no other order is open on it.
 // @Tags Admin
 // @Accept json
 // @Produce json
 // @Security		BearerAuth
 // @param Authorization header string true "Token"
 // @Param id path string true "Order ID"
-// @Param order body models.Order true "Table ID"
+// @Param order body types.OrderUpdate true "Table ID"
 // @Success 200 {object} string
-// @Failure 500 {object} string
+// @Failure 500 {object} apperrors.Problem
 // @Router /order/{id} [put]
-func UpdateOrder(c *gin.Context) {
-	var reqOrder models.Order
+func (ctl *Controller) UpdateOrder(c *gin.Context) {
+	var reqOrder types.OrderUpdate
 
 	orderId := c.Param("id")
-	if err := c.ShouldBindJSON(&reqOrder); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if err := bindJSON(c, &reqOrder); err != nil {
+		c.Error(err)
 		return
 	}
-	order, err := services.UpdateOrder(c, reqOrder, orderId)
+	order, err := ctl.svc.UpdateOrder(c.Request.Context(), actorFrom(c), orderId, reqOrder)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"error": false, "message": "Order updated successfully", "status": http.StatusOK, "success": true, "data": order})
@@ -116,7 +166,7 @@ func UpdateOrder(c *gin.Context) {
 }
 
 // @Summary Delete a order
-// @Description Delete a order
+// @Description Delete an order together with its items. Deleting an open order frees its table once no other order is open on it.
 // @Tags Admin
 // @Accept json
 // @Produce json
@@ -124,16 +174,153 @@ func UpdateOrder(c *gin.Context) {
 // @param Authorization header string true "Token"
 // @Param id path string true "Order ID"
 // @Success 200 {object} string
-// @Failure 500 {object} string
+// @Failure 500 {object} apperrors.Problem
 // @Router /order/{id} [delete]
-func DeleteOrder(c *gin.Context) {
+func (ctl *Controller) DeleteOrder(c *gin.Context) {
 	orderId := c.Param("id")
 
-	_, err := services.DeleteOrder(c, orderId)
+	_, err := ctl.svc.DeleteOrder(c.Request.Context(), actorFrom(c), orderId)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"error": false, "message": "Order deleted successfully", "status": http.StatusOK, "success": true, "data": nil})
 }
+
+// @Summary Accept an order
+// @Description Moves a PLACED order to ACCEPTED. Open to admins, managers and waiters.
+// @Tags User
+// @Accept json
+// @Produce json
+// @Security		BearerAuth
+// @param Authorization header string true "Token"
+// @Param id path string true "Order ID"
+// @Success 200 {object} string
+// @Failure 403 {object} apperrors.Problem
+// @Failure 404 {object} apperrors.Problem
+// @Failure 409 {object} apperrors.Problem
+// @Failure 500 {object} apperrors.Problem
+// @Router /orders/{id}/accept [post]
+func (ctl *Controller) AcceptOrder(c *gin.Context) {
+	ctl.transitionOrder(c, helpers.OrderActionAccept)
+}
+
+// @Summary Start preparing an order
+// @Description Moves an ACCEPTED order to PREPARING. Open to admins, managers and the kitchen.
+// @Tags User
+// @Accept json
+// @Produce json
+// @Security		BearerAuth
+// @param Authorization header string true "Token"
+// @Param id path string true "Order ID"
+// @Success 200 {object} string
+// @Failure 403 {object} apperrors.Problem
+// @Failure 404 {object} apperrors.Problem
+// @Failure 409 {object} apperrors.Problem
+// @Failure 500 {object} apperrors.Problem
+// @Router /orders/{id}/prepare [post]
+func (ctl *Controller) PrepareOrder(c *gin.Context) {
+	ctl.transitionOrder(c, helpers.OrderActionPrepare)
+}
+
+// @Summary Mark an order ready
+// @Description Moves a PREPARING order to READY. Open to admins, managers and the kitchen.
+// @Tags User
+// @Accept json
+// @Produce json
+// @Security		BearerAuth
+// @param Authorization header string true "Token"
+// @Param id path string true "Order ID"
+// @Success 200 {object} string
+// @Failure 403 {object} apperrors.Problem
+// @Failure 404 {object} apperrors.Problem
+// @Failure 409 {object} apperrors.Problem
+// @Failure 500 {object} apperrors.Problem
+// @Router /orders/{id}/ready [post]
+func (ctl *Controller) ReadyOrder(c *gin.Context) {
+	ctl.transitionOrder(c, helpers.OrderActionReady)
+}
+
+// @Summary Serve an order
+// @Description Moves a READY order to SERVED. Open to admins, managers and waiters.
+// @Tags User
+// @Accept json
+// @Produce json
+// @Security		BearerAuth
+// @param Authorization header string true "Token"
+// @Param id path string true "Order ID"
+// @Success 200 {object} string
+// @Failure 403 {object} apperrors.Problem
+// @Failure 404 {object} apperrors.Problem
+// @Failure 409 {object} apperrors.Problem
+// @Failure 500 {object} apperrors.Problem
+// @Router /orders/{id}/serve [post]
+func (ctl *Controller) ServeOrder(c *gin.Context) {
+	ctl.transitionOrder(c, helpers.OrderActionServe)
+}
+
+// @Summary Pay an order
+// @Description Moves a SERVED order to PAID and frees its table once no other order is open on it. Open to admins, managers and waiters.
+// @Tags User
+// @Accept json
+// @Produce json
+// @Security		BearerAuth
+// @param Authorization header string true "Token"
+// @Param id path string true "Order ID"
+// @Success 200 {object} string
+// @Failure 403 {object} apperrors.Problem
+// @Failure 404 {object} apperrors.Problem
+// @Failure 409 {object} apperrors.Problem
+// @Failure 500 {object} apperrors.Problem
+// @Router /orders/{id}/pay [post]
+func (ctl *Controller) PayOrder(c *gin.Context) {
+	ctl.transitionOrder(c, helpers.OrderActionPay)
+}
+
+// @Summary Cancel an order
+// @Description Cancels an order that has not been served yet and frees its table once no other order is open on it. Waiters can only cancel it until the kitchen starts, admins and managers until it is ready.
+// @Tags User
+// @Accept json
+// @Produce json
+// @Security		BearerAuth
+// @param Authorization header string true "Token"
+// @Param id path string true "Order ID"
+// @Success 200 {object} string
+// @Failure 403 {object} apperrors.Problem
+// @Failure 404 {object} apperrors.Problem
+// @Failure 409 {object} apperrors.Problem
+// @Failure 500 {object} apperrors.Problem
+// @Router /orders/{id}/cancel [post]
+func (ctl *Controller) CancelOrder(c *gin.Context) {
+	ctl.transitionOrder(c, helpers.OrderActionCancel)
+}
+
+// @Summary Refund an order
+// @Description Moves a PAID order to REFUNDED. Open to admins and managers.
+// @Tags User
+// @Accept json
+// @Produce json
+// @Security		BearerAuth
+// @param Authorization header string true "Token"
+// @Param id path string true "Order ID"
+// @Success 200 {object} string
+// @Failure 403 {object} apperrors.Problem
+// @Failure 404 {object} apperrors.Problem
+// @Failure 409 {object} apperrors.Problem
+// @Failure 500 {object} apperrors.Problem
+// @Router /orders/{id}/refund [post]
+func (ctl *Controller) RefundOrder(c *gin.Context) {
+	ctl.transitionOrder(c, helpers.OrderActionRefund)
+}
+
+// transitionOrder moves the order named in the path with the action
+func (ctl *Controller) transitionOrder(c *gin.Context, action string) {
+	order, err := ctl.svc.TransitionOrder(c.Request.Context(), actorFrom(c), c.Param("id"), action)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"error": false, "message": "Order is " + order.Order_status, "data": order, "status": http.StatusOK, "success": true})
+}
@@ -4,36 +4,28 @@ import (
 	"log"
 	"net/http"
 
-	"github.com/ShahSau/culinary-bliss/database"
-	"github.com/ShahSau/culinary-bliss/models"
-	"github.com/ShahSau/culinary-bliss/services"
 	"github.com/ShahSau/culinary-bliss/types"
 	"github.com/gin-gonic/gin"
-	"go.mongodb.org/mongo-driver/bson"
-	"go.mongodb.org/mongo-driver/mongo"
 )
 
-var restaurantCollection *mongo.Collection = database.GetCollection(database.DB, "restaurants")
-
 // @Summary GetRestaurants
-// @Description Get all restaurants
+// @Description Get all restaurants. Filter with field=value or field[op]=value on title, pickup, delivery, rating, ratingCount, created_at
 // @Tags Global
 // @Produce json
-// @Param 		 recordPerPage query int false "Record Per Page"
-// @Param 		 page query int false "Page"
-// @Param 		 startIndex query int false "Start Index"
+// @Param limit query int false "Page size, 1 to 100, 20 by default"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param sort query string false "Comma separated fields to sort by, - for descending: title, rating, ratingCount, created_at"
 // @Success 200 {object} string
-// @Failure 400 {object} string
+// @Failure 400 {object} apperrors.Problem
 // @Router /restaurants [get]
-func GetRestaurants(c *gin.Context) {
-	responseRestaurant, err := services.GetRestaurants(c)
+func (ctl *Controller) GetRestaurants(c *gin.Context) {
+	page, err := ctl.svc.GetRestaurants(c.Request.Context(), actorFrom(c), listFrom(c))
 	if err != nil {
-		log.Println("Error getting restaurants:", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"data": responseRestaurant.AllRestaurants, "page": responseRestaurant.Page, "recordPerPage": responseRestaurant.RecordPerPage, "startIndex": responseRestaurant.StartIndex, "error": false, "message": "Restaurants retrieved successfully", "status": http.StatusOK, "success": true})
+	c.JSON(http.StatusOK, listed("Restaurants retrieved successfully", page))
 }
 
 // @Summary GetRestaurant
@@ -42,15 +34,15 @@ func GetRestaurants(c *gin.Context) {
 // @Produce json
 // @Param id path string true "Restaurant ID"
 // @Success 200 {object} string
-// @Failure 400 {object} string
+// @Failure 400 {object} apperrors.Problem
 // @Router /restaurants/{id} [get]
-func GetRestaurant(c *gin.Context) {
+func (ctl *Controller) GetRestaurant(c *gin.Context) {
 	restaurant_id := c.Param("id")
 
-	restaurant, err := services.GetRestaurantByID(c, restaurant_id)
+	restaurant, err := ctl.svc.GetRestaurantByID(c.Request.Context(), actorFrom(c), restaurant_id)
 	if err != nil {
 		log.Println("Error getting restaurant:", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -66,20 +58,20 @@ func GetRestaurant(c *gin.Context) {
 // @param Authorization header string true "Token"
 // @Param restaurant body types.Restaurant true "Restaurant Object"
 // @Success 200 {object} string
-// @Failure 400 {object} string
+// @Failure 400 {object} apperrors.Problem
 // @Router /restaurants [post]
-func CreateRestaurant(c *gin.Context) {
-	var restaurantReq models.Restaurant
+func (ctl *Controller) CreateRestaurant(c *gin.Context) {
+	var restaurantReq types.Restaurant
 
-	if err := c.ShouldBindJSON(&restaurantReq); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if err := bindJSON(c, &restaurantReq); err != nil {
+		c.Error(err)
 		return
 	}
 
-	restaurant, err := services.CreateRestaurant(c, restaurantReq)
+	restaurant, err := ctl.svc.CreateRestaurant(c.Request.Context(), actorFrom(c), restaurantReq)
 	if err != nil {
 		log.Println("Error creating restaurant:", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -94,27 +86,22 @@ func CreateRestaurant(c *gin.Context) {
 // @Security		BearerAuth
 // @param Authorization header string true "Token"
 // @Param id path string true "Restaurant ID"
-// @Param restaurant body types.Restaurant true "Restaurant Object"
+// @Param restaurant body types.RestaurantUpdate true "Restaurant Object"
 // @Success 200 {object} string
-// @Failure 400 {object} string
+// @Failure 400 {object} apperrors.Problem
 // @Router /restaurants/{id} [put]
-func UpdateRestaurant(c *gin.Context) {
+func (ctl *Controller) UpdateRestaurant(c *gin.Context) {
 	restaurant_id := c.Param("id")
 
-	if err := c.ShouldBindJSON(&restaurant_id); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
-
-	var restaurantReq models.Restaurant
-	if err := c.ShouldBindJSON(&restaurantReq); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	var restaurantReq types.RestaurantUpdate
+	if err := bindJSON(c, &restaurantReq); err != nil {
+		c.Error(err)
 		return
 	}
-	restaurant, err := services.UpdateRestaurant(c, restaurant_id, restaurantReq)
+	restaurant, err := ctl.svc.UpdateRestaurant(c.Request.Context(), actorFrom(c), restaurant_id, restaurantReq)
 	if err != nil {
 		log.Println("Error updating restaurant:", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -129,15 +116,15 @@ func UpdateRestaurant(c *gin.Context) {
 // @param Authorization header string true "Token"
 // @Param id path string true "Restaurant ID"
 // @Success 200 {object} string
-// @Failure 400 {object} string
+// @Failure 400 {object} apperrors.Problem
 // @Router /restaurants/{id} [delete]
-func DeleteRestaurant(c *gin.Context) {
+func (ctl *Controller) DeleteRestaurant(c *gin.Context) {
 	restaurant_id := c.Param("id")
 
-	_, err := restaurantCollection.DeleteOne(c.Request.Context(), bson.M{"restaurant_id": restaurant_id})
+	_, err := ctl.svc.DeleteRestaurant(c.Request.Context(), actorFrom(c), restaurant_id)
 	if err != nil {
 		log.Println("Error deleting restaurant:", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -152,22 +139,17 @@ func DeleteRestaurant(c *gin.Context) {
 // @param Authorization header string true "Token"
 // @Param id path string true "Restaurant ID"
 // @Success 200 {object} string
-// @Failure 400 {object} string
+// @Failure 400 {object} apperrors.Problem
 // @Router /restaurants/menus/{id} [get]
-func MenuByRestaurant(c *gin.Context) {
+func (ctl *Controller) MenuByRestaurant(c *gin.Context) {
 	restaurant_id := c.Param("id")
 
-	var restaurant models.Restaurant
-
-	defer c.Request.Body.Close()
-
-	err := restaurantCollection.FindOne(c.Request.Context(), bson.M{"restaurant_id": restaurant_id}).Decode(&restaurant)
-
+	menus, err := ctl.svc.MenusByRestaurant(c.Request.Context(), actorFrom(c), restaurant_id)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"error": false, "message": "Restaurant retrived successfully", "data": restaurant.Menu, "status": http.StatusOK, "success": true})
+	c.JSON(http.StatusOK, gin.H{"error": false, "message": "Restaurant retrived successfully", "data": menus, "status": http.StatusOK, "success": true})
 }
 
 // @Summary AddRatingtoRestaurant
@@ -180,36 +162,21 @@ func MenuByRestaurant(c *gin.Context) {
 // @Param id path string true "Restaurant ID"
 // @Param rating body types.Rating true "Rating Object"
 // @Success 200 {object} string
-// @Failure 400 {object} string
+// @Failure 400 {object} apperrors.Problem
 // @Router /restaurants/rating/{id} [put]
-func AddRatingtoRestaurant(c *gin.Context) {
+func (ctl *Controller) AddRatingtoRestaurant(c *gin.Context) {
 	restaurant_id := c.Param("id")
 
-	var restaurant models.Restaurant
-
-	defer c.Request.Body.Close()
-
-	err := restaurantCollection.FindOne(c.Request.Context(), bson.M{"restaurant_id": restaurant_id}).Decode(&restaurant)
-
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
-	}
-
 	var rating types.Rating
 
-	if err := c.ShouldBindJSON(&rating); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if err := bindJSON(c, &rating); err != nil {
+		c.Error(err)
 		return
 	}
 
-	restaurant.Rating = (float64(restaurant.Rating)*float64(restaurant.RatingCount) + float64(rating.Rating)) / float64(restaurant.RatingCount+1)
-	restaurant.RatingCount = restaurant.RatingCount + 1
-
-	_, err = restaurantCollection.UpdateOne(c.Request.Context(), bson.M{"restaurant_id": restaurant_id}, bson.M{"$set": restaurant})
-
+	restaurant, err := ctl.svc.AddRating(c.Request.Context(), actorFrom(c), restaurant_id, rating)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
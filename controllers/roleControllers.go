@@ -0,0 +1,105 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/ShahSau/culinary-bliss/types"
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary		Get all Roles
+// @Description	Get every role with the permissions it grants
+// @Tags			Admin
+// @Accept			json
+// @Produce		json
+// @Security		BearerAuth
+// @param Authorization header string true "Token"
+// @Success		200	{object}	string
+// @Failure		500	{object}	apperrors.Problem
+// @Router			/roles [get]
+func (ctl *Controller) GetRoles(c *gin.Context) {
+	roles, err := ctl.svc.GetRoles(c.Request.Context(), actorFrom(c))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"error": false, "message": "Roles retrieved successfully", "data": roles, "status": http.StatusOK, "success": true})
+}
+
+// @Summary		Get a Role
+// @Description	Get a role and the permissions it grants
+// @Tags			Admin
+// @Accept			json
+// @Produce		json
+// @Security		BearerAuth
+// @param Authorization header string true "Token"
+// @Param 		 name path string true "Role name"
+// @Success		200	{object}	string
+// @Failure		404	{object}	apperrors.Problem
+// @Router			/roles/{name} [get]
+func (ctl *Controller) GetRole(c *gin.Context) {
+	role, err := ctl.svc.GetRole(c.Request.Context(), actorFrom(c), c.Param("name"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"error": false, "message": "Role retrieved successfully", "data": role, "status": http.StatusOK, "success": true})
+}
+
+// @Summary		Update Role permissions
+// @Description	Replace the permissions granted to a role and optionally whether it has to use two-factor authentication. Only admins can, and the users with the role are logged out so their tokens pick up the change.
+// @Tags			Admin
+// @Accept			json
+// @Produce		json
+// @Security		BearerAuth
+// @param Authorization header string true "Token"
+// @Param 		 name path string true "Role name"
+// @Param 		 permissions body types.RolePermissions true "Permissions"
+// @Success		200	{object}	string
+// @Failure		400	{object}	apperrors.Problem
+// @Router			/roles/{name} [put]
+func (ctl *Controller) UpdateRole(c *gin.Context) {
+	var req types.RolePermissions
+	if err := bindJSON(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	role, err := ctl.svc.UpdateRolePermissions(c.Request.Context(), actorFrom(c), c.Param("name"), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"error": false, "message": "Role updated successfully", "data": role, "status": http.StatusOK, "success": true})
+}
+
+// @Summary		Assign Role
+// @Description	Assign a role to a user, only admins can. The user is logged out when the role changes.
+// @Tags			Admin
+// @Accept			json
+// @Produce		json
+// @Security		BearerAuth
+// @param Authorization header string true "Token"
+// @Param 		 id path string true "User ID"
+// @Param 		 role body types.UserRole true "Role"
+// @Success		200	{object}	string
+// @Failure		400	{object}	apperrors.Problem
+// @Router			/users/{id}/role [put]
+func (ctl *Controller) UpdateUserRole(c *gin.Context) {
+	var req types.UserRole
+	if err := bindJSON(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	user, err := ctl.svc.UpdateUserRole(c.Request.Context(), actorFrom(c), c.Param("id"), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"error": false, "message": "Role assigned successfully", "data": types.NewUser(user), "status": http.StatusOK, "success": true})
+}
@@ -4,27 +4,30 @@ import (
 	"fmt"
 	"net/http"
 
-	"github.com/ShahSau/culinary-bliss/models"
-	"github.com/ShahSau/culinary-bliss/services"
+	"github.com/ShahSau/culinary-bliss/types"
 	"github.com/gin-gonic/gin"
 )
 
 // @Summary Get all tables
-// @Description Get all tables
+// @Description Get all tables. Filter with field=value or field[op]=value on table_number, table_status, number_of_guests, created_at
 // @Tags Global
 // @Accept json
 // @Produce json
+// @Param limit query int false "Page size, 1 to 100, 20 by default"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param sort query string false "Comma separated fields to sort by, - for descending: table_number, number_of_guests, created_at"
 // @Success 200 {object} string
-// @Failure 500 {object} string
+// @Failure 400 {object} apperrors.Problem
+// @Failure 500 {object} apperrors.Problem
 // @Router /table [get]
-func GetTables(c *gin.Context) {
-	results, err := services.GetTables(c)
+func (ctl *Controller) GetTables(c *gin.Context) {
+	page, err := ctl.svc.GetTables(c.Request.Context(), actorFrom(c), listFrom(c))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"error": false, "message": "Table retrived successfully", "data": results, "status": http.StatusOK, "success": true})
+	c.JSON(http.StatusOK, listed("Tables retrieved successfully", page))
 }
 
 // @Summary Get a table
@@ -34,14 +37,14 @@ func GetTables(c *gin.Context) {
 // @Produce json
 // @Param id path string true "Table ID"
 // @Success 200 {object} string
-// @Failure 500 {object} string
+// @Failure 500 {object} apperrors.Problem
 // @Router /table/{id} [get]
-func GetTable(c *gin.Context) {
+func (ctl *Controller) GetTable(c *gin.Context) {
 	table_id := c.Param("id")
 
-	table, err := services.GetTable(c, table_id)
+	table, err := ctl.svc.GetTable(c.Request.Context(), actorFrom(c), table_id)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -57,19 +60,19 @@ func GetTable(c *gin.Context) {
 // @param Authorization header string true "Token"
 // @Param table body types.Table true "Table"
 // @Success 201 {object} string
-// @Failure 400 {object} string
+// @Failure 400 {object} apperrors.Problem
 // @Router /table [post]
-func CreateTable(c *gin.Context) {
-	var tableReq models.Table
+func (ctl *Controller) CreateTable(c *gin.Context) {
+	var tableReq types.Table
 
-	if err := c.ShouldBindJSON(&tableReq); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if err := bindJSON(c, &tableReq); err != nil {
+		c.Error(err)
 		return
 	}
 
-	newTable, err := services.CreateTable(c, tableReq)
+	newTable, err := ctl.svc.CreateTable(c.Request.Context(), actorFrom(c), tableReq)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -85,21 +88,21 @@ func CreateTable(c *gin.Context) {
 // @Security		BearerAuth
 // @param Authorization header string true "Token"
 // @Param id path string true "Table ID"
-// @Param table body types.Table true "Table"
+// @Param table body types.TableUpdate true "Table"
 // @Success 200 {object} string
-// @Failure 400 {object} string
+// @Failure 400 {object} apperrors.Problem
 // @Router /table/{id} [put]
-func UpdateTable(c *gin.Context) {
-	var tableReq models.Table
+func (ctl *Controller) UpdateTable(c *gin.Context) {
+	var tableReq types.TableUpdate
 	id := c.Param("id")
 
-	if err := c.ShouldBindJSON(&tableReq); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if err := bindJSON(c, &tableReq); err != nil {
+		c.Error(err)
 		return
 	}
-	updatedTable, err := services.UpdateTable(c, id, tableReq)
+	updatedTable, err := ctl.svc.UpdateTable(c.Request.Context(), actorFrom(c), id, tableReq)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -116,14 +119,14 @@ func UpdateTable(c *gin.Context) {
 // @param Authorization header string true "Token"
 // @Param id path string true "Table ID"
 // @Success 200 {object} string
-// @Failure 500 {object} string
+// @Failure 500 {object} apperrors.Problem
 // @Router /table/{id} [delete]
-func DeleteTable(c *gin.Context) {
+func (ctl *Controller) DeleteTable(c *gin.Context) {
 	id := c.Param("id")
 
-	_, err := services.DeleteTable(c, id)
+	_, err := ctl.svc.DeleteTable(c.Request.Context(), actorFrom(c), id)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
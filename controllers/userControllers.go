@@ -3,33 +3,32 @@ package controllers
 import (
 	"net/http"
 
-	"github.com/ShahSau/culinary-bliss/models"
-	"github.com/ShahSau/culinary-bliss/services"
 	"github.com/ShahSau/culinary-bliss/types"
 	"github.com/gin-gonic/gin"
 )
 
 // @Summary		Get all Users
-// @Description	Get all users
+// @Description	Get all users. Filter with field=value or field[op]=value on email, role, status, first_name, last_name, created_at
 // @Tags			Admin
 // @Accept			json
 // @Produce		json
 // @Security		BearerAuth
 // @param Authorization header string true "Token"
-// @Param 		 recordPerPage query int false "Record Per Page"
-// @Param 		 page query int false "Page"
-// @Param 		 startIndex query int false "Start Index"
+// @Param limit query int false "Page size, 1 to 100, 20 by default"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param sort query string false "Comma separated fields to sort by, - for descending: first_name, last_name, created_at"
 // @Success		200	{object}	string
-// @Failure		500	{object}	string
+// @Failure		400	{object}	apperrors.Problem
+// @Failure		500	{object}	apperrors.Problem
 // @Router			/users [get]
-func GetUsers(c *gin.Context) {
-	response, err := services.GetUsers(c)
+func (ctl *Controller) GetUsers(c *gin.Context) {
+	page, err := ctl.svc.GetUsers(c.Request.Context(), actorFrom(c), listFrom(c))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"data": response.AllUsers, "page": response.Page, "recordPerPage": response.RecordPerPage, "startIndex": response.StartIndex, "status": http.StatusOK, "success": true, "error": false, "message": "Users retrieved successfully"})
 
+	c.JSON(http.StatusOK, listed("Users retrieved successfully", page))
 }
 
 // @Summary		Get a  User
@@ -40,19 +39,20 @@ func GetUsers(c *gin.Context) {
 // @Security		BearerAuth
 // @param Authorization header string true "Token"
 // @Param 		 id path string true "User ID"
-// @Success		200	{object}	string
-// @Failure		500	{object}	string
+// @Success		200	{object}	types.User
+// @Failure		403	{object}	apperrors.Problem
+// @Failure		500	{object}	apperrors.Problem
 // @Router			/users/{id} [get]
-func GetUser(c *gin.Context) {
+func (ctl *Controller) GetUser(c *gin.Context) {
 	userId := c.Param("id")
 
-	user, err := services.GetUser(c, userId)
+	user, err := ctl.svc.GetUser(c.Request.Context(), actorFrom(c), userId)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"data": user, "status": http.StatusOK, "success": true, "error": false, "message": "User retrieved successfully"})
+	c.JSON(http.StatusOK, gin.H{"data": types.NewUser(user), "status": http.StatusOK, "success": true, "error": false, "message": "User retrieved successfully"})
 
 }
 
@@ -63,25 +63,58 @@ func GetUser(c *gin.Context) {
 // @Produce		json
 // @Security		BearerAuth
 // @param Authorization header string true "Token"
-// @Param 		 user body types.RegisterUser true "User"
-// @Success		200	{object}	string
-// @Failure		500	{object}	string
+// @Param 		 id path string true "User ID"
+// @Param 		 user body types.UpdateUser true "User"
+// @Success		200	{object}	types.User
+// @Failure		403	{object}	apperrors.Problem
+// @Failure		500	{object}	apperrors.Problem
 // @Router			/users/{id} [put]
-func UpdateUser(c *gin.Context) {
+func (ctl *Controller) UpdateUser(c *gin.Context) {
 	userId := c.Param("id")
-	var userReq models.User
-	if err := c.ShouldBindJSON(&userReq); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	var userReq types.UpdateUser
+	if err := bindJSON(c, &userReq); err != nil {
+		c.Error(err)
+		return
+	}
+
+	updatedUser, err := ctl.svc.UpdateUser(c.Request.Context(), actorFrom(c), userId, userReq)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"error": false, "message": "User updated successfully", "status": http.StatusOK, "success": true, "data": types.NewUser(updatedUser)})
+}
+
+// @Summary		Change Credentials
+// @Description	Change the email or password of your own account, confirmed with the current password. A new password logs out every other session, a new email is only used once the link sent to it is followed.
+// @Tags			User
+// @Accept			json
+// @Produce		json
+// @Security		BearerAuth
+// @param Authorization header string true "Token"
+// @Param 		 id path string true "User ID"
+// @Param 		 credentials body types.ChangeCredentials true "Credentials"
+// @Success		200	{object}	types.User
+// @Failure		401	{object}	apperrors.Problem
+// @Failure		403	{object}	apperrors.Problem
+// @Failure		409	{object}	apperrors.Problem
+// @Failure		500	{object}	apperrors.Problem
+// @Router			/users/{id}/credentials [put]
+func (ctl *Controller) ChangeCredentials(c *gin.Context) {
+	var req types.ChangeCredentials
+	if err := bindJSON(c, &req); err != nil {
+		c.Error(err)
 		return
 	}
 
-	updatedUser, err := services.UpdateUser(c, userId, userReq)
+	user, err := ctl.svc.ChangeCredentials(c.Request.Context(), actorFrom(c), c.Param("id"), req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"error": false, "message": "User updated successfully", "status": http.StatusOK, "success": true, "data": updatedUser})
+	c.JSON(http.StatusOK, gin.H{"error": false, "message": "Credentials changed successfully", "status": http.StatusOK, "success": true, "data": types.NewUser(user)})
 }
 
 // @Summary		Delete User
@@ -93,14 +126,14 @@ func UpdateUser(c *gin.Context) {
 // @param Authorization header string true "Token"
 // @Param 		 id path string true "User ID"
 // @Success		200	{object}	string
-// @Failure		500	{object}	string
+// @Failure		500	{object}	apperrors.Problem
 // @Router			/users/{id} [delete]
-func DeleteUser(c *gin.Context) {
+func (ctl *Controller) DeleteUser(c *gin.Context) {
 	id := c.Param("id")
 
-	_, err := services.DeleteUser(c, id)
+	_, err := ctl.svc.DeleteUser(c.Request.Context(), actorFrom(c), id)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -116,19 +149,146 @@ func DeleteUser(c *gin.Context) {
 // @param Authorization header string true "Token"
 // @Param 		 user body types.PasswordReset true "User"
 // @Success		200	{object}	string
-// @Failure		500	{object}	string
+// @Failure		500	{object}	apperrors.Problem
 // @Router			/reset-password [post]
-func ResetPassword(c *gin.Context) {
+func (ctl *Controller) ResetPassword(c *gin.Context) {
 	var userReq types.PasswordReset
-	if err := c.ShouldBindJSON(&userReq); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if err := bindJSON(c, &userReq); err != nil {
+		c.Error(err)
+		return
+	}
+
+	foundUser, err := ctl.svc.ResetPassword(c.Request.Context(), actorFrom(c), userReq)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"error": false, "message": "Password reset successfully", "status": http.StatusOK, "success": true, "data": types.NewUser(foundUser)})
+}
+
+// @Summary		Get Sessions
+// @Description	List the devices a user is logged in on
+// @Tags			User
+// @Accept			json
+// @Produce		json
+// @Security		BearerAuth
+// @param Authorization header string true "Token"
+// @Param 		 id path string true "User ID"
+// @Success		200	{object}	string
+// @Failure		500	{object}	apperrors.Problem
+// @Router			/users/{id}/sessions [get]
+func (ctl *Controller) GetSessions(c *gin.Context) {
+	sessions, err := ctl.svc.GetSessions(c.Request.Context(), actorFrom(c), c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"error": false, "message": "Sessions retrieved successfully", "data": sessions, "status": http.StatusOK, "success": true})
+}
+
+// @Summary		Revoke Session
+// @Description	Log a user out of a single device
+// @Tags			User
+// @Accept			json
+// @Produce		json
+// @Security		BearerAuth
+// @param Authorization header string true "Token"
+// @Param 		 id path string true "User ID"
+// @Param 		 session_id path string true "Session ID"
+// @Success		200	{object}	string
+// @Failure		404	{object}	apperrors.Problem
+// @Router			/users/{id}/sessions/{session_id} [delete]
+func (ctl *Controller) RevokeSession(c *gin.Context) {
+	err := ctl.svc.RevokeSession(c.Request.Context(), actorFrom(c), c.Param("id"), c.Param("session_id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"error": false, "message": "Session revoked successfully", "status": http.StatusOK, "success": true, "data": nil})
+}
+
+// @Summary		Logout all Sessions
+// @Description	Log a user out of every device
+// @Tags			User
+// @Accept			json
+// @Produce		json
+// @Security		BearerAuth
+// @param Authorization header string true "Token"
+// @Param 		 id path string true "User ID"
+// @Success		200	{object}	string
+// @Failure		500	{object}	apperrors.Problem
+// @Router			/users/{id}/logout-all [post]
+func (ctl *Controller) LogoutAllSessions(c *gin.Context) {
+	count, err := ctl.svc.RevokeUserTokenFamilies(c.Request.Context(), actorFrom(c), c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"error": false, "message": "All sessions logged out successfully", "status": http.StatusOK, "success": true, "data": gin.H{"revoked_sessions": count}})
+}
+
+// @Summary		Resend Verification Email
+// @Description	Send a new verification link to a user that has not verified their email yet
+// @Tags			Admin
+// @Accept			json
+// @Produce		json
+// @Security		BearerAuth
+// @param Authorization header string true "Token"
+// @Param 		 id path string true "User ID"
+// @Success		200	{object}	string
+// @Failure		400	{object}	apperrors.Problem
+// @Router			/users/{id}/verification/resend [post]
+func (ctl *Controller) ResendVerification(c *gin.Context) {
+	err := ctl.svc.ResendVerificationEmail(c.Request.Context(), actorFrom(c), c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"error": false, "message": "Verification email sent successfully", "status": http.StatusOK, "success": true, "data": nil})
+}
+
+// @Summary		Verify User
+// @Description	Activate a user without the emailed verification link
+// @Tags			Admin
+// @Accept			json
+// @Produce		json
+// @Security		BearerAuth
+// @param Authorization header string true "Token"
+// @Param 		 id path string true "User ID"
+// @Success		200	{object}	string
+// @Failure		404	{object}	apperrors.Problem
+// @Router			/users/{id}/verify [post]
+func (ctl *Controller) VerifyUser(c *gin.Context) {
+	user, err := ctl.svc.VerifyUser(c.Request.Context(), actorFrom(c), c.Param("id"))
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
-	foundUser, err := services.ResetPassword(c, userReq)
+	c.JSON(http.StatusOK, gin.H{"error": false, "message": "User verified successfully", "status": http.StatusOK, "success": true, "data": types.NewUser(user)})
+}
+
+// @Summary		Unlock User
+// @Description	Lift the lockout a user's account got after too many failed logins
+// @Tags			Admin
+// @Accept			json
+// @Produce		json
+// @Security		BearerAuth
+// @param Authorization header string true "Token"
+// @Param 		 id path string true "User ID"
+// @Success		200	{object}	string
+// @Failure		404	{object}	apperrors.Problem
+// @Router			/users/{id}/unlock [post]
+func (ctl *Controller) UnlockUser(c *gin.Context) {
+	err := ctl.svc.UnlockUser(c.Request.Context(), actorFrom(c), c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"error": false, "message": "Password reset successfully", "status": http.StatusOK, "success": true, "data": foundUser})
+
+	c.JSON(http.StatusOK, gin.H{"error": false, "message": "User unlocked successfully", "status": http.StatusOK, "success": true, "data": nil})
 }
@@ -3,52 +3,28 @@ package database
 import (
 	"context"
 	"fmt"
-	"log"
-	"os"
-	"time"
 
-	"github.com/joho/godotenv"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
-func EnvMongoURI() string {
-	err := godotenv.Load()
+// ConnectDB connects to the MongoDB at uri and checks that it answers
+func ConnectDB(ctx context.Context, uri string) (*mongo.Client, error) {
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri))
 	if err != nil {
-		log.Fatal("Error loading .env file")
-	}
-
-	return os.Getenv("DB_HOST")
-}
-
-func ConnectDB() *mongo.Client {
-	client, err := mongo.NewClient(options.Client().ApplyURI(EnvMongoURI()))
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-
-	defer cancel()
-
-	err = client.Connect(ctx)
-	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
 
 	//ping the database
 	err = client.Ping(ctx, nil)
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
 	fmt.Println("Connected to MongoDB")
-	return client
+	return client, nil
 }
 
-var DB *mongo.Client = ConnectDB()
-
-// getting database collections
-func GetCollection(client *mongo.Client, collectionName string) *mongo.Collection {
-	collection := client.Database("CulinaryBiliss").Collection(collectionName)
-	return collection
+// Database returns the database every collection lives in
+func Database(client *mongo.Client, name string) *mongo.Database {
+	return client.Database(name)
 }
@@ -0,0 +1,26 @@
+package helpers
+
+// ApiKeyPrefix starts every API key so they are easy to recognise, for example by secret scanners
+const ApiKeyPrefix = "cbk_"
+
+// ApiKeyPermissions are the permissions the routes open to API keys ask for, a key is never granted any other
+var ApiKeyPermissions = []string{
+	PermManageRestaurants,
+	PermReadOrders,
+	PermWriteOrders,
+	PermReadOrderItems,
+	PermWriteOrderItems,
+}
+
+func IsApiKeyPermission(permission string) bool {
+	return contains(ApiKeyPermissions, permission)
+}
+
+// GenerateApiKey returns a new API key. Only its hash is stored.
+func GenerateApiKey() (string, error) {
+	token, err := GenerateRandomToken(32)
+	if err != nil {
+		return "", err
+	}
+	return ApiKeyPrefix + token, nil
+}
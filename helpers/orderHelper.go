@@ -0,0 +1,77 @@
+package helpers
+
+// An order is PLACED and then ACCEPTED, PREPARING, READY, SERVED and PAID in that order.
+// It can be CANCELLED until it is served and REFUNDED once it is paid.
+const (
+	OrderStatusPlaced    = "PLACED"
+	OrderStatusAccepted  = "ACCEPTED"
+	OrderStatusPreparing = "PREPARING"
+	OrderStatusReady     = "READY"
+	OrderStatusServed    = "SERVED"
+	OrderStatusPaid      = "PAID"
+	OrderStatusCancelled = "CANCELLED"
+	OrderStatusRefunded  = "REFUNDED"
+)
+
+const (
+	OrderActionAccept  = "accept"
+	OrderActionPrepare = "prepare"
+	OrderActionReady   = "ready"
+	OrderActionServe   = "serve"
+	OrderActionPay     = "pay"
+	OrderActionCancel  = "cancel"
+	OrderActionRefund  = "refund"
+)
+
+// OrderTransition lets the roles move an order with the action from one status to another
+type OrderTransition struct {
+	Action string
+	From   string
+	To     string
+	Roles  []string
+}
+
+// OrderTransitions lists every move an order can make. A move that is not listed is illegal.
+var OrderTransitions = []OrderTransition{
+	{Action: OrderActionAccept, From: OrderStatusPlaced, To: OrderStatusAccepted, Roles: []string{RoleAdmin, RoleManager, RoleWaiter}},
+	{Action: OrderActionPrepare, From: OrderStatusAccepted, To: OrderStatusPreparing, Roles: []string{RoleAdmin, RoleManager, RoleKitchen}},
+	{Action: OrderActionReady, From: OrderStatusPreparing, To: OrderStatusReady, Roles: []string{RoleAdmin, RoleManager, RoleKitchen}},
+	{Action: OrderActionServe, From: OrderStatusReady, To: OrderStatusServed, Roles: []string{RoleAdmin, RoleManager, RoleWaiter}},
+	{Action: OrderActionPay, From: OrderStatusServed, To: OrderStatusPaid, Roles: []string{RoleAdmin, RoleManager, RoleWaiter}},
+	{Action: OrderActionCancel, From: OrderStatusPlaced, To: OrderStatusCancelled, Roles: []string{RoleAdmin, RoleManager, RoleWaiter}},
+	{Action: OrderActionCancel, From: OrderStatusAccepted, To: OrderStatusCancelled, Roles: []string{RoleAdmin, RoleManager, RoleWaiter}},
+	// once the kitchen has started only a manager can call it off
+	{Action: OrderActionCancel, From: OrderStatusPreparing, To: OrderStatusCancelled, Roles: []string{RoleAdmin, RoleManager}},
+	{Action: OrderActionCancel, From: OrderStatusReady, To: OrderStatusCancelled, Roles: []string{RoleAdmin, RoleManager}},
+	{Action: OrderActionRefund, From: OrderStatusPaid, To: OrderStatusRefunded, Roles: []string{RoleAdmin, RoleManager}},
+}
+
+// OrderOpenStatuses are the statuses of an order that still holds its table
+var OrderOpenStatuses = []string{OrderStatusPlaced, OrderStatusAccepted, OrderStatusPreparing, OrderStatusReady, OrderStatusServed}
+
+func IsOrderOpen(status string) bool {
+	return contains(OrderOpenStatuses, status)
+}
+
+// OrderEditableStatuses are the statuses in which items can still be added to, changed on or removed from an order
+var OrderEditableStatuses = []string{OrderStatusPlaced, OrderStatusAccepted}
+
+func IsOrderEditable(status string) bool {
+	return contains(OrderEditableStatuses, status)
+}
+
+// OrderActionRoles returns every role that can move an order with the action from at least one status
+func OrderActionRoles(action string) []string {
+	var roles []string
+	for _, transition := range OrderTransitions {
+		if transition.Action != action {
+			continue
+		}
+		for _, role := range transition.Roles {
+			if !contains(roles, role) {
+				roles = append(roles, role)
+			}
+		}
+	}
+	return roles
+}
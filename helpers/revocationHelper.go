@@ -0,0 +1,11 @@
+package helpers
+
+// TokenRevocationKey is the denylist key of a single token
+func TokenRevocationKey(jti string) string {
+	return "jti:" + jti
+}
+
+// FamilyRevocationKey is the denylist key of every access token issued from a refresh token family
+func FamilyRevocationKey(familyID string) string {
+	return "family:" + familyID
+}
@@ -0,0 +1,128 @@
+package helpers
+
+const (
+	RoleAdmin    = "Admin"
+	RoleManager  = "Manager"
+	RoleWaiter   = "Waiter"
+	RoleKitchen  = "Kitchen"
+	RoleCustomer = "Customer"
+)
+
+const (
+	PermManageFood        = "food:write"
+	PermManageMenus       = "menus:write"
+	PermManageCategories  = "categories:write"
+	PermManageTables      = "tables:write"
+	PermManageRestaurants = "restaurants:write"
+	PermReadOrders        = "orders:read"
+	PermWriteOrders       = "orders:write"
+	PermDeleteOrders      = "orders:delete"
+	PermReadOrderItems    = "order_items:read"
+	PermWriteOrderItems   = "order_items:write"
+	PermReadInvoices      = "invoices:read"
+	PermWriteInvoices     = "invoices:write"
+	PermDeleteInvoices    = "invoices:delete"
+	PermReadUsers         = "users:read"
+	PermWriteUsers        = "users:write"
+	PermDeleteUsers       = "users:delete"
+	PermRevokeSessions    = "sessions:revoke"
+	PermManageRoles       = "roles:manage"
+	PermManageApiKeys     = "api_keys:manage"
+)
+
+// Roles lists every role a user can be assigned, in order of decreasing privilege
+var Roles = []string{RoleAdmin, RoleManager, RoleWaiter, RoleKitchen, RoleCustomer}
+
+// Permissions lists every permission that can be granted to a role
+var Permissions = []string{
+	PermManageFood,
+	PermManageMenus,
+	PermManageCategories,
+	PermManageTables,
+	PermManageRestaurants,
+	PermReadOrders,
+	PermWriteOrders,
+	PermDeleteOrders,
+	PermReadOrderItems,
+	PermWriteOrderItems,
+	PermReadInvoices,
+	PermWriteInvoices,
+	PermDeleteInvoices,
+	PermReadUsers,
+	PermWriteUsers,
+	PermDeleteUsers,
+	PermRevokeSessions,
+	PermManageRoles,
+	PermManageApiKeys,
+}
+
+// DefaultRolePermissions is the permission matrix used until an admin stores a different one in the roles collection
+var DefaultRolePermissions = map[string][]string{
+	RoleAdmin: Permissions,
+	RoleManager: {
+		PermManageFood,
+		PermManageMenus,
+		PermManageCategories,
+		PermManageTables,
+		PermManageRestaurants,
+		PermReadOrders,
+		PermWriteOrders,
+		PermDeleteOrders,
+		PermReadOrderItems,
+		PermWriteOrderItems,
+		PermReadInvoices,
+		PermWriteInvoices,
+		PermReadUsers,
+	},
+	RoleWaiter: {
+		PermReadOrders,
+		PermWriteOrders,
+		PermReadOrderItems,
+		PermWriteOrderItems,
+		PermWriteInvoices,
+	},
+	RoleKitchen: {
+		PermReadOrders,
+		PermWriteOrders,
+		PermReadOrderItems,
+	},
+	RoleCustomer: {},
+}
+
+// DefaultMfaRequired lists the roles that must use two-factor authentication until an admin stores a different policy
+var DefaultMfaRequired = map[string]bool{
+	RoleAdmin: true,
+}
+
+// NormalizeRole maps the legacy "User" role onto Customer
+func NormalizeRole(role string) string {
+	if role == "User" || role == "" {
+		return RoleCustomer
+	}
+	return role
+}
+
+func IsValidRole(role string) bool {
+	return contains(Roles, role)
+}
+
+func IsValidPermission(permission string) bool {
+	return contains(Permissions, permission)
+}
+
+func HasRole(role string, roles ...string) bool {
+	return contains(roles, NormalizeRole(role))
+}
+
+func HasPermission(granted []string, permission string) bool {
+	return contains(granted, permission)
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
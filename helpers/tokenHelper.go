@@ -1,58 +1,40 @@
 package helpers
 
 import (
-	"context"
-	"fmt"
-	"log"
-	"os"
-	"time"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 
-	"github.com/ShahSau/culinary-bliss/database"
-	"github.com/dgrijalva/jwt-go/v4"
-	"go.mongodb.org/mongo-driver/bson"
-	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo"
+	"github.com/ShahSau/culinary-bliss/tokens"
 )
 
-type SignedDetails struct {
-	Email      string
-	First_name string
-	Last_name  string
-	User_id    string
-	jwt.StandardClaims
-}
-
-var userCollection *mongo.Collection = database.GetCollection(database.DB, "users")
-
-var SECRET_KEY string = os.Getenv("SECRET_KEY")
-
-func GenerateAllTokens(email string, firstName string, lastName string, user_id string) (signedToken string, signedRefreshToken string, err error) {
-	claims := &SignedDetails{
-		Email:      email,
-		First_name: firstName,
-		Last_name:  lastName,
-		User_id:    user_id,
-		StandardClaims: jwt.StandardClaims{
-			ExpiresAt: jwt.At(time.Now().Local().Add(time.Hour * time.Duration(24))), // 24 hours
-		},
+func GenerateAllTokens(email string, firstName string, lastName string, user_id string, role string, permissions []string, family_id string) (signedToken string, signedRefreshToken string, err error) {
+	claims := &tokens.Claims{
+		Email:       email,
+		First_name:  firstName,
+		Last_name:   lastName,
+		User_id:     user_id,
+		Role:        role,
+		Permissions: permissions,
+		Family_id:   family_id,
 	}
 
-	refreshClaims := &SignedDetails{
-		Email:      email,
-		First_name: firstName,
-		Last_name:  lastName,
-		User_id:    user_id,
-		StandardClaims: jwt.StandardClaims{
-			ExpiresAt: jwt.At(time.Now().Local().Add(time.Hour * time.Duration(24*7))), // 7 days
-		},
+	refreshClaims := &tokens.Claims{
+		Email:       email,
+		First_name:  firstName,
+		Last_name:   lastName,
+		User_id:     user_id,
+		Role:        role,
+		Permissions: permissions,
+		Family_id:   family_id,
 	}
 
-	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(SECRET_KEY))
+	token, err := tokens.Sign(claims, tokens.AccessToken, tokens.AccessTokenTTL)
 	if err != nil {
 		return "", "", err
 	}
 
-	refreshToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, refreshClaims).SignedString([]byte(SECRET_KEY))
+	refreshToken, err := tokens.Sign(refreshClaims, tokens.RefreshToken, tokens.RefreshTokenTTL)
 	if err != nil {
 		return "", "", err
 	}
@@ -60,40 +42,27 @@ func GenerateAllTokens(email string, firstName string, lastName string, user_id
 	return token, refreshToken, nil
 }
 
-func UpdateAllTokens(signedToken string, signedRefreshToken string, user_id string) {
-	var ctx, _ = context.WithTimeout(context.Background(), 100*time.Second)
-	var updateObj primitive.D
-
-	updateObj = append(updateObj, bson.E{Key: "$set", Value: bson.D{{Key: "token", Value: signedToken}, {Key: "refresh_token", Value: signedRefreshToken}}})
-	updatedAt, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
-	updateObj = append(updateObj, bson.E{Key: "$set", Value: bson.D{{Key: "updated_at", Value: updatedAt}}})
-
-	_, err := userCollection.UpdateOne(ctx, bson.D{{Key: "user_id", Value: user_id}}, updateObj)
-
-	if err != nil {
-		log.Panic(err)
-		return
+// GenerateMfaToken mints the short lived challenge token that proves the password step of a login succeeded
+func GenerateMfaToken(email string, user_id string) (string, error) {
+	claims := &tokens.Claims{
+		Email:   email,
+		User_id: user_id,
 	}
 
+	return tokens.Sign(claims, tokens.MfaToken, tokens.MfaTokenTTL)
 }
 
-func ValidateToken(signedToken string) (claims *SignedDetails, err error) {
-	token, err := jwt.ParseWithClaims(
-		signedToken,
-		&SignedDetails{},
-		func(token *jwt.Token) (interface{}, error) {
-			return []byte(SECRET_KEY), nil
-		},
-	)
-
-	claims, ok := token.Claims.(*SignedDetails)
-	if !ok {
-		return nil, fmt.Errorf("invalid token")
-	}
+// HashToken returns the hex encoded SHA-256 of a token so it can be stored and compared without keeping the token itself
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
 
-	if claims.ExpiresAt.Unix() < time.Now().Local().Unix() {
-		return nil, fmt.Errorf("expired token")
+// GenerateRandomToken returns a hex encoded cryptographically random token of the given number of bytes
+func GenerateRandomToken(size int) (string, error) {
+	bytes := make([]byte, size)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
 	}
-
-	return claims, nil
+	return hex.EncodeToString(bytes), nil
 }
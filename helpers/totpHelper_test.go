@@ -0,0 +1,66 @@
+package helpers
+
+import (
+	"testing"
+	"time"
+)
+
+// rfc6238Secret is the SHA-1 key of the RFC 6238 test vectors, "12345678901234567890", in base32
+const rfc6238Secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+// The RFC lists 8 digit codes, the last 6 digits are the 6 digit codes
+var rfc6238Vectors = []struct {
+	unix int64
+	code string
+}{
+	{unix: 59, code: "287082"},
+	{unix: 1111111109, code: "081804"},
+	{unix: 1111111111, code: "050471"},
+	{unix: 1234567890, code: "005924"},
+	{unix: 2000000000, code: "279037"},
+	{unix: 20000000000, code: "353130"},
+}
+
+func TestTOTPCodeMatchesRFC6238(t *testing.T) {
+	for _, vector := range rfc6238Vectors {
+		code, err := TOTPCode(rfc6238Secret, time.Unix(vector.unix, 0))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if code != vector.code {
+			t.Errorf("at %d: expected %s, got %s", vector.unix, vector.code, code)
+		}
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	for _, vector := range rfc6238Vectors {
+		at := time.Unix(vector.unix, 0)
+		step, ok := ValidateTOTP(rfc6238Secret, vector.code, 0, at)
+		if !ok || step != vector.unix/totpPeriod {
+			t.Errorf("at %d: expected step %d to match, got %d %v", vector.unix, vector.unix/totpPeriod, step, ok)
+		}
+	}
+
+	at := time.Unix(1111111111, 0)
+	tests := []struct {
+		name     string
+		code     string
+		lastStep int64
+		at       time.Time
+		ok       bool
+	}{
+		{name: "spaces are ignored", code: "050 471", at: at, ok: true},
+		{name: "a period early", code: "050471", at: at.Add(-totpPeriod * time.Second), ok: true},
+		{name: "a period late", code: "050471", at: at.Add(totpPeriod * time.Second), ok: true},
+		{name: "two periods late", code: "050471", at: at.Add(2 * totpPeriod * time.Second)},
+		{name: "already used", code: "050471", lastStep: 1111111111 / totpPeriod, at: at},
+		{name: "wrong code", code: "050472", at: at},
+		{name: "too short", code: "05047", at: at},
+	}
+	for _, test := range tests {
+		if _, ok := ValidateTOTP(rfc6238Secret, test.code, test.lastStep, test.at); ok != test.ok {
+			t.Errorf("%s: expected %v, got %v", test.name, test.ok, ok)
+		}
+	}
+}
@@ -0,0 +1,11 @@
+package helpers
+
+const (
+	UserStatusPending = "pending"
+	UserStatusActive  = "active"
+)
+
+// IsUserActive treats accounts created before email verification existed as active
+func IsUserActive(status string) bool {
+	return status == UserStatusActive || status == ""
+}
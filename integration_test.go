@@ -0,0 +1,305 @@
+//go:build integration
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"regexp"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ShahSau/culinary-bliss/config"
+	"github.com/ShahSau/culinary-bliss/controllers"
+	"github.com/ShahSau/culinary-bliss/helpers"
+	"github.com/ShahSau/culinary-bliss/mailer"
+	"github.com/ShahSau/culinary-bliss/migrations"
+	"github.com/ShahSau/culinary-bliss/models"
+	"github.com/ShahSau/culinary-bliss/repositories"
+	"github.com/ShahSau/culinary-bliss/services"
+	"github.com/ShahSau/culinary-bliss/testmongo"
+	"github.com/ShahSau/culinary-bliss/tokens"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// The integration tests run the whole API against a real mongod, see testmongo for where it comes from:
+//
+//	go test -tags=integration .
+
+// testConfig is what newRouter reads from the configuration
+var testConfig = config.Config{Cors: config.Cors{Origins: []string{"http://app.test"}}}
+
+// password is the password of every seeded user
+const password = "correct horse battery staple"
+
+var (
+	client *mongo.Client
+	// passwordHash is computed once, hashing is slow on purpose
+	passwordHash string
+	// seeded numbers the seeded users so their phone numbers differ
+	seeded atomic.Int64
+	// exercised records every route a test requested, by method and path pattern
+	exercised sync.Map
+)
+
+func TestMain(m *testing.M) {
+	os.Exit(runIntegration(m))
+}
+
+func runIntegration(m *testing.M) int {
+	gin.SetMode(gin.TestMode)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	server, err := testmongo.Start(ctx)
+	if errors.Is(err, testmongo.ErrNoMongod) {
+		fmt.Println("skipping the integration tests:", err)
+		return 0
+	}
+	if err != nil {
+		fmt.Println(err)
+		return 1
+	}
+	defer server.Stop()
+
+	client, err = mongo.Connect(ctx, options.Client().ApplyURI(server.URI))
+	if err != nil {
+		fmt.Println(err)
+		return 1
+	}
+	defer client.Disconnect(context.Background())
+
+	keyDir, err := os.MkdirTemp("", "integration-keys-")
+	if err != nil {
+		fmt.Println(err)
+		return 1
+	}
+	defer os.RemoveAll(keyDir)
+	tokens.Configure(tokens.Settings{KeyDir: keyDir, SigningAlg: tokens.SigningAlgEdDSA, Issuer: "culinary-bliss", Audience: "culinary-bliss-api"})
+	if err := tokens.LoadSigningKeys(); err != nil {
+		fmt.Println(err)
+		return 1
+	}
+
+	passwordHash = services.HashPassword(password)
+
+	code := m.Run()
+	// a partial run cannot be expected to reach every route
+	if code == 0 && flag.Lookup("test.run").Value.String() == "" {
+		if missed := unexercisedRoutes(); len(missed) > 0 {
+			fmt.Println("no integration test requests these routes:")
+			for _, route := range missed {
+				fmt.Println("\t" + route)
+			}
+			code = 1
+		}
+	}
+	return code
+}
+
+// unexercisedRoutes lists the routes of the API no test requested
+func unexercisedRoutes() []string {
+	router, err := newRouter(testConfig, services.New(repositories.NewMemory(), &outbox{}, ""), controllers.NewHealth())
+	if err != nil {
+		panic(err)
+	}
+	var missed []string
+	for _, route := range router.Routes() {
+		if _, ok := exercised.Load(route.Method + " " + route.Path); !ok {
+			missed = append(missed, route.Method+" "+route.Path)
+		}
+	}
+	sort.Strings(missed)
+	return missed
+}
+
+// outbox keeps the mail the API sends so tests can follow the links in it
+type outbox struct {
+	mu       sync.Mutex
+	messages []mailer.Message
+}
+
+func (o *outbox) Send(msg mailer.Message) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.messages = append(o.messages, msg)
+	return nil
+}
+
+var linkToken = regexp.MustCompile(`token=([^\s&]+)`)
+
+// token returns the token of the last link mailed to the address
+func (o *outbox) token(t *testing.T, to string) string {
+	t.Helper()
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	for i := len(o.messages) - 1; i >= 0; i-- {
+		if o.messages[i].To != to {
+			continue
+		}
+		if match := linkToken.FindStringSubmatch(o.messages[i].Body); match != nil {
+			return match[1]
+		}
+	}
+	t.Fatalf("no link was mailed to %s", to)
+	return ""
+}
+
+// app is the API serving a database of its own
+type app struct {
+	t      *testing.T
+	router *gin.Engine
+	// matcher has the routes of router and records which one a request was for
+	matcher *gin.Engine
+	svc     *services.Service
+	repos   *repositories.Repositories
+	mail    *outbox
+}
+
+// newApp migrates a fresh database and serves the API on it, the database is dropped when the test ends
+func newApp(t *testing.T) *app {
+	t.Helper()
+	ctx := context.Background()
+
+	db := client.Database("it_" + primitive.NewObjectID().Hex())
+	t.Cleanup(func() { db.Drop(context.Background()) })
+	if _, err := migrations.New(db).Up(ctx, false); err != nil {
+		t.Fatal(err)
+	}
+
+	a := &app{t: t, repos: repositories.NewMongo(db), mail: &outbox{}}
+	a.svc = services.New(a.repos, a.mail, "http://app.test")
+	router, err := newRouter(testConfig, a.svc, controllers.NewHealth(
+		controllers.Check{Name: "mongo", Run: func(ctx context.Context) error { return client.Ping(ctx, nil) }},
+		controllers.Check{Name: "signing_keys", Run: func(ctx context.Context) error { return tokens.Ready() }},
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+	a.router = router
+
+	a.matcher = gin.New()
+	for _, route := range a.router.Routes() {
+		a.matcher.Handle(route.Method, route.Path, func(c *gin.Context) {
+			exercised.Store(c.Request.Method+" "+c.FullPath(), true)
+		})
+	}
+
+	// the Admin role requires two-factor authentication by default, only the MFA tests need it
+	if _, err := a.repos.Roles.Save(ctx, models.Role{Name: helpers.RoleAdmin, Permissions: helpers.Permissions}); err != nil {
+		t.Fatal(err)
+	}
+	return a
+}
+
+// seedUser stores an active user with the role, who can login with password
+func (a *app) seedUser(role string, email string) models.User {
+	a.t.Helper()
+	now := time.Now().UTC().Truncate(time.Second)
+	user := models.User{
+		ID:         primitive.NewObjectID(),
+		First_name: "Test",
+		Last_name:  role,
+		Email:      email,
+		Password:   passwordHash,
+		Phone:      fmt.Sprintf("+44 20 7946 %04d", seeded.Add(1)),
+		Role:       role,
+		Status:     helpers.UserStatusActive,
+		VerifiedAt: &now,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	user.User_id = user.ID.Hex()
+	if err := a.repos.Users.Create(context.Background(), user); err != nil {
+		a.t.Fatal(err)
+	}
+	return user
+}
+
+// login returns the access and refresh token of a user without a second factor
+func (a *app) login(email string) (string, string) {
+	a.t.Helper()
+	res := a.call(http.MethodPost, "/login", nil, map[string]string{"email": email, "password": password}, http.StatusOK)
+	token, _ := res["token"].(string)
+	refreshToken, _ := res["refreshToken"].(string)
+	if token == "" || refreshToken == "" {
+		a.t.Fatalf("login of %s returned no tokens: %v", email, res)
+	}
+	return token, refreshToken
+}
+
+// as authenticates a request
+type as func(req *http.Request)
+
+func bearer(token string) as {
+	return func(req *http.Request) { req.Header.Set("Authorization", "Bearer "+token) }
+}
+
+func apiKey(key string) as {
+	return func(req *http.Request) { req.Header.Set("X-API-Key", key) }
+}
+
+// call sends body as JSON and decodes the JSON answer, failing the test unless the status is want
+func (a *app) call(method string, path string, auth as, body interface{}, want int) map[string]interface{} {
+	a.t.Helper()
+	var payload io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			a.t.Fatal(err)
+		}
+		payload = bytes.NewReader(encoded)
+	}
+	req := httptest.NewRequest(method, path, payload)
+	req.Header.Set("Content-Type", "application/json")
+	if auth != nil {
+		auth(req)
+	}
+
+	a.matcher.ServeHTTP(httptest.NewRecorder(), req.Clone(context.Background()))
+	recorder := httptest.NewRecorder()
+	a.router.ServeHTTP(recorder, req)
+
+	if recorder.Code != want {
+		a.t.Fatalf("%s %s answered %d, want %d: %s", method, path, recorder.Code, want, recorder.Body)
+	}
+	decoded := map[string]interface{}{}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &decoded); err != nil {
+		a.t.Fatalf("%s %s answered %q: %v", method, path, recorder.Body, err)
+	}
+	return decoded
+}
+
+// data is the data of a response as an object
+func data(t *testing.T, res map[string]interface{}) map[string]interface{} {
+	t.Helper()
+	object, ok := res["data"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected an object in %v", res)
+	}
+	return object
+}
+
+// items is the data of a list response
+func items(t *testing.T, res map[string]interface{}) []interface{} {
+	t.Helper()
+	list, ok := res["data"].([]interface{})
+	if !ok {
+		t.Fatalf("expected a list in %v", res)
+	}
+	return list
+}
@@ -0,0 +1,182 @@
+package listing
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Apply lists documents held in memory the way a database would: filtered, sorted and paged after the cursor
+func Apply[T any](documents []T, query Query) Page[T] {
+	matching := []T{}
+	for _, document := range documents {
+		if matches(document, query.Filters) {
+			matching = append(matching, document)
+		}
+	}
+	total := int64(len(matching))
+
+	sort.SliceStable(matching, func(i, j int) bool {
+		return compareSorted(sortValues(matching[i], query.Sort), sortValues(matching[j], query.Sort), query.Sort) < 0
+	})
+
+	start := 0
+	if query.After != nil {
+		start = sort.Search(len(matching), func(i int) bool {
+			return compareSorted(sortValues(matching[i], query.Sort), query.After, query.Sort) > 0
+		})
+	}
+	end := start + query.Limit + 1
+	if end > len(matching) {
+		end = len(matching)
+	}
+	return NewPage(query, matching[start:end], total)
+}
+
+// matches reports whether the document passes every filter
+func matches[T any](document T, filters []Filter) bool {
+	for _, filter := range filters {
+		value := fieldValue(document, filter.Field)
+		var ok bool
+		switch filter.Op {
+		case Eq:
+			ok = compare(value, filter.Value) == 0
+		case Ne:
+			ok = compare(value, filter.Value) != 0
+		case Gt:
+			ok = compare(value, filter.Value) > 0
+		case Gte:
+			ok = compare(value, filter.Value) >= 0
+		case Lt:
+			ok = compare(value, filter.Value) < 0
+		case Lte:
+			ok = compare(value, filter.Value) <= 0
+		case In:
+			for _, candidate := range filter.Value.([]interface{}) {
+				if compare(value, candidate) == 0 {
+					ok = true
+					break
+				}
+			}
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// compareSorted compares two rows of sort values in the order the sort asks for
+func compareSorted(a []interface{}, b []interface{}, sorts []Sort) int {
+	for i, s := range sorts {
+		c := compare(a[i], b[i])
+		if s.Desc {
+			c = -c
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// compare orders values of the same kind, a missing value comes first like in Mongo
+func compare(a interface{}, b interface{}) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	}
+
+	switch a := a.(type) {
+	case string:
+		return strings.Compare(a, b.(string))
+	case float64:
+		b := b.(float64)
+		switch {
+		case a < b:
+			return -1
+		case a > b:
+			return 1
+		}
+		return 0
+	case bool:
+		b := b.(bool)
+		switch {
+		case a == b:
+			return 0
+		case !a:
+			return -1
+		}
+		return 1
+	case time.Time:
+		return a.Compare(b.(time.Time))
+	}
+	return 0
+}
+
+// sortValues reads the values a document is sorted by
+func sortValues[T any](document T, sorts []Sort) []interface{} {
+	values := make([]interface{}, len(sorts))
+	for i, s := range sorts {
+		values[i] = fieldValue(document, s.Field)
+	}
+	return values
+}
+
+// fieldIndexes caches, per struct type, the index of the field stored under each bson name
+var fieldIndexes sync.Map
+
+// fieldValue reads the field stored under name as a string, float64, bool or time.Time, or nil if it is missing.
+// A dotted name reads a field of an embedded document, e.g. price.amount.
+func fieldValue(document interface{}, name string) interface{} {
+	v := reflect.Indirect(reflect.ValueOf(document))
+	if !v.IsValid() || v.Kind() != reflect.Struct {
+		return nil
+	}
+	name, rest, nested := strings.Cut(name, ".")
+	indexes, ok := fieldIndexes.Load(v.Type())
+	if !ok {
+		byName := map[string]int{}
+		for i := 0; i < v.NumField(); i++ {
+			tag := strings.Split(v.Type().Field(i).Tag.Get("bson"), ",")[0]
+			if tag != "" && tag != "-" {
+				byName[tag] = i
+			}
+		}
+		indexes, _ = fieldIndexes.LoadOrStore(v.Type(), byName)
+	}
+	i, ok := indexes.(map[string]int)[name]
+	if !ok {
+		return nil
+	}
+
+	field := reflect.Indirect(v.Field(i))
+	if !field.IsValid() {
+		return nil
+	}
+	if nested {
+		return fieldValue(field.Interface(), rest)
+	}
+	switch field.Kind() {
+	case reflect.String:
+		return field.String()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(field.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(field.Uint())
+	case reflect.Float32, reflect.Float64:
+		return field.Float()
+	case reflect.Bool:
+		return field.Bool()
+	}
+	if t, ok := field.Interface().(time.Time); ok {
+		return t
+	}
+	return nil
+}
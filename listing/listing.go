@@ -0,0 +1,343 @@
+// Package listing turns the query of a list request into filters, a sort order and a cursor that any repository can apply
+package listing
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ShahSau/culinary-bliss/apperrors"
+	"github.com/ShahSau/culinary-bliss/money"
+	"github.com/ShahSau/culinary-bliss/types"
+)
+
+const (
+	DefaultLimit = 20
+	MaxLimit     = 100
+)
+
+// Kind is the type of the values of a field
+type Kind int
+
+const (
+	String Kind = iota
+	Number
+	Bool
+	Time
+	// Money fields hold a money.Money, they are filtered and sorted by its amount in the configured currency
+	Money
+)
+
+// Op compares a field with the value of a filter
+type Op string
+
+const (
+	Eq  Op = "eq"
+	Ne  Op = "ne"
+	Gt  Op = "gt"
+	Gte Op = "gte"
+	Lt  Op = "lt"
+	Lte Op = "lte"
+	In  Op = "in"
+)
+
+// The usual operators of fields that are matched exactly and of fields that are compared
+var (
+	Equality = []Op{Eq, Ne, In}
+	Range    = []Op{Eq, Ne, Gt, Gte, Lt, Lte}
+)
+
+// Field is a field clients may filter or sort a list by
+type Field struct {
+	Kind     Kind
+	Ops      []Op
+	Sortable bool
+}
+
+// Spec whitelists how a resource can be listed. Fields are named as they are stored, which is also how clients name them.
+type Spec struct {
+	// ID is a unique string field that orders documents with equal sort values
+	ID          string
+	Fields      map[string]Field
+	DefaultSort string
+}
+
+// Filter keeps the documents whose field compares to Value with Op. The Value of In is a []interface{}.
+type Filter struct {
+	Field string
+	Op    Op
+	Value interface{}
+}
+
+// Sort orders a list by one field
+type Sort struct {
+	Field string
+	Desc  bool
+	Kind  Kind
+}
+
+// Query is a parsed list request
+type Query struct {
+	Filters []Filter
+	// Sort always ends with the ID of the spec so the order is total
+	Sort  []Sort
+	Limit int
+	// After holds the sort values of the last document of the previous page, it is nil on the first page
+	After []interface{}
+}
+
+// Page is one page of a list
+type Page[T any] struct {
+	Items []T `json:"items"`
+	// Total counts the documents matching the filters on every page
+	Total int64 `json:"total"`
+	Limit int   `json:"limit"`
+	// NextCursor continues the list after this page, it is empty on the last page
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// cursor is what an opaque cursor encodes
+type cursor struct {
+	Sort   string        `json:"s"`
+	Values []interface{} `json:"v"`
+}
+
+var filterKey = regexp.MustCompile(`^(\w+)(?:\[(\w+)\])?$`)
+
+// Parse checks a list request against the spec, reporting every unknown filter or bad value as a field error
+func Parse(spec Spec, req types.ListRequest) (Query, error) {
+	var problems []apperrors.FieldError
+	invalid := func(field string, format string, args ...interface{}) {
+		problems = append(problems, apperrors.FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	query := Query{Limit: DefaultLimit}
+	if value := req.Query.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > MaxLimit {
+			invalid("limit", "must be between 1 and %d", MaxLimit)
+		} else {
+			query.Limit = limit
+		}
+	}
+
+	sortParam := req.Query.Get("sort")
+	if sortParam == "" {
+		sortParam = spec.DefaultSort
+	}
+	for _, name := range strings.Split(sortParam, ",") {
+		desc := strings.HasPrefix(name, "-")
+		name = strings.TrimPrefix(name, "-")
+		if name == "" {
+			continue
+		}
+		field, ok := spec.Fields[name]
+		if name != spec.ID && (!ok || !field.Sortable) {
+			invalid("sort", "cannot sort by %s", name)
+			continue
+		}
+		query.Sort = append(query.Sort, Sort{Field: storedAs(name, field), Desc: desc, Kind: comparedAs(field)})
+	}
+	if n := len(query.Sort); n == 0 || query.Sort[n-1].Field != spec.ID {
+		desc := n > 0 && query.Sort[n-1].Desc
+		query.Sort = append(query.Sort, Sort{Field: spec.ID, Desc: desc, Kind: String})
+	}
+
+	for key, values := range req.Query {
+		if key == "limit" || key == "sort" || key == "cursor" {
+			continue
+		}
+		match := filterKey.FindStringSubmatch(key)
+		if match == nil {
+			invalid(key, "is not a filter")
+			continue
+		}
+		field, ok := spec.Fields[match[1]]
+		if !ok {
+			invalid(key, "is not a filter")
+			continue
+		}
+		op := Eq
+		if match[2] != "" {
+			op = Op(match[2])
+		}
+		if !supports(field, op) {
+			invalid(key, "%s cannot be filtered with %s", match[1], op)
+			continue
+		}
+		for _, raw := range values {
+			value, err := parseValue(field.Kind, op, raw)
+			if err != nil {
+				invalid(key, "%s", err)
+				continue
+			}
+			query.Filters = append(query.Filters, Filter{Field: storedAs(match[1], field), Op: op, Value: value})
+		}
+	}
+
+	if value := req.Query.Get("cursor"); value != "" {
+		after, err := decodeCursor(value, query.Sort)
+		if err != nil {
+			invalid("cursor", "is not a cursor of this list, start again without it")
+		}
+		query.After = after
+	}
+
+	if len(problems) > 0 {
+		return Query{}, apperrors.Invalid(problems...)
+	}
+	return query, nil
+}
+
+func supports(field Field, op Op) bool {
+	for _, allowed := range field.Ops {
+		if allowed == op {
+			return true
+		}
+	}
+	return false
+}
+
+// storedAs is the path of the value a field is compared by
+func storedAs(name string, field Field) string {
+	if field.Kind == Money {
+		return name + ".amount"
+	}
+	return name
+}
+
+// comparedAs is the kind of the value a field is compared by
+func comparedAs(field Field) Kind {
+	if field.Kind == Money {
+		return Number
+	}
+	return field.Kind
+}
+
+func parseValue(kind Kind, op Op, raw string) (interface{}, error) {
+	if op == In {
+		var values []interface{}
+		for _, part := range strings.Split(raw, ",") {
+			value, err := parseValue(kind, Eq, part)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+		}
+		return values, nil
+	}
+
+	switch kind {
+	case Number:
+		value, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return nil, fmt.Errorf("must be a number")
+		}
+		return value, nil
+	case Money:
+		value, err := money.Parse(raw, money.Default())
+		if err != nil {
+			return nil, fmt.Errorf("must be an amount like 12.50")
+		}
+		return float64(value.Amount), nil
+	case Bool:
+		value, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("must be true or false")
+		}
+		return value, nil
+	case Time:
+		value, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return nil, fmt.Errorf("must be a time like 2024-06-01T12:00:00Z")
+		}
+		return value, nil
+	default:
+		return raw, nil
+	}
+}
+
+// sortKey identifies the sort order a cursor belongs to
+func sortKey(sort []Sort) string {
+	var fields []string
+	for _, s := range sort {
+		if s.Desc {
+			fields = append(fields, "-"+s.Field)
+		} else {
+			fields = append(fields, s.Field)
+		}
+	}
+	return strings.Join(fields, ",")
+}
+
+func encodeCursor(sort []Sort, values []interface{}) string {
+	for i, value := range values {
+		if t, ok := value.(time.Time); ok {
+			values[i] = t.Format(time.RFC3339Nano)
+		}
+	}
+	encoded, _ := json.Marshal(cursor{Sort: sortKey(sort), Values: values})
+	return base64.RawURLEncoding.EncodeToString(encoded)
+}
+
+// decodeCursor returns the sort values a cursor continues after, typed the way the fields are
+func decodeCursor(value string, sort []Sort) ([]interface{}, error) {
+	decoded, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	var c cursor
+	if err := json.Unmarshal(decoded, &c); err != nil {
+		return nil, err
+	}
+	if c.Sort != sortKey(sort) || len(c.Values) != len(sort) {
+		return nil, fmt.Errorf("cursor of another sort order")
+	}
+
+	after := make([]interface{}, len(sort))
+	for i, s := range sort {
+		switch raw := c.Values[i].(type) {
+		case nil:
+			after[i] = nil
+		case string:
+			if s.Kind == Time {
+				t, err := time.Parse(time.RFC3339Nano, raw)
+				if err != nil {
+					return nil, err
+				}
+				after[i] = t
+			} else if s.Kind == String {
+				after[i] = raw
+			} else {
+				return nil, fmt.Errorf("bad cursor value")
+			}
+		case float64:
+			if s.Kind != Number {
+				return nil, fmt.Errorf("bad cursor value")
+			}
+			after[i] = raw
+		case bool:
+			if s.Kind != Bool {
+				return nil, fmt.Errorf("bad cursor value")
+			}
+			after[i] = raw
+		default:
+			return nil, fmt.Errorf("bad cursor value")
+		}
+	}
+	return after, nil
+}
+
+// NewPage builds the page of a query from up to Limit+1 documents, the extra one only tells that there is a next page
+func NewPage[T any](query Query, items []T, total int64) Page[T] {
+	page := Page[T]{Items: items, Total: total, Limit: query.Limit}
+	if len(items) > query.Limit {
+		page.Items = items[:query.Limit]
+		page.NextCursor = encodeCursor(query.Sort, sortValues(page.Items[query.Limit-1], query.Sort))
+	}
+	return page
+}
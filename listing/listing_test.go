@@ -0,0 +1,199 @@
+package listing
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ShahSau/culinary-bliss/apperrors"
+	"github.com/ShahSau/culinary-bliss/money"
+	"github.com/ShahSau/culinary-bliss/types"
+)
+
+type dish struct {
+	ID        string    `bson:"dish_id"`
+	Name      string    `bson:"name"`
+	Price     float64   `bson:"price"`
+	Vegan     bool      `bson:"vegan"`
+	CreatedAt time.Time `bson:"created_at"`
+}
+
+var dishes = Spec{
+	ID: "dish_id",
+	Fields: map[string]Field{
+		"name":       {Kind: String, Ops: Equality, Sortable: true},
+		"price":      {Kind: Number, Ops: Range, Sortable: true},
+		"vegan":      {Kind: Bool, Ops: []Op{Eq}},
+		"created_at": {Kind: Time, Ops: Range, Sortable: true},
+	},
+	DefaultSort: "-created_at",
+}
+
+func request(raw string) types.ListRequest {
+	query, err := url.ParseQuery(raw)
+	if err != nil {
+		panic(err)
+	}
+	return types.ListRequest{Query: query}
+}
+
+// fields returns the field errors of err, or fails the test if it is not a validation error
+func fields(t *testing.T, err error) map[string]string {
+	t.Helper()
+	var domain *apperrors.Error
+	if !errors.As(err, &domain) || !errors.Is(err, apperrors.ErrValidation) {
+		t.Fatalf("expected a validation error, got %v", err)
+	}
+	found := map[string]string{}
+	for _, field := range domain.Fields {
+		found[field.Field] = field.Message
+	}
+	return found
+}
+
+func TestParse(t *testing.T) {
+	query, err := Parse(dishes, request("limit=5&sort=price,-name&price[gte]=3&name[in]=Soup,Stew&vegan=true"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantSort := []Sort{{Field: "price", Kind: Number}, {Field: "name", Desc: true, Kind: String}, {Field: "dish_id", Desc: true, Kind: String}}
+	if query.Limit != 5 || !reflect.DeepEqual(query.Sort, wantSort) {
+		t.Fatalf("unexpected query %+v", query)
+	}
+	filters := map[string]Filter{}
+	for _, filter := range query.Filters {
+		filters[filter.Field] = filter
+	}
+	want := map[string]Filter{
+		"price": {Field: "price", Op: Gte, Value: 3.0},
+		"name":  {Field: "name", Op: In, Value: []interface{}{"Soup", "Stew"}},
+		"vegan": {Field: "vegan", Op: Eq, Value: true},
+	}
+	if !reflect.DeepEqual(filters, want) {
+		t.Fatalf("got filters %+v, want %+v", filters, want)
+	}
+
+	defaults, err := Parse(dishes, request(""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if defaults.Limit != DefaultLimit || sortKey(defaults.Sort) != "-created_at,-dish_id" {
+		t.Fatalf("unexpected defaults %+v", defaults)
+	}
+}
+
+func TestParseRejects(t *testing.T) {
+	otherSort := encodeCursor([]Sort{{Field: "price"}, {Field: "dish_id"}}, []interface{}{3.0, "d1"})
+
+	tests := []struct {
+		name  string
+		query string
+		want  map[string]string
+	}{
+		{"unknown filter", "colour=red", map[string]string{"colour": "is not a filter"}},
+		{"malformed filter", "price[gte=3", map[string]string{"price[gte": "is not a filter"}},
+		{"unsupported operator", "vegan[gt]=true", map[string]string{"vegan[gt]": "vegan cannot be filtered with gt"}},
+		{"bad value", "price[lt]=cheap&created_at[gte]=yesterday", map[string]string{
+			"price[lt]": "must be a number", "created_at[gte]": "must be a time like 2024-06-01T12:00:00Z",
+		}},
+		{"limit out of range", "limit=500", map[string]string{"limit": "must be between 1 and 100"}},
+		{"unsortable field", "sort=vegan", map[string]string{"sort": "cannot sort by vegan"}},
+		{"garbled cursor", "cursor=%21%21", map[string]string{"cursor": "is not a cursor of this list, start again without it"}},
+		{"cursor of another sort", "cursor=" + otherSort, map[string]string{"cursor": "is not a cursor of this list, start again without it"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := Parse(dishes, request(test.query))
+			if got := fields(t, err); !reflect.DeepEqual(got, test.want) {
+				t.Fatalf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestApplyPagesWithCursors(t *testing.T) {
+	start := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	var documents []dish
+	for i, name := range []string{"Soup", "Stew", "Salad", "Pie", "Tart", "Cake", "Bread"} {
+		documents = append(documents, dish{
+			ID:    string(rune('a' + i)),
+			Name:  name,
+			Price: float64(i%3 + 1),
+			Vegan: i%2 == 0,
+			// two dishes share every creation time so the id has to break the tie
+			CreatedAt: start.Add(time.Duration(i/2) * time.Hour),
+		})
+	}
+
+	var seen []string
+	raw := "limit=3"
+	for pages := 0; ; pages++ {
+		if pages > 3 {
+			t.Fatal("cursor never ran out")
+		}
+		query, err := Parse(dishes, request(raw))
+		if err != nil {
+			t.Fatal(err)
+		}
+		page := Apply(documents, query)
+		if page.Total != 7 || page.Limit != 3 {
+			t.Fatalf("unexpected page %+v", page)
+		}
+		for _, item := range page.Items {
+			seen = append(seen, item.ID)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		raw = "limit=3&cursor=" + page.NextCursor
+	}
+	if want := []string{"g", "f", "e", "d", "c", "b", "a"}; !reflect.DeepEqual(seen, want) {
+		t.Fatalf("got %v, want %v", seen, want)
+	}
+
+	query, err := Parse(dishes, request("price[gte]=2&name[in]=Stew,Salad,Tart,Cake&sort=price,name"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	page := Apply(documents, query)
+	var names []string
+	for _, item := range page.Items {
+		names = append(names, item.Name)
+	}
+	if want := []string{"Stew", "Tart", "Cake", "Salad"}; page.Total != 4 || !reflect.DeepEqual(names, want) || page.NextCursor != "" {
+		t.Fatalf("got %v of %d, want %v", names, page.Total, want)
+	}
+}
+
+func TestMoneyFieldsCompareByAmount(t *testing.T) {
+	type priced struct {
+		ID   string      `bson:"priced_id"`
+		Cost money.Money `bson:"cost"`
+	}
+	spec := Spec{ID: "priced_id", Fields: map[string]Field{"cost": {Kind: Money, Ops: Range, Sortable: true}}, DefaultSort: "cost"}
+	documents := []priced{
+		{ID: "a", Cost: money.MustParse("12")},
+		{ID: "b", Cost: money.MustParse("4.50")},
+		{ID: "c", Cost: money.MustParse("4.49")},
+	}
+
+	query, err := Parse(spec, request("cost[lte]=4.5&sort=-cost"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (Filter{Field: "cost.amount", Op: Lte, Value: 450.0}); !reflect.DeepEqual(query.Filters, []Filter{want}) {
+		t.Fatalf("got filters %+v, want %+v", query.Filters, want)
+	}
+	page := Apply(documents, query)
+	if page.Total != 2 || page.Items[0].ID != "b" || page.Items[1].ID != "c" {
+		t.Fatalf("unexpected page %+v", page)
+	}
+
+	_, err = Parse(spec, request("cost[gt]=cheap"))
+	if got := fields(t, err); got["cost[gt]"] != "must be an amount like 12.50" {
+		t.Fatalf("unexpected errors %v", got)
+	}
+}
@@ -0,0 +1,26 @@
+package mailer
+
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer delivers a message to a single recipient
+type Mailer interface {
+	Send(msg Message) error
+}
+
+// New picks the mailer for driver. "smtp" sends real mail through smtp, anything else writes
+// messages to logFile, or to the standard logger when no file is set.
+func New(driver string, logFile string, smtp *SMTPMailer) Mailer {
+	if driver == "smtp" {
+		return smtp
+	}
+
+	if logFile != "" {
+		return &FileMailer{Path: logFile}
+	}
+
+	return &LogMailer{}
+}
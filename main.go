@@ -1,40 +1,157 @@
 package main
 
 import (
+	"context"
+	"flag"
+	"log"
+	"net/http"
 	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
 
 	"time"
 
+	"github.com/ShahSau/culinary-bliss/config"
+	"github.com/ShahSau/culinary-bliss/controllers"
 	"github.com/ShahSau/culinary-bliss/database"
 	docs "github.com/ShahSau/culinary-bliss/docs"
+	"github.com/ShahSau/culinary-bliss/mailer"
 	"github.com/ShahSau/culinary-bliss/middleware"
+	"github.com/ShahSau/culinary-bliss/migrations"
+	"github.com/ShahSau/culinary-bliss/money"
+	"github.com/ShahSau/culinary-bliss/repositories"
 	"github.com/ShahSau/culinary-bliss/routes"
+	"github.com/ShahSau/culinary-bliss/services"
+	"github.com/ShahSau/culinary-bliss/tokens"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
 )
 
 func main() {
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
+	var err error
+	switch {
+	case len(os.Args) > 1 && os.Args[1] == "migrate":
+		err = runMigrate(os.Args[2:])
+	case len(os.Args) > 1 && os.Args[1] == "config":
+		err = runConfig(os.Args[2:])
+	default:
+		err = runServer(os.Args[1:])
 	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// runServer starts the API and serves it until it fails
+func runServer(args []string) error {
+	cfg, err := config.Load(flag.NewFlagSet("culinary-bliss", flag.ExitOnError), args)
+	if err != nil {
+		return err
+	}
+
+	// stopped is cancelled on SIGINT or SIGTERM
+	stopped, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	ctx, cancel := context.WithTimeout(stopped, 10*time.Second)
+	client, err := database.ConnectDB(ctx, cfg.Database.URI)
+	cancel()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := client.Disconnect(ctx); err != nil {
+			log.Println("Error disconnecting from MongoDB:", err)
+		}
+	}()
+
+	if err := money.Configure(cfg.Currency); err != nil {
+		return err
+	}
+
+	tokens.Configure(tokens.Settings{
+		KeyDir:      cfg.Tokens.KeyDir,
+		SigningAlg:  cfg.Tokens.SigningAlg,
+		KeyRotation: cfg.Tokens.KeyRotation.Duration,
+		Issuer:      cfg.Tokens.Issuer,
+		Audience:    cfg.Tokens.Audience,
+	})
+	if err := tokens.LoadSigningKeys(); err != nil {
+		return err
+	}
+	go tokens.StartKeyRotation(stopped)
+
+	db := database.Database(client, cfg.Database.Name)
+	// migrations are left to the migrate subcommand, an instance on an older schema reports itself not ready instead
+	runner := migrations.New(db)
 
-	database.ConnectDB()
+	repos := repositories.NewMongo(db)
+
+	mail := mailer.New(cfg.Mail.Driver, cfg.Mail.LogFile, &mailer.SMTPMailer{
+		Host:     cfg.Mail.SMTP.Host,
+		Port:     cfg.Mail.SMTP.Port,
+		Username: cfg.Mail.SMTP.Username,
+		Password: cfg.Mail.SMTP.Password,
+		From:     cfg.Mail.From,
+	})
+	svc := services.New(repos, mail, cfg.AppURL)
+	go svc.StartRevocationCacheSweep(stopped)
+	health := controllers.NewHealth(
+		controllers.Check{Name: "mongo", Run: func(ctx context.Context) error { return client.Ping(ctx, readpref.Primary()) }},
+		controllers.Check{Name: "signing_keys", Run: func(ctx context.Context) error { return tokens.Ready() }},
+		controllers.Check{Name: "migrations", Run: runner.Current},
+	)
+	router, err := newRouter(cfg, svc, health)
+	if err != nil {
+		return err
+	}
+
+	server := &http.Server{Addr: ":" + strconv.Itoa(cfg.Server.Port), Handler: router}
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serveErr:
+		return err
+	case <-stopped.Done():
+	}
+
+	log.Println("Shutting down, waiting for in-flight requests")
+	ctx, cancel = context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout.Duration)
+	defer cancel()
+	return server.Shutdown(ctx)
+}
+
+// newRouter wires every route of the API to the service, the integration tests serve it with httptest
+func newRouter(cfg config.Config, svc *services.Service, health *controllers.Health) (*gin.Engine, error) {
+	ctl := controllers.New(svc)
+	auth := middleware.Authtication(svc)
 
 	router := gin.Default()
+	// gin believes X-Forwarded-For from anyone by default, which would let clients pick the address they are throttled by
+	if err := router.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		return nil, err
+	}
 	// CORS
 	router.Use(cors.New(cors.Config{
-		AllowOrigins:     []string{"https://culinary-bliss.onrender.com", "http://localhost:3000", "http://localhost:8080"},
+		AllowOrigins:     cfg.Cors.Origins,
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", "Access-Control-Allow-Origin", "Access-Control-Allow-Headers"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", "Access-Control-Allow-Origin", "Access-Control-Allow-Headers", "X-API-Key"},
 		ExposeHeaders:    []string{"Content-Length"},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
 	}))
 	router.Use(gin.Logger())
 	router.Use(gin.Recovery())
+	router.Use(middleware.Problems())
 
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
@@ -42,24 +159,29 @@ func main() {
 	docs.SwaggerInfo.Title = "Culinary Bliss API"
 	docs.SwaggerInfo.Description = "Culinary Bliss restaurant management app designed to streamline your operations and elevate your dining experience and for efficient, effective, and effortless restaurant management."
 	docs.SwaggerInfo.Version = "1.0"
-	docs.SwaggerInfo.Schemes = []string{"https"}
-	docs.SwaggerInfo.Host = "culinary-bliss.onrender.com"
-	//docs.SwaggerInfo.Host = "localhost:8080"
-
-	routes.AuthRoutes(router)
-	routes.GlobalRoutes(router)
-	router.Use(middleware.Authtication)
-
-	routes.UserRoutes(router)
-	routes.FoodRoutes(router)
-	routes.MenuRoutes(router)
-	routes.InvoiceRoutes(router)
-	routes.TableRoutes(router)
-	routes.OrderRoutes(router)
-	routes.OrderItemRoutes(router)
-	routes.RestaurantRoutes(router)
-	routes.CatgeoryRoutes(router)
-
-	router.Run(":" + port)
+	docs.SwaggerInfo.Schemes = cfg.Swagger.Schemes
+	docs.SwaggerInfo.Host = cfg.Swagger.Host
+
+	routes.AuthRoutes(router, ctl, auth)
+	routes.GlobalRoutes(router, ctl)
+	routes.HealthRoutes(router, health)
+	router.Use(auth)
+
+	// API keys are scoped to a restaurant, the routes after these are not and only let users in
+	routes.RestaurantRoutes(router, ctl)
+	routes.OrderRoutes(router, ctl)
+	routes.OrderItemRoutes(router, ctl)
+	router.Use(middleware.RefuseApiKeys())
+
+	routes.UserRoutes(router, ctl)
+	routes.FoodRoutes(router, ctl)
+	routes.MenuRoutes(router, ctl)
+	routes.InvoiceRoutes(router, ctl)
+	routes.TableRoutes(router, ctl)
+	routes.CatgeoryRoutes(router, ctl)
+	routes.RoleRoutes(router, ctl)
+	routes.MfaRoutes(router, ctl)
+	routes.ApiKeyRoutes(router, ctl)
 
+	return router, nil
 }
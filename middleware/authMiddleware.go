@@ -1,23 +1,60 @@
 package middleware
 
 import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/ShahSau/culinary-bliss/apperrors"
 	"github.com/ShahSau/culinary-bliss/helpers"
+	"github.com/ShahSau/culinary-bliss/models"
+	"github.com/ShahSau/culinary-bliss/tokens"
 	"github.com/gin-gonic/gin"
 )
 
-// AuthMiddleware is a middleware that checks if the user is authenticated
-func Authtication(c *gin.Context) {
-	clientToken := c.Request.Header.Get("Authorization")
+// Authenticator answers the questions the middleware cannot answer from the request alone
+type Authenticator interface {
+	IsTokenRevoked(ctx context.Context, claims *tokens.Claims) (bool, error)
+	AuthenticateApiKey(ctx context.Context, key string) (models.ApiKey, error)
+}
+
+// Authtication returns a middleware that checks if the user is authenticated, either with a JWT or with an X-API-Key
+func Authtication(auth Authenticator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authenticate(c, auth)
+	}
+}
+
+func authenticate(c *gin.Context, auth Authenticator) {
+	if apiKey := c.Request.Header.Get("X-API-Key"); apiKey != "" {
+		authenticateApiKey(c, auth, apiKey)
+		return
+	}
+
+	clientToken := strings.TrimPrefix(c.Request.Header.Get("Authorization"), "Bearer ")
 	if clientToken == "" {
-		c.JSON(403, gin.H{"error": "No Authorization header provided, Please login"})
-		c.Abort()
+		abort(c, apperrors.Unauthorized("No Authorization header provided, Please login"))
 		return
 	}
 
-	claims, err := helpers.ValidateToken(clientToken)
+	claims, err := tokens.Parse(clientToken, tokens.AccessToken)
 	if err != nil {
-		c.JSON(403, gin.H{"error": "Invalid token"})
-		c.Abort()
+		// a genuine token of the wrong kind identifies the user but does not grant access, anything else does not identify anyone
+		if errors.Is(err, tokens.ErrWrongType) {
+			abort(c, apperrors.Forbidden(err.Error()))
+		} else {
+			abort(c, apperrors.Unauthorized(err.Error()))
+		}
+		return
+	}
+
+	revoked, err := auth.IsTokenRevoked(c.Request.Context(), claims)
+	if err != nil {
+		abort(c, apperrors.Internal(err))
+		return
+	}
+	if revoked {
+		abort(c, apperrors.Unauthorized("Token has been revoked, Please login"))
 		return
 	}
 
@@ -25,7 +62,31 @@ func Authtication(c *gin.Context) {
 	c.Set("first_name", claims.First_name)
 	c.Set("last_name", claims.Last_name)
 	c.Set("user_id", claims.User_id)
+	c.Set("role", helpers.NormalizeRole(claims.Role))
+	c.Set("permissions", claims.Permissions)
+	c.Set("jti", claims.ID)
+	c.Set("family_id", claims.Family_id)
+	c.Set("token_expires_at", claims.ExpiresAt.Time)
 
 	c.Next()
 
 }
+
+// authenticateApiKey lets machine clients in. They have no user, role or session, only the permissions and restaurant of their key.
+func authenticateApiKey(c *gin.Context, auth Authenticator, key string) {
+	apiKey, err := auth.AuthenticateApiKey(c.Request.Context(), key)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrUnauthorized) {
+			abort(c, apperrors.Unauthorized("Invalid API key"))
+		} else {
+			abort(c, apperrors.Internal(err))
+		}
+		return
+	}
+
+	c.Set("api_key_id", apiKey.Key_id)
+	c.Set("restaurant_id", apiKey.Restaurant_id)
+	c.Set("permissions", apiKey.Permissions)
+
+	c.Next()
+}
@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"log"
+
+	"github.com/ShahSau/culinary-bliss/apperrors"
+	"github.com/gin-gonic/gin"
+)
+
+// Problems renders the last error a handler recorded with c.Error as an RFC 7807 problem, logging the ones the client does not get to see
+func Problems() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+
+		err := c.Errors.Last().Err
+		problem := apperrors.ProblemFor(err, c.Request.URL.Path)
+		if problem.Status >= 500 {
+			log.Printf("%s %s: %v", c.Request.Method, c.Request.URL.Path, err)
+		}
+		c.Header("Content-Type", apperrors.ContentType)
+		c.JSON(problem.Status, problem)
+	}
+}
+
+// abort stops the request with err, Problems renders it
+func abort(c *gin.Context, err error) {
+	c.Error(err)
+	c.Abort()
+}
@@ -0,0 +1,83 @@
+package middleware
+
+import (
+	"github.com/ShahSau/culinary-bliss/apperrors"
+	"github.com/ShahSau/culinary-bliss/helpers"
+	"github.com/gin-gonic/gin"
+)
+
+// RequireRole only lets the request through if the authenticated user has one of the given roles
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !helpers.HasRole(c.GetString("role"), roles...) {
+			abort(c, apperrors.Forbidden("You are not authorized to access this resource"))
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RequirePermission only lets the request through if the token of the authenticated user grants every given permission
+func RequirePermission(permissions ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		granted := c.GetStringSlice("permissions")
+		for _, permission := range permissions {
+			if !helpers.HasPermission(granted, permission) {
+				abort(c, apperrors.Forbidden("You are not authorized to access this resource"))
+				return
+			}
+		}
+
+		c.Next()
+	}
+}
+
+// RequireSelfOrPermission lets users act on their own account, identified by the given path parameter, and otherwise requires the permission
+func RequireSelfOrPermission(param string, permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Param(param) != c.GetString("user_id") && !helpers.HasPermission(c.GetStringSlice("permissions"), permission) {
+			abort(c, apperrors.Forbidden("You are not authorized to access this resource"))
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RequireSelf only lets users act on their own account, identified by the given path parameter, whatever their permissions
+func RequireSelf(param string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if userID := c.GetString("user_id"); userID == "" || c.Param(param) != userID {
+			abort(c, apperrors.Forbidden("You are not authorized to access this resource"))
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RequireRestaurantScope keeps API keys to the restaurant they were issued for, identified by the given path parameter.
+// Users are not scoped to a restaurant.
+func RequireRestaurantScope(param string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if restaurantID := c.GetString("restaurant_id"); restaurantID != "" && c.Param(param) != restaurantID {
+			abort(c, apperrors.Forbidden("You are not authorized to access this resource"))
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RefuseApiKeys keeps API keys out of routes that are not scoped to a restaurant
+func RefuseApiKeys() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString("api_key_id") != "" {
+			abort(c, apperrors.Forbidden("API keys can only access the routes of their restaurant"))
+			return
+		}
+
+		c.Next()
+	}
+}
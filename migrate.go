@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ShahSau/culinary-bliss/config"
+	"github.com/ShahSau/culinary-bliss/database"
+	"github.com/ShahSau/culinary-bliss/migrations"
+	"github.com/ShahSau/culinary-bliss/money"
+)
+
+const migrateUsage = `usage: culinary-bliss migrate [up|down|status] [flags]
+
+  up      apply every pending migration (default)
+  down    roll back the most recent migrations
+  status  list the migrations and whether they are applied
+
+`
+
+// runMigrate is the migrate subcommand, it changes the schema without starting the server
+func runMigrate(args []string) error {
+	command := "up"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		command, args = args[0], args[1:]
+	}
+
+	flags := flag.NewFlagSet("migrate", flag.ExitOnError)
+	dryRun := flags.Bool("dry-run", false, "print the migrations that would run without running them")
+	steps := flags.Int("steps", 1, "how many migrations down rolls back")
+	flags.Usage = func() {
+		fmt.Fprint(flags.Output(), migrateUsage)
+		flags.PrintDefaults()
+	}
+	cfg, err := config.Load(flags, args)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	client, err := database.ConnectDB(ctx, cfg.Database.URI)
+	cancel()
+	if err != nil {
+		return err
+	}
+	defer client.Disconnect(context.Background())
+
+	if err := money.Configure(cfg.Currency); err != nil {
+		return err
+	}
+	runner := migrations.New(database.Database(client, cfg.Database.Name))
+	verb := "Applied"
+	if *dryRun {
+		verb = "Would apply"
+	}
+
+	switch command {
+	case "up":
+		applied, err := runner.Up(context.Background(), *dryRun)
+		for _, migration := range applied {
+			fmt.Printf("%s %d %s\n", verb, migration.Version, migration.Name)
+		}
+		if err == nil && len(applied) == 0 {
+			fmt.Println("Nothing to migrate")
+		}
+		return err
+	case "down":
+		if *steps < 1 {
+			return errors.New("steps must be at least 1")
+		}
+		verb = "Rolled back"
+		if *dryRun {
+			verb = "Would roll back"
+		}
+		rolledBack, err := runner.Down(context.Background(), *steps, *dryRun)
+		for _, migration := range rolledBack {
+			fmt.Printf("%s %d %s\n", verb, migration.Version, migration.Name)
+		}
+		if err == nil && len(rolledBack) == 0 {
+			fmt.Println("Nothing to roll back")
+		}
+		return err
+	case "status":
+		statuses, err := runner.Status(context.Background())
+		if err != nil {
+			return err
+		}
+		for _, status := range statuses {
+			applied := "pending"
+			if status.AppliedAt != nil {
+				applied = "applied " + status.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Printf("%d %s %s\n", status.Migration.Version, status.Migration.Name, applied)
+		}
+		return nil
+	default:
+		flags.Usage()
+		return fmt.Errorf("unknown migrate command %q", command)
+	}
+}
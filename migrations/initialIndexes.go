@@ -0,0 +1,98 @@
+package migrations
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// collectionIndexes are indexes a migration creates on one collection
+type collectionIndexes struct {
+	collection string
+	indexes    []mongo.IndexModel
+}
+
+// initialIndexes are the unique and lookup indexes of every collection the services use
+var initialIndexes = []collectionIndexes{
+	{"users", []mongo.IndexModel{unique("user_id"), unique("email"), unique("phone")}},
+	{"roles", []mongo.IndexModel{unique("name")}},
+	{"token_families", []mongo.IndexModel{unique("family_id"), lookup("user_id", "revoked")}},
+	{"revoked_tokens", []mongo.IndexModel{unique("key"), expiring("expires_at")}},
+	{"password_resets", []mongo.IndexModel{unique("token_hash"), lookup("user_id"), expiring("expires_at")}},
+	{"email_verifications", []mongo.IndexModel{unique("token_hash"), lookup("user_id"), expiring("expires_at")}},
+	{"login_attempts", []mongo.IndexModel{unique("key"), expiring("expires_at")}},
+	{"audit_events", []mongo.IndexModel{lookup("user_id"), lookup("created_at")}},
+	{"api_keys", []mongo.IndexModel{unique("key_id"), unique("key_hash")}},
+	{"restaurants", []mongo.IndexModel{unique("restaurant_id")}},
+	{"menu", []mongo.IndexModel{unique("menu_id")}},
+	{"food", []mongo.IndexModel{unique("food_id"), lookup("menu_id")}},
+	{"categories", []mongo.IndexModel{unique("category_id")}},
+	{"tables", []mongo.IndexModel{unique("table_id")}},
+	{"orders", []mongo.IndexModel{unique("order_id"), lookup("table_id")}},
+	{"order_items", []mongo.IndexModel{unique("order_item_id"), lookup("order_id"), lookup("food_id")}},
+	{"invoice", []mongo.IndexModel{unique("invoice_id"), lookup("order_id")}},
+}
+
+// createIndexes returns the Up of a migration that creates the given indexes
+func createIndexes(set []collectionIndexes) func(ctx context.Context, db *mongo.Database) error {
+	return func(ctx context.Context, db *mongo.Database) error {
+		for _, c := range set {
+			if _, err := db.Collection(c.collection).Indexes().CreateMany(ctx, c.indexes); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// dropIndexes returns the Down of a migration created with createIndexes
+func dropIndexes(set []collectionIndexes) func(ctx context.Context, db *mongo.Database) error {
+	return func(ctx context.Context, db *mongo.Database) error {
+		for _, c := range set {
+			for _, index := range c.indexes {
+				_, err := db.Collection(c.collection).Indexes().DropOne(ctx, *index.Options.Name)
+				if err != nil && !isMissing(err) {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+}
+
+func unique(field string) mongo.IndexModel {
+	return index(options.Index().SetUnique(true), field)
+}
+
+func lookup(fields ...string) mongo.IndexModel {
+	return index(options.Index(), fields...)
+}
+
+// expiring lets Mongo delete documents once the time in field has passed
+func expiring(field string) mongo.IndexModel {
+	return index(options.Index().SetExpireAfterSeconds(0), field)
+}
+
+// index names the index the way Mongo would, so indexes created before migrations existed are recognised
+func index(opts *options.IndexOptions, fields ...string) mongo.IndexModel {
+	var keys bson.D
+	var name []string
+	for _, field := range fields {
+		keys = append(keys, bson.E{Key: field, Value: 1})
+		name = append(name, field+"_1")
+	}
+	return mongo.IndexModel{Keys: keys, Options: opts.SetName(strings.Join(name, "_"))}
+}
+
+// isMissing reports whether a drop failed only because the index or collection is already gone
+func isMissing(err error) bool {
+	var commandErr mongo.CommandError
+	if errors.As(err, &commandErr) {
+		return commandErr.Code == 26 || commandErr.Code == 27
+	}
+	return false
+}
@@ -0,0 +1,18 @@
+package migrations
+
+import "go.mongodb.org/mongo-driver/mongo"
+
+// listingIndexes serve the default sort of every list, ending with the id that breaks ties the way the cursor does.
+// Mongo walks them backwards for descending sorts.
+var listingIndexes = []collectionIndexes{
+	{"users", []mongo.IndexModel{lookup("created_at", "user_id")}},
+	{"api_keys", []mongo.IndexModel{lookup("created_at", "key_id")}},
+	{"restaurants", []mongo.IndexModel{lookup("created_at", "restaurant_id")}},
+	{"menu", []mongo.IndexModel{lookup("created_at", "menu_id")}},
+	{"food", []mongo.IndexModel{lookup("created_at", "food_id")}},
+	{"categories", []mongo.IndexModel{lookup("title", "category_id")}},
+	{"tables", []mongo.IndexModel{lookup("table_number", "table_id")}},
+	{"orders", []mongo.IndexModel{lookup("order_date", "order_id")}},
+	{"order_items", []mongo.IndexModel{lookup("created_at", "order_item_id")}},
+	{"invoice", []mongo.IndexModel{lookup("created_at", "invoice_id")}},
+}
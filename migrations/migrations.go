@@ -0,0 +1,220 @@
+// Package migrations brings the database schema up to date with ordered, versioned migrations
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Migration changes the schema from one version to the next. Down undoes exactly what Up did.
+type Migration struct {
+	Version int
+	Name    string
+	Up      func(ctx context.Context, db *mongo.Database) error
+	Down    func(ctx context.Context, db *mongo.Database) error
+}
+
+// All lists every migration in the order they are applied. New migrations go at the end with the next version.
+var All = []Migration{
+	{Version: 1, Name: "initial_indexes", Up: createIndexes(initialIndexes), Down: dropIndexes(initialIndexes)},
+	{Version: 2, Name: "listing_indexes", Up: createIndexes(listingIndexes), Down: dropIndexes(listingIndexes)},
+	{Version: 3, Name: "order_item_quantities", Up: splitQuantities, Down: joinQuantities},
+	{Version: 4, Name: "money_amounts", Up: toMoney, Down: fromMoney},
+	{Version: 5, Name: "unset_user_tokens", Up: unsetUserTokens, Down: keepUserTokensUnset},
+	{Version: 6, Name: "role_permissions", Up: grantAddedPermissions, Down: revokeAddedPermissions},
+	{Version: 7, Name: "order_statuses", Up: mapOrderStatuses, Down: restoreOrderStatuses},
+}
+
+// Record is kept in the schema_migrations collection for every applied migration
+type Record struct {
+	Version   int       `bson:"version"`
+	Name      string    `bson:"name"`
+	AppliedAt time.Time `bson:"applied_at"`
+}
+
+// Store remembers which migrations have been applied
+type Store interface {
+	Applied(ctx context.Context) ([]Record, error)
+	Add(ctx context.Context, record Record) error
+	Remove(ctx context.Context, version int) error
+}
+
+// Status is a migration together with when it was applied, if it was
+type Status struct {
+	Migration Migration
+	AppliedAt *time.Time
+}
+
+// Runner applies and rolls back migrations against a database
+type Runner struct {
+	db         *mongo.Database
+	store      Store
+	migrations []Migration
+}
+
+// New returns a runner for All that records its progress in the schema_migrations collection of db
+func New(db *mongo.Database) *Runner {
+	return &Runner{db: db, store: &mongoStore{collection: db.Collection("schema_migrations")}, migrations: All}
+}
+
+// Up applies every pending migration in order and returns them. With dryRun nothing is changed.
+func (r *Runner) Up(ctx context.Context, dryRun bool) ([]Migration, error) {
+	applied, err := r.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for _, migration := range r.migrations {
+		if _, ok := applied[migration.Version]; ok {
+			continue
+		}
+		if !dryRun {
+			if err := migration.Up(ctx, r.db); err != nil {
+				return done, fmt.Errorf("migration %d %s: %w", migration.Version, migration.Name, err)
+			}
+			now, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+			if err := r.store.Add(ctx, Record{Version: migration.Version, Name: migration.Name, AppliedAt: now}); err != nil {
+				return done, err
+			}
+		}
+		done = append(done, migration)
+	}
+
+	return done, nil
+}
+
+// Down rolls back the last steps applied migrations, newest first, and returns them. With dryRun nothing is changed.
+func (r *Runner) Down(ctx context.Context, steps int, dryRun bool) ([]Migration, error) {
+	applied, err := r.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var versions []int
+	for version := range applied {
+		versions = append(versions, version)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(versions)))
+
+	var done []Migration
+	for _, version := range versions {
+		if len(done) == steps {
+			break
+		}
+		migration, ok := r.find(version)
+		if !ok {
+			return done, fmt.Errorf("migration %d is applied but not known to this build", version)
+		}
+		if !dryRun {
+			if err := migration.Down(ctx, r.db); err != nil {
+				return done, fmt.Errorf("migration %d %s: %w", migration.Version, migration.Name, err)
+			}
+			if err := r.store.Remove(ctx, migration.Version); err != nil {
+				return done, err
+			}
+		}
+		done = append(done, migration)
+	}
+
+	return done, nil
+}
+
+// Status lists every known migration and when it was applied
+func (r *Runner) Status(ctx context.Context) ([]Status, error) {
+	applied, err := r.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var statuses []Status
+	for _, migration := range r.migrations {
+		status := Status{Migration: migration}
+		if record, ok := applied[migration.Version]; ok {
+			status.AppliedAt = &record.AppliedAt
+		}
+		statuses = append(statuses, status)
+	}
+
+	return statuses, nil
+}
+
+// Current fails while a known migration has not been applied yet
+func (r *Runner) Current(ctx context.Context) error {
+	applied, err := r.applied(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, migration := range r.migrations {
+		if _, ok := applied[migration.Version]; !ok {
+			return fmt.Errorf("migration %d %s is pending", migration.Version, migration.Name)
+		}
+	}
+
+	return nil
+}
+
+// applied returns the applied migrations by version, after checking the known ones are in order
+func (r *Runner) applied(ctx context.Context) (map[int]Record, error) {
+	for i := 1; i < len(r.migrations); i++ {
+		if r.migrations[i].Version <= r.migrations[i-1].Version {
+			return nil, fmt.Errorf("migration %d %s is out of order", r.migrations[i].Version, r.migrations[i].Name)
+		}
+	}
+
+	records, err := r.store.Applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	applied := map[int]Record{}
+	for _, record := range records {
+		applied[record.Version] = record
+	}
+	return applied, nil
+}
+
+func (r *Runner) find(version int) (Migration, bool) {
+	for _, migration := range r.migrations {
+		if migration.Version == version {
+			return migration, true
+		}
+	}
+	return Migration{}, false
+}
+
+type mongoStore struct {
+	collection *mongo.Collection
+}
+
+func (s *mongoStore) Applied(ctx context.Context) ([]Record, error) {
+	cursor, err := s.collection.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "version", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+
+	records := []Record{}
+	if err = cursor.All(ctx, &records); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// Add is an upsert so two instances applying the same migration at once leave a single record
+func (s *mongoStore) Add(ctx context.Context, record Record) error {
+	update := bson.D{{Key: "$setOnInsert", Value: record}}
+	_, err := s.collection.UpdateOne(ctx, bson.M{"version": record.Version}, update, options.Update().SetUpsert(true))
+	return err
+}
+
+func (s *mongoStore) Remove(ctx context.Context, version int) error {
+	_, err := s.collection.DeleteOne(ctx, bson.M{"version": version})
+	return err
+}
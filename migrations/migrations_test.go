@@ -0,0 +1,141 @@
+package migrations
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type memoryStore struct {
+	records []Record
+}
+
+func (s *memoryStore) Applied(ctx context.Context) ([]Record, error) {
+	return s.records, nil
+}
+
+func (s *memoryStore) Add(ctx context.Context, record Record) error {
+	s.records = append(s.records, record)
+	return nil
+}
+
+func (s *memoryStore) Remove(ctx context.Context, version int) error {
+	kept := s.records[:0]
+	for _, record := range s.records {
+		if record.Version != version {
+			kept = append(kept, record)
+		}
+	}
+	s.records = kept
+	return nil
+}
+
+// newTestRunner returns a runner over migrations that only log the order they ran in
+func newTestRunner(versions ...int) (*Runner, *[]int) {
+	var ran []int
+	var migrations []Migration
+	for _, version := range versions {
+		version := version
+		migrations = append(migrations, Migration{
+			Version: version,
+			Name:    "test",
+			Up:      func(ctx context.Context, db *mongo.Database) error { ran = append(ran, version); return nil },
+			Down:    func(ctx context.Context, db *mongo.Database) error { ran = append(ran, -version); return nil },
+		})
+	}
+	return &Runner{store: &memoryStore{}, migrations: migrations}, &ran
+}
+
+func TestUpAppliesPendingMigrationsInOrder(t *testing.T) {
+	runner, ran := newTestRunner(1, 2, 3)
+	runner.store.Add(context.Background(), Record{Version: 1})
+
+	applied, err := runner.Up(context.Background(), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(applied) != 2 || len(*ran) != 2 || (*ran)[0] != 2 || (*ran)[1] != 3 {
+		t.Fatalf("expected migrations 2 and 3 to run, ran %v", *ran)
+	}
+
+	applied, _ = runner.Up(context.Background(), false)
+	if len(applied) != 0 {
+		t.Fatalf("expected nothing left to apply, got %d", len(applied))
+	}
+}
+
+func TestDryRunChangesNothing(t *testing.T) {
+	runner, ran := newTestRunner(1, 2)
+
+	applied, err := runner.Up(context.Background(), true)
+	if err != nil || len(applied) != 2 {
+		t.Fatalf("expected two migrations to be reported, got %d %v", len(applied), err)
+	}
+	if len(*ran) != 0 {
+		t.Fatalf("expected a dry run not to run migrations, ran %v", *ran)
+	}
+
+	statuses, _ := runner.Status(context.Background())
+	for _, status := range statuses {
+		if status.AppliedAt != nil {
+			t.Fatalf("expected migration %d to still be pending", status.Migration.Version)
+		}
+	}
+}
+
+func TestCurrentFailsUntilEveryMigrationIsApplied(t *testing.T) {
+	runner, _ := newTestRunner(1, 2)
+	runner.store.Add(context.Background(), Record{Version: 1})
+
+	if err := runner.Current(context.Background()); err == nil {
+		t.Fatal("expected migration 2 to be reported pending")
+	}
+	runner.Up(context.Background(), false)
+	if err := runner.Current(context.Background()); err != nil {
+		t.Fatalf("expected the schema to be current, got %v", err)
+	}
+}
+
+func TestDownRollsBackNewestFirst(t *testing.T) {
+	runner, ran := newTestRunner(1, 2, 3)
+	runner.Up(context.Background(), false)
+	*ran = nil
+
+	rolledBack, err := runner.Down(context.Background(), 2, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rolledBack) != 2 || (*ran)[0] != -3 || (*ran)[1] != -2 {
+		t.Fatalf("expected 3 then 2 to be rolled back, ran %v", *ran)
+	}
+
+	statuses, _ := runner.Status(context.Background())
+	if statuses[0].AppliedAt == nil || statuses[1].AppliedAt != nil || statuses[2].AppliedAt != nil {
+		t.Fatalf("expected only migration 1 to stay applied")
+	}
+}
+
+func TestOutOfOrderMigrationsAreRejected(t *testing.T) {
+	runner, _ := newTestRunner(2, 1)
+
+	if _, err := runner.Up(context.Background(), false); err == nil {
+		t.Fatal("expected an error for migrations out of order")
+	}
+}
+
+func TestFailedMigrationIsNotRecorded(t *testing.T) {
+	runner, _ := newTestRunner(1, 2)
+	runner.migrations[1].Up = func(ctx context.Context, db *mongo.Database) error { return errors.New("boom") }
+
+	applied, err := runner.Up(context.Background(), false)
+	if err == nil || len(applied) != 1 {
+		t.Fatalf("expected migration 1 to apply and 2 to fail, got %d %v", len(applied), err)
+	}
+
+	records, _ := runner.store.Applied(context.Background())
+	if len(records) != 1 || records[0].Version != 1 {
+		t.Fatalf("expected only migration 1 to be recorded, got %+v", records)
+	}
+}
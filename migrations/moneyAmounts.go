@@ -0,0 +1,94 @@
+package migrations
+
+import (
+	"context"
+
+	"github.com/ShahSau/culinary-bliss/money"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// amountField held a price as a floating point number of major units.
+// When modifiers is set it is the price of every modifier in that array instead.
+type amountField struct {
+	collection string
+	field      string
+	modifiers  string
+}
+
+var amountFields = []amountField{
+	{collection: "food", field: "price"},
+	{collection: "food", modifiers: "modifiers"},
+	{collection: "orders", field: "total_amount"},
+	{collection: "order_items", field: "unit_price"},
+	{collection: "order_items", field: "total_amount"},
+	{collection: "order_items", modifiers: "modifiers"},
+	{collection: "invoice", field: "total_amount"},
+}
+
+// conversion rewrites amount fields stored one way into the other
+type conversion struct {
+	// filter matches the documents with a field still stored the old way
+	filter bson.M
+	// is tells in an aggregation expression whether value is stored the old way
+	is func(value string) bson.M
+	// convert is the aggregation expression of the new value
+	convert func(value string) bson.M
+}
+
+// toMoney turns the stored numbers into integer minor units of the configured currency
+func toMoney(ctx context.Context, db *mongo.Database) error {
+	currency := money.Default()
+	return convertAmounts(ctx, db, conversion{
+		filter: bson.M{"$type": "number"},
+		is:     func(value string) bson.M { return bson.M{"$isNumber": value} },
+		convert: func(value string) bson.M {
+			// rounding to a few places first keeps 4.175 stored as 4.17499… from rounding down
+			minor := bson.M{"$round": bson.A{bson.M{"$multiply": bson.A{value, money.Scale(currency)}}, 6}}
+			return bson.M{
+				"amount":   bson.M{"$toLong": bson.M{"$floor": bson.M{"$add": bson.A{minor, 0.5}}}},
+				"currency": currency,
+			}
+		},
+	})
+}
+
+// fromMoney turns the amounts back into numbers of major units of the configured currency
+func fromMoney(ctx context.Context, db *mongo.Database) error {
+	scale := money.Scale(money.Default())
+	return convertAmounts(ctx, db, conversion{
+		filter:  bson.M{"$type": "object"},
+		is:      func(value string) bson.M { return bson.M{"$eq": bson.A{bson.M{"$type": value}, "object"}} },
+		convert: func(value string) bson.M { return bson.M{"$divide": bson.A{value + ".amount", scale}} },
+	})
+}
+
+// convertAmounts applies the conversion to every amount field
+func convertAmounts(ctx context.Context, db *mongo.Database, c conversion) error {
+	for _, f := range amountFields {
+		if f.modifiers == "" {
+			_, err := db.Collection(f.collection).UpdateMany(ctx,
+				bson.M{f.field: c.filter},
+				bson.A{bson.M{"$set": bson.M{f.field: c.convert("$" + f.field)}}},
+			)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		price := bson.M{"$cond": bson.A{c.is("$$modifier.price"), c.convert("$$modifier.price"), "$$modifier.price"}}
+		modifiers := bson.M{"$map": bson.M{
+			"input": "$" + f.modifiers,
+			"as":    "modifier",
+			"in":    bson.M{"$mergeObjects": bson.A{"$$modifier", bson.M{"price": price}}},
+		}}
+		_, err := db.Collection(f.collection).UpdateMany(ctx,
+			bson.M{f.modifiers + ".price": c.filter},
+			bson.A{bson.M{"$set": bson.M{f.modifiers: modifiers}}},
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,26 @@
+package migrations
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// splitQuantities turns the portion size order items kept in quantity into a portion of one
+func splitQuantities(ctx context.Context, db *mongo.Database) error {
+	_, err := db.Collection("order_items").UpdateMany(ctx,
+		bson.M{"quantity": bson.M{"$type": "string"}},
+		bson.A{bson.M{"$set": bson.M{"portion": "$quantity", "quantity": 1, "unit_price": "$total_amount", "modifiers": bson.A{}}}},
+	)
+	return err
+}
+
+// joinQuantities puts the portion back into quantity. How many portions an item was is lost.
+func joinQuantities(ctx context.Context, db *mongo.Database) error {
+	_, err := db.Collection("order_items").UpdateMany(ctx,
+		bson.M{"portion": bson.M{"$exists": true}},
+		bson.A{bson.M{"$set": bson.M{"quantity": "$portion"}}, bson.M{"$unset": bson.A{"portion", "unit_price", "modifiers"}}},
+	)
+	return err
+}
@@ -0,0 +1,54 @@
+package migrations
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// orderStatuses are the statuses of the order state machine
+var orderStatuses = bson.A{"PLACED", "ACCEPTED", "PREPARING", "READY", "SERVED", "PAID", "CANCELLED", "REFUNDED"}
+
+// legacyOrderStatuses maps the free text statuses orders used to be stored with, lowercased, onto the state machine.
+// Anything else becomes PLACED so the order can still be moved on or cancelled.
+var legacyOrderStatuses = []struct {
+	status string
+	texts  bson.A
+}{
+	{status: "ACCEPTED", texts: bson.A{"accepted", "confirmed"}},
+	{status: "PREPARING", texts: bson.A{"preparing", "in progress", "in_progress", "cooking"}},
+	{status: "READY", texts: bson.A{"ready"}},
+	{status: "SERVED", texts: bson.A{"served", "delivered"}},
+	{status: "PAID", texts: bson.A{"paid", "completed", "complete", "done", "closed"}},
+	{status: "CANCELLED", texts: bson.A{"cancelled", "canceled"}},
+	{status: "REFUNDED", texts: bson.A{"refunded"}},
+}
+
+// mapOrderStatuses moves orders with a legacy status onto the state machine and keeps the old text in legacy_order_status
+func mapOrderStatuses(ctx context.Context, db *mongo.Database) error {
+	text := bson.M{"$toLower": bson.M{"$trim": bson.M{"input": bson.M{"$ifNull": bson.A{"$order_status", ""}}}}}
+
+	var branches bson.A
+	for _, legacy := range legacyOrderStatuses {
+		branches = append(branches, bson.M{"case": bson.M{"$in": bson.A{text, legacy.texts}}, "then": legacy.status})
+	}
+
+	_, err := db.Collection("orders").UpdateMany(ctx,
+		bson.M{"order_status": bson.M{"$nin": orderStatuses}},
+		bson.A{bson.M{"$set": bson.M{
+			"legacy_order_status": bson.M{"$ifNull": bson.A{"$order_status", ""}},
+			"order_status":        bson.M{"$switch": bson.M{"branches": branches, "default": "PLACED"}},
+		}}},
+	)
+	return err
+}
+
+// restoreOrderStatuses puts the legacy text back on the orders that had one
+func restoreOrderStatuses(ctx context.Context, db *mongo.Database) error {
+	_, err := db.Collection("orders").UpdateMany(ctx,
+		bson.M{"legacy_order_status": bson.M{"$exists": true}},
+		bson.A{bson.M{"$set": bson.M{"order_status": "$legacy_order_status"}}, bson.M{"$unset": "legacy_order_status"}},
+	)
+	return err
+}
@@ -0,0 +1,42 @@
+package migrations
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// addedRolePermissions are the permissions introduced after roles could be stored, by the roles that get them by default.
+// Stored roles replace the default matrix, so without this they would never be granted.
+var addedRolePermissions = map[string][]string{
+	"Admin": {"users:write", "sessions:revoke", "api_keys:manage"},
+}
+
+// grantAddedPermissions adds the new default permissions to the roles stored in the roles collection
+func grantAddedPermissions(ctx context.Context, db *mongo.Database) error {
+	for role, permissions := range addedRolePermissions {
+		_, err := db.Collection("roles").UpdateMany(ctx,
+			bson.M{"name": role},
+			bson.M{"$addToSet": bson.M{"permissions": bson.M{"$each": permissions}}},
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// revokeAddedPermissions takes the new permissions away again, no stored role could have held them before
+func revokeAddedPermissions(ctx context.Context, db *mongo.Database) error {
+	for role, permissions := range addedRolePermissions {
+		_, err := db.Collection("roles").UpdateMany(ctx,
+			bson.M{"name": role},
+			bson.M{"$pull": bson.M{"permissions": bson.M{"$in": permissions}}},
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
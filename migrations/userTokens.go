@@ -0,0 +1,22 @@
+package migrations
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// unsetUserTokens removes the copies of the last issued tokens users used to keep, the token families hold hashes instead
+func unsetUserTokens(ctx context.Context, db *mongo.Database) error {
+	_, err := db.Collection("users").UpdateMany(ctx,
+		bson.M{"$or": bson.A{bson.M{"token": bson.M{"$exists": true}}, bson.M{"refresh_token": bson.M{"$exists": true}}}},
+		bson.M{"$unset": bson.M{"token": "", "refresh_token": ""}},
+	)
+	return err
+}
+
+// keepUserTokensUnset has nothing to bring back, the removed tokens were live credentials
+func keepUserTokensUnset(ctx context.Context, db *mongo.Database) error {
+	return nil
+}
@@ -0,0 +1,178 @@
+//go:build integration
+
+package main
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/ShahSau/culinary-bliss/migrations"
+	"github.com/ShahSau/culinary-bliss/models"
+	"github.com/ShahSau/culinary-bliss/money"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestMoneyAmountsMigration(t *testing.T) {
+	ctx := context.Background()
+	db := client.Database("it_" + primitive.NewObjectID().Hex())
+	t.Cleanup(func() { db.Drop(context.Background()) })
+
+	runner := migrations.New(db)
+	if _, err := runner.Up(ctx, false); err != nil {
+		t.Fatal(err)
+	}
+	// back to the schema that kept amounts as numbers, which migration 4 changed
+	numbers := len(migrations.All) - 3
+	if _, err := runner.Down(ctx, numbers, false); err != nil {
+		t.Fatal(err)
+	}
+
+	legacy := bson.M{"food_id": "f1", "price": 4.175, "modifiers": bson.A{bson.M{"name": "Bread", "price": 0.5}}}
+	if _, err := db.Collection("food").InsertOne(ctx, legacy); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Collection("orders").InsertOne(ctx, bson.M{"order_id": "o1", "total_amount": int32(24)}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := runner.Up(ctx, false); err != nil {
+		t.Fatal(err)
+	}
+	var food models.Food
+	if err := db.Collection("food").FindOne(ctx, bson.M{"food_id": "f1"}).Decode(&food); err != nil {
+		t.Fatal(err)
+	}
+	if food.Price != money.New(418, "USD") || len(food.Modifiers) != 1 || food.Modifiers[0].Price != money.New(50, "USD") {
+		t.Fatalf("unexpected amounts %+v", food)
+	}
+	var order models.Order
+	if err := db.Collection("orders").FindOne(ctx, bson.M{"order_id": "o1"}).Decode(&order); err != nil {
+		t.Fatal(err)
+	}
+	if order.Total_amount != money.New(2400, "USD") {
+		t.Fatalf("unexpected total %+v", order.Total_amount)
+	}
+
+	// the rollback turns them back into numbers
+	if _, err := runner.Down(ctx, numbers, false); err != nil {
+		t.Fatal(err)
+	}
+	var raw bson.M
+	if err := db.Collection("food").FindOne(ctx, bson.M{"food_id": "f1"}).Decode(&raw); err != nil {
+		t.Fatal(err)
+	}
+	if raw["price"] != 4.18 {
+		t.Fatalf("expected the price as a number again, got %v", raw["price"])
+	}
+}
+
+func TestUnsetUserTokensMigration(t *testing.T) {
+	ctx := context.Background()
+	db := client.Database("it_" + primitive.NewObjectID().Hex())
+	t.Cleanup(func() { db.Drop(context.Background()) })
+
+	runner := migrations.New(db)
+	if _, err := runner.Up(ctx, false); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := runner.Down(ctx, len(migrations.All)-4, false); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Collection("users").InsertOne(ctx, bson.M{"user_id": "u1", "token": "access", "refresh_token": "refresh"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := runner.Up(ctx, false); err != nil {
+		t.Fatal(err)
+	}
+	var raw bson.M
+	if err := db.Collection("users").FindOne(ctx, bson.M{"user_id": "u1"}).Decode(&raw); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := raw["token"]; ok {
+		t.Fatalf("expected the tokens to be gone, got %v", raw)
+	}
+	if _, ok := raw["refresh_token"]; ok {
+		t.Fatalf("expected the tokens to be gone, got %v", raw)
+	}
+}
+
+func TestRolePermissionsMigration(t *testing.T) {
+	ctx := context.Background()
+	db := client.Database("it_" + primitive.NewObjectID().Hex())
+	t.Cleanup(func() { db.Drop(context.Background()) })
+
+	runner := migrations.New(db)
+	if _, err := runner.Up(ctx, false); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := runner.Down(ctx, len(migrations.All)-5, false); err != nil {
+		t.Fatal(err)
+	}
+	stored := bson.M{"name": "Admin", "permissions": bson.A{"roles:manage", "users:read", "users:delete"}, "mfa_required": true}
+	if _, err := db.Collection("roles").InsertOne(ctx, stored); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := runner.Up(ctx, false); err != nil {
+		t.Fatal(err)
+	}
+	var role models.Role
+	if err := db.Collection("roles").FindOne(ctx, bson.M{"name": "Admin"}).Decode(&role); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"roles:manage", "users:read", "users:delete", "users:write", "sessions:revoke", "api_keys:manage"}
+	if !reflect.DeepEqual(role.Permissions, want) {
+		t.Fatalf("expected %v, got %v", want, role.Permissions)
+	}
+}
+
+func TestOrderStatusesMigration(t *testing.T) {
+	ctx := context.Background()
+	db := client.Database("it_" + primitive.NewObjectID().Hex())
+	t.Cleanup(func() { db.Drop(context.Background()) })
+
+	runner := migrations.New(db)
+	if _, err := runner.Up(ctx, false); err != nil {
+		t.Fatal(err)
+	}
+	// back to the free text statuses, which migration 7 mapped
+	statuses := len(migrations.All) - 6
+	if _, err := runner.Down(ctx, statuses, false); err != nil {
+		t.Fatal(err)
+	}
+	legacy := map[string]string{"o1": "Pending", "o2": " delivered ", "o3": "Completed", "o4": "canceled", "o5": "something else", "o6": "READY"}
+	for orderID, status := range legacy {
+		if _, err := db.Collection("orders").InsertOne(ctx, bson.M{"order_id": orderID, "order_status": status}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := runner.Up(ctx, false); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"o1": "PLACED", "o2": "SERVED", "o3": "PAID", "o4": "CANCELLED", "o5": "PLACED", "o6": "READY"}
+	for orderID, status := range want {
+		var order models.Order
+		if err := db.Collection("orders").FindOne(ctx, bson.M{"order_id": orderID}).Decode(&order); err != nil {
+			t.Fatal(err)
+		}
+		if order.Order_status != status {
+			t.Errorf("%s: expected %s, got %s", orderID, status, order.Order_status)
+		}
+	}
+
+	// the rollback brings the old text back
+	if _, err := runner.Down(ctx, statuses, false); err != nil {
+		t.Fatal(err)
+	}
+	var raw bson.M
+	if err := db.Collection("orders").FindOne(ctx, bson.M{"order_id": "o2"}).Decode(&raw); err != nil {
+		t.Fatal(err)
+	}
+	if raw["order_status"] != " delivered " || raw["legacy_order_status"] != nil {
+		t.Fatalf("expected the legacy status back, got %v", raw)
+	}
+}
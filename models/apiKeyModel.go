@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ApiKey lets a machine client such as a POS terminal or kitchen printer call the API without a user account
+type ApiKey struct {
+	ID            primitive.ObjectID `json:"_id,omitempty" bson:"_id,omitempty"`
+	Key_id        string             `json:"key_id" bson:"key_id"`
+	Name          string             `json:"name" bson:"name"`
+	Prefix        string             `json:"prefix" bson:"prefix"`
+	Key_hash      string             `json:"-" bson:"key_hash"`
+	Restaurant_id string             `json:"restaurant_id" bson:"restaurant_id"`
+	Permissions   []string           `json:"permissions" bson:"permissions"`
+	Created_by    string             `json:"created_by" bson:"created_by"`
+	Revoked       bool               `json:"revoked" bson:"revoked"`
+	RevokedAt     *time.Time         `json:"revoked_at,omitempty" bson:"revoked_at,omitempty"`
+	LastUsedAt    *time.Time         `json:"last_used_at,omitempty" bson:"last_used_at,omitempty"`
+	CreatedAt     time.Time          `json:"created_at,omitempty" bson:"created_at,omitempty"`
+	UpdatedAt     time.Time          `json:"updated_at,omitempty" bson:"updated_at,omitempty"`
+}
@@ -0,0 +1,18 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type AuditEvent struct {
+	ID         primitive.ObjectID `json:"_id,omitempty" bson:"_id,omitempty"`
+	Event      string             `json:"event" bson:"event"`
+	User_id    string             `json:"user_id,omitempty" bson:"user_id,omitempty"`
+	Email      string             `json:"email,omitempty" bson:"email,omitempty"`
+	Ip_address string             `json:"ip_address,omitempty" bson:"ip_address,omitempty"`
+	Actor_id   string             `json:"actor_id,omitempty" bson:"actor_id,omitempty"`
+	Details    string             `json:"details,omitempty" bson:"details,omitempty"`
+	CreatedAt  time.Time          `json:"created_at" bson:"created_at"`
+}
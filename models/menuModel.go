@@ -16,10 +16,3 @@ type Menu struct {
 	CreatedAt   time.Time          `json:"created_at,omitempty" bson:"created_at,omitempty"`
 	UpdatedAt   time.Time          `json:"updated_at,omitempty" bson:"updated_at,omitempty"`
 }
-
-type ResponseMenu struct {
-	AllMenus      []Menu `json:"all_menus"`
-	Page          int    `json:"page"`
-	RecordPerPage int    `json:"record_per_page"`
-	StartIndex    int    `json:"start_index"`
-}
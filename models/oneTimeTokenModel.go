@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// OneTimeToken backs the single use links emailed to users, such as password resets and email verification
+type OneTimeToken struct {
+	ID         primitive.ObjectID `json:"_id,omitempty" bson:"_id,omitempty"`
+	User_id    string             `json:"user_id" bson:"user_id"`
+	Token_hash string             `json:"-" bson:"token_hash"`
+	// Email is the new address an email change link confirms, it is empty on every other token
+	Email     string    `json:"email,omitempty" bson:"email,omitempty"`
+	Used      bool      `json:"used" bson:"used"`
+	ExpiresAt time.Time `json:"expires_at" bson:"expires_at"`
+	CreatedAt time.Time `json:"created_at,omitempty" bson:"created_at,omitempty"`
+	UpdatedAt time.Time `json:"updated_at,omitempty" bson:"updated_at,omitempty"`
+}
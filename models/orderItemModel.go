@@ -3,6 +3,7 @@ package models
 import (
 	"time"
 
+	"github.com/ShahSau/culinary-bliss/money"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
@@ -11,8 +12,12 @@ type OrderItem struct {
 	Food_id       string             `json:"food_id" binding:"required" bson:"food_id"`
 	Order_id      string             `json:"order_id" binding:"required" bson:"order_id"`
 	Order_item_id string             `json:"order_item_id" bson:"order_item_id"`
-	Quantity      string             `json:"quantity" binding:"required" validate:"eq=S|eq=M|eq=L" bson:"quantity"`
-	Total_amount  float64            `json:"total_amount" binding:"required" bson:"total_amount"`
+	Quantity      int                `json:"quantity" bson:"quantity"`
+	Portion       string             `json:"portion" bson:"portion"`
+	Modifiers     []Modifier         `json:"modifiers" bson:"modifiers"`
+	Notes         string             `json:"notes,omitempty" bson:"notes,omitempty"`
+	Unit_price    money.Money        `json:"unit_price" bson:"unit_price"`
+	Total_amount  money.Money        `json:"total_amount" bson:"total_amount"`
 	CreatedAt     time.Time          `json:"created_at,omitempty" bson:"created_at,omitempty"`
 	UpdatedAt     time.Time          `json:"updated_at,omitempty" bson:"updated_at,omitempty"`
 }
@@ -3,23 +3,52 @@ package models
 import (
 	"time"
 
+	"github.com/ShahSau/culinary-bliss/money"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type Order struct {
-	ID           primitive.ObjectID `json:"_id,omitempty" bson:"_id,omitempty"`
-	Order_id     string             `json:"order_id"  bson:"order_id"`
-	Table_id     string             `json:"table_id" binding:"required" bson:"table_id"`
-	Order_status string             `json:"order_status" binding:"required" bson:"order_status"`
-	Order_date   time.Time          `json:"order_date" bson:"order_date"`
-	Total_amount float64            `json:"total_amount" binding:"required" bson:"total_amount"`
-	CreatedAt    time.Time          `json:"created_at,omitempty" bson:"created_at,omitempty"`
-	UpdatedAt    time.Time          `json:"updated_at,omitempty" bson:"updated_at,omitempty"`
+	ID       primitive.ObjectID `json:"_id,omitempty" bson:"_id,omitempty"`
+	Order_id string             `json:"order_id"  bson:"order_id"`
+	Table_id string             `json:"table_id" binding:"required" bson:"table_id"`
+	// Restaurant_id is the restaurant of the table, it is copied onto the order so API keys of the restaurant can find it
+	Restaurant_id  string              `json:"restaurant_id,omitempty" bson:"restaurant_id,omitempty"`
+	Order_status   string              `json:"order_status" bson:"order_status"`
+	Status_history []OrderStatusChange `json:"status_history" bson:"status_history"`
+	Order_date     time.Time           `json:"order_date" bson:"order_date"`
+	Total_amount   money.Money         `json:"total_amount" binding:"required" bson:"total_amount"`
+	CreatedAt      time.Time           `json:"created_at,omitempty" bson:"created_at,omitempty"`
+	UpdatedAt      time.Time           `json:"updated_at,omitempty" bson:"updated_at,omitempty"`
 }
 
-type ResponseOrder struct {
-	AllOrders     []Order `json:"all_orders"`
-	Page          int     `json:"page"`
-	RecordPerPage int     `json:"record_per_page"`
-	StartIndex    int     `json:"start_index"`
+// OrderStatusChange records when an order reached a status and who moved it there
+type OrderStatusChange struct {
+	Status     string    `json:"status" bson:"status"`
+	At         time.Time `json:"at" bson:"at"`
+	User_id    string    `json:"user_id,omitempty" bson:"user_id,omitempty"`
+	Api_key_id string    `json:"api_key_id,omitempty" bson:"api_key_id,omitempty"`
+}
+
+// OrderDetails is an order with its table and its items joined to their food
+type OrderDetails struct {
+	Order Order `json:"order" bson:"order"`
+	// Table is nil when the table of the order was deleted
+	Table       *Table            `json:"table" bson:"table,omitempty"`
+	Order_items []OrderDetailItem `json:"order_items" bson:"order_items"`
+	// Payment_due is what the items of the order add up to
+	Payment_due money.Money `json:"payment_due" bson:"payment_due"`
+}
+
+// OrderDetailItem is an order item with the name and image of its food
+type OrderDetailItem struct {
+	Order_item_id string      `json:"order_item_id" bson:"order_item_id"`
+	Food_id       string      `json:"food_id" bson:"food_id"`
+	Name          string      `json:"name" bson:"name"`
+	Image         string      `json:"image" bson:"image"`
+	Quantity      int         `json:"quantity" bson:"quantity"`
+	Portion       string      `json:"portion" bson:"portion"`
+	Modifiers     []Modifier  `json:"modifiers" bson:"modifiers"`
+	Notes         string      `json:"notes,omitempty" bson:"notes,omitempty"`
+	Unit_price    money.Money `json:"unit_price" bson:"unit_price"`
+	Total_amount  money.Money `json:"total_amount" bson:"total_amount"`
 }
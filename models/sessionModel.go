@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// Session is the public view of a token family, one per logged in device
+type Session struct {
+	Session_id string    `json:"session_id"`
+	User_agent string    `json:"user_agent"`
+	Ip_address string    `json:"ip_address"`
+	Current    bool      `json:"current"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
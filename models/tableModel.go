@@ -12,6 +12,7 @@ type Table struct {
 	Table_id         string             `json:"table_id" binding:"required" bson:"table_id"`
 	Table_number     int                `json:"table_number" binding:"required" bson:"table_number"`
 	Table_status     string             `json:"table_status" binding:"required" bson:"table_status"`
+	Restaurant_id    string             `json:"restaurant_id,omitempty" bson:"restaurant_id,omitempty"`
 	CreatedAt        time.Time          `json:"created_at,omitempty" bson:"created_at,omitempty"`
 	UpdatedAt        time.Time          `json:"updated_at,omitempty" bson:"updated_at,omitempty"`
 }
@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TokenFamily tracks the chain of refresh tokens issued from a single login
+type TokenFamily struct {
+	ID                 primitive.ObjectID `json:"_id,omitempty" bson:"_id,omitempty"`
+	Family_id          string             `json:"family_id" bson:"family_id"`
+	User_id            string             `json:"user_id" bson:"user_id"`
+	Refresh_token_hash string             `json:"-" bson:"refresh_token_hash"`
+	Revoked            bool               `json:"revoked" bson:"revoked"`
+	User_agent         string             `json:"user_agent" bson:"user_agent"`
+	Ip_address         string             `json:"ip_address" bson:"ip_address"`
+	ExpiresAt          time.Time          `json:"expires_at" bson:"expires_at"`
+	CreatedAt          time.Time          `json:"created_at,omitempty" bson:"created_at,omitempty"`
+	UpdatedAt          time.Time          `json:"updated_at,omitempty" bson:"updated_at,omitempty"`
+}
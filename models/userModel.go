@@ -7,24 +7,23 @@ import (
 )
 
 type User struct {
-	ID           primitive.ObjectID `json:"_id,omitempty" bson:"_id,omitempty"`
-	First_name   string             `json:"first_name" binding:"required" bson:"first_name"`
-	Last_name    string             `json:"last_name" binding:"required" bson:"last_name"`
-	Email        string             `json:"email" binding:"required" bson:"email"`
-	Password     string             `json:"password" binding:"required" bson:"password"`
-	Avatar       string             `json:"avatar" bson:"avatar"`
-	Phone        string             `json:"phone" binding:"required" bson:"phone"`
-	Role         string             `json:"role" validate:"eq=Admin|eq=User" bson:"role"`
-	CreatedAt    time.Time          `json:"created_at,omitempty" bson:"created_at,omitempty"`
-	UpdatedAt    time.Time          `json:"updated_at,omitempty" bson:"updated_at,omitempty"`
-	User_id      string             `json:"user_id" bson:"user_id"`
-	Token        string             `json:"token,omitempty" bson:"token,omitempty"`
-	RefreshToken string             `json:"refresh_token,omitempty" bson:"refresh_token,omitempty"`
-}
+	ID         primitive.ObjectID `json:"_id,omitempty" bson:"_id,omitempty"`
+	First_name string             `json:"first_name" binding:"required" bson:"first_name"`
+	Last_name  string             `json:"last_name" binding:"required" bson:"last_name"`
+	Email      string             `json:"email" binding:"required" bson:"email"`
+	Password   string             `json:"-" bson:"password"`
+	Avatar     string             `json:"avatar" bson:"avatar"`
+	Phone      string             `json:"phone" binding:"required" bson:"phone"`
+	Role       string             `json:"role" validate:"eq=Admin|eq=Manager|eq=Waiter|eq=Kitchen|eq=Customer" bson:"role"`
+	Status     string             `json:"status" bson:"status"`
+	VerifiedAt *time.Time         `json:"verified_at,omitempty" bson:"verified_at,omitempty"`
+	CreatedAt  time.Time          `json:"created_at,omitempty" bson:"created_at,omitempty"`
+	UpdatedAt  time.Time          `json:"updated_at,omitempty" bson:"updated_at,omitempty"`
+	User_id    string             `json:"user_id" bson:"user_id"`
 
-type ResponseUser struct {
-	AllUsers      []User `json:"all_users"`
-	Page          int    `json:"page"`
-	RecordPerPage int    `json:"record_per_page"`
-	StartIndex    int    `json:"start_index"`
+	Mfa_enabled        bool     `json:"mfa_enabled" bson:"mfa_enabled"`
+	Mfa_secret         string   `json:"-" bson:"mfa_secret,omitempty"`
+	Mfa_pending_secret string   `json:"-" bson:"mfa_pending_secret,omitempty"`
+	Mfa_recovery_codes []string `json:"-" bson:"mfa_recovery_codes,omitempty"`
+	Mfa_last_step      int64    `json:"-" bson:"mfa_last_step,omitempty"`
 }
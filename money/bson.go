@@ -0,0 +1,50 @@
+package money
+
+import (
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+)
+
+// bsonMoney is how amounts are stored, the amount counts minor units
+type bsonMoney struct {
+	Amount   int64  `bson:"amount"`
+	Currency string `bson:"currency"`
+}
+
+func (m Money) MarshalBSONValue() (bsontype.Type, []byte, error) {
+	data, err := bson.Marshal(bsonMoney{Amount: m.Amount, Currency: m.currency()})
+	return bson.TypeEmbeddedDocument, data, err
+}
+
+// UnmarshalBSONValue reads stored amounts, and the plain numbers prices were kept as before, in the configured currency
+func (m *Money) UnmarshalBSONValue(t bsontype.Type, data []byte) error {
+	raw := bson.RawValue{Type: t, Value: data}
+	switch t {
+	case bson.TypeEmbeddedDocument:
+		var stored bsonMoney
+		if err := raw.Unmarshal(&stored); err != nil {
+			return err
+		}
+		*m = Money{Amount: stored.Amount, Currency: stored.Currency}
+		return nil
+	case bson.TypeDouble:
+		parsed, err := FromFloat(raw.Double(), defaultCurrency)
+		if err != nil {
+			return err
+		}
+		*m = parsed
+		return nil
+	case bson.TypeInt32:
+		*m = New(int64(raw.Int32())*Scale(defaultCurrency), defaultCurrency)
+		return nil
+	case bson.TypeInt64:
+		*m = New(raw.Int64()*Scale(defaultCurrency), defaultCurrency)
+		return nil
+	case bson.TypeNull, bson.TypeUndefined:
+		*m = Money{}
+		return nil
+	}
+	return fmt.Errorf("cannot read a %s as an amount", t)
+}
@@ -0,0 +1,243 @@
+// Package money keeps amounts as whole minor units of an ISO 4217 currency, so adding them up never drifts by a cent
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Money is an amount in one currency. The zero value is nothing in the configured currency.
+type Money struct {
+	// Amount counts minor units, e.g. cents. JSON has it in major units.
+	Amount   int64  `bson:"amount" swaggertype:"string" example:"12.50"`
+	Currency string `bson:"currency" example:"USD"`
+}
+
+var ErrCurrencyMismatch = errors.New("amounts are in different currencies")
+
+// exponents are the digits after the decimal point of the currencies this package knows
+var exponents = map[string]int{
+	"AUD": 2, "BHD": 3, "BRL": 2, "CAD": 2, "CHF": 2, "CNY": 2, "CZK": 2, "DKK": 2,
+	"EUR": 2, "GBP": 2, "HKD": 2, "HUF": 2, "INR": 2, "ISK": 0, "JPY": 0, "KRW": 0,
+	"KWD": 3, "MXN": 2, "NOK": 2, "NZD": 2, "OMR": 3, "PLN": 2, "SEK": 2, "SGD": 2,
+	"TND": 3, "TRY": 2, "USD": 2, "ZAR": 2,
+}
+
+// defaultCurrency is what every price of the API is in, it is set once at start up
+var defaultCurrency = "USD"
+
+// Configure sets the currency prices are in, it must be called before serving requests
+func Configure(currency string) error {
+	if !IsCurrency(currency) {
+		return fmt.Errorf("%q is not a currency", currency)
+	}
+	defaultCurrency = currency
+	return nil
+}
+
+// Default is the configured currency
+func Default() string {
+	return defaultCurrency
+}
+
+// IsCurrency reports whether code is an ISO 4217 currency this package knows
+func IsCurrency(code string) bool {
+	_, ok := exponents[code]
+	return ok
+}
+
+// New is amount minor units of the currency, or of the configured one if currency is empty
+func New(amount int64, currency string) Money {
+	if currency == "" {
+		currency = defaultCurrency
+	}
+	return Money{Amount: amount, Currency: currency}
+}
+
+// Parse reads a decimal amount such as 12.5 or -0.125, rounding half away from zero to the minor unit
+func Parse(s string, currency string) (Money, error) {
+	m := New(0, currency)
+	exponent, ok := exponents[m.Currency]
+	if !ok {
+		return Money{}, fmt.Errorf("%q is not a currency", m.Currency)
+	}
+
+	negative := strings.HasPrefix(s, "-")
+	whole, fraction, _ := strings.Cut(strings.TrimLeft(s, "+-"), ".")
+	if whole == "" && fraction == "" || strings.Trim(whole+fraction, "0123456789") != "" {
+		return Money{}, fmt.Errorf("%q is not an amount", s)
+	}
+	// the first digit past the minor unit decides the rounding
+	roundUp := len(fraction) > exponent && fraction[exponent] >= '5'
+	if len(fraction) > exponent {
+		fraction = fraction[:exponent]
+	}
+	digits := strings.TrimLeft(whole+fraction+strings.Repeat("0", exponent-len(fraction)), "0")
+	if digits == "" {
+		digits = "0"
+	}
+	amount, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil {
+		return Money{}, fmt.Errorf("%q is out of range", s)
+	}
+	if roundUp {
+		amount++
+	}
+	if negative {
+		amount = -amount
+	}
+	m.Amount = amount
+	return m, nil
+}
+
+// MustParse is Parse in the configured currency that panics on a bad amount, for amounts written in code
+func MustParse(s string) Money {
+	m, err := Parse(s, "")
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// FromFloat converts an amount given as a number, rounding to the minor unit the way Parse does
+func FromFloat(f float64, currency string) (Money, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return Money{}, fmt.Errorf("%v is not an amount", f)
+	}
+	return Parse(strconv.FormatFloat(f, 'f', -1, 64), currency)
+}
+
+// String is the amount in major units with every minor digit, e.g. 12.50
+func (m Money) String() string {
+	exponent := exponents[m.currency()]
+	amount := m.Amount
+	sign := ""
+	if amount < 0 {
+		sign, amount = "-", -amount
+	}
+	if exponent == 0 {
+		return sign + strconv.FormatInt(amount, 10)
+	}
+	return fmt.Sprintf("%s%d.%0*d", sign, amount/Scale(m.currency()), exponent, amount%Scale(m.currency()))
+}
+
+// Scale is how many minor units make a major one of the currency
+func Scale(currency string) int64 {
+	return int64(math.Pow10(exponents[currency]))
+}
+
+// IsZero reports whether there is no money at all
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+// Equal reports whether two amounts are the same amount of the same currency
+func (m Money) Equal(other Money) bool {
+	return m.Amount == other.Amount && m.currency() == other.currency()
+}
+
+// Add sums two amounts of the same currency. The zero value takes on the currency of the other amount.
+func (m Money) Add(other Money) (Money, error) {
+	currency, err := common(m, other)
+	if err != nil {
+		return Money{}, err
+	}
+	return Money{Amount: m.Amount + other.Amount, Currency: currency}, nil
+}
+
+// Sub takes other off the amount, the currencies must agree as for Add
+func (m Money) Sub(other Money) (Money, error) {
+	return m.Add(Money{Amount: -other.Amount, Currency: other.Currency})
+}
+
+// Times multiplies the amount by a count, e.g. a quantity
+func (m Money) Times(n int64) Money {
+	return Money{Amount: m.Amount * n, Currency: m.Currency}
+}
+
+// Percent is percent per cent of the amount, rounded half away from zero to the minor unit
+func (m Money) Percent(percent int64) Money {
+	product := m.Amount * percent
+	half := int64(50)
+	if product < 0 {
+		half = -half
+	}
+	return Money{Amount: (product + half) / 100, Currency: m.Currency}
+}
+
+// Sum adds up amounts of one currency, nothing adds up to zero
+func Sum(amounts ...Money) (Money, error) {
+	var total Money
+	for _, amount := range amounts {
+		var err error
+		if total, err = total.Add(amount); err != nil {
+			return Money{}, err
+		}
+	}
+	return total, nil
+}
+
+// currency is the currency of the amount, the configured one for the zero value
+func (m Money) currency() string {
+	if m.Currency == "" {
+		return defaultCurrency
+	}
+	return m.Currency
+}
+
+// common is the currency two amounts share, an amount without one goes with any other
+func common(a Money, b Money) (string, error) {
+	switch {
+	case a.Currency == "":
+		return b.Currency, nil
+	case b.Currency == "" || a.Currency == b.Currency:
+		return a.Currency, nil
+	}
+	return "", fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, a.Currency, b.Currency)
+}
+
+// jsonMoney is how amounts are sent, the amount is a decimal string so clients do not round it through a float
+type jsonMoney struct {
+	Amount   json.RawMessage `json:"amount"`
+	Currency string          `json:"currency"`
+}
+
+func (m Money) MarshalJSON() ([]byte, error) {
+	amount, _ := json.Marshal(m.String())
+	return json.Marshal(jsonMoney{Amount: amount, Currency: m.currency()})
+}
+
+// UnmarshalJSON reads {"amount": "12.50", "currency": "EUR"}, where the amount may also be a number and the currency may be left out,
+// or just the amount. Every price of the API is in the configured currency, so other currencies are rejected.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	var sent jsonMoney
+	if !strings.HasPrefix(strings.TrimSpace(string(data)), "{") {
+		sent.Amount = data
+	} else if err := json.Unmarshal(data, &sent); err != nil {
+		return err
+	}
+	if sent.Currency != "" && sent.Currency != defaultCurrency {
+		return fmt.Errorf("amounts must be in %s, not %q", defaultCurrency, sent.Currency)
+	}
+
+	var parsed Money
+	var amount string
+	var number float64
+	var err error
+	if json.Unmarshal(sent.Amount, &amount) == nil {
+		parsed, err = Parse(amount, defaultCurrency)
+	} else if json.Unmarshal(sent.Amount, &number) == nil {
+		parsed, err = FromFloat(number, defaultCurrency)
+	} else {
+		err = fmt.Errorf("an amount must be a number or a decimal string, got %s", sent.Amount)
+	}
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
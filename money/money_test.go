@@ -0,0 +1,135 @@
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestParseRoundsHalfAwayFromZero(t *testing.T) {
+	tests := []struct {
+		in       string
+		currency string
+		want     int64
+	}{
+		{"12", "EUR", 1200},
+		{"12.5", "EUR", 1250},
+		{"4.999", "EUR", 500},
+		{"4.994", "EUR", 499},
+		{"0.125", "EUR", 13},
+		{"-0.125", "EUR", -13},
+		{".5", "JPY", 1},
+		{"1.0005", "BHD", 1001},
+	}
+	for _, test := range tests {
+		m, err := Parse(test.in, test.currency)
+		if err != nil {
+			t.Fatalf("%s: %v", test.in, err)
+		}
+		if m.Amount != test.want || m.Currency != test.currency {
+			t.Fatalf("%s %s parsed to %+v, want %d", test.in, test.currency, m, test.want)
+		}
+	}
+
+	for _, bad := range []string{"", "-", "1.2.3", "12,50", "1e3", "abc"} {
+		if _, err := Parse(bad, "EUR"); err == nil {
+			t.Fatalf("expected %q to be rejected", bad)
+		}
+	}
+}
+
+func TestFromFloatDoesNotTruncate(t *testing.T) {
+	// 4.35 is 4.3499999999999996447 as a float, cutting it at two decimals gave 4.34
+	m, err := FromFloat(4.35, "EUR")
+	if err != nil || m.Amount != 435 {
+		t.Fatalf("got %+v %v", m, err)
+	}
+}
+
+func TestString(t *testing.T) {
+	tests := map[string]Money{
+		"12.50":  {Amount: 1250, Currency: "EUR"},
+		"0.05":   {Amount: 5, Currency: "EUR"},
+		"-1.05":  {Amount: -105, Currency: "EUR"},
+		"1200":   {Amount: 1200, Currency: "JPY"},
+		"1.001":  {Amount: 1001, Currency: "KWD"},
+		"0.00":   {},
+		"100.00": {Amount: 10000, Currency: "USD"},
+	}
+	for want, m := range tests {
+		if got := m.String(); got != want {
+			t.Fatalf("%+v printed as %s, want %s", m, got, want)
+		}
+	}
+}
+
+func TestArithmetic(t *testing.T) {
+	eur := func(amount int64) Money { return Money{Amount: amount, Currency: "EUR"} }
+
+	total, err := Sum(eur(450), eur(80), Money{})
+	if err != nil || total != eur(530) {
+		t.Fatalf("got %+v %v", total, err)
+	}
+	if _, err := eur(1).Add(Money{Amount: 1, Currency: "GBP"}); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Fatalf("expected a currency mismatch, got %v", err)
+	}
+	if got := eur(450).Percent(75); got != eur(338) {
+		t.Fatalf("expected 3.375 to round to 3.38, got %v", got)
+	}
+	if got := eur(-450).Percent(75); got != eur(-338) {
+		t.Fatalf("expected -3.375 to round to -3.38, got %v", got)
+	}
+	if got := eur(418).Times(3); got != eur(1254) {
+		t.Fatalf("got %v", got)
+	}
+}
+
+func TestJSON(t *testing.T) {
+	defer Configure(defaultCurrency)
+	if err := Configure("EUR"); err != nil {
+		t.Fatal(err)
+	}
+
+	encoded, err := json.Marshal(Money{Amount: 1250, Currency: "EUR"})
+	if err != nil || string(encoded) != `{"amount":"12.50","currency":"EUR"}` {
+		t.Fatalf("got %s %v", encoded, err)
+	}
+
+	for _, sent := range []string{`{"amount":"12.50","currency":"EUR"}`, `{"amount":12.5}`, `12.5`, `"12.50"`} {
+		var m Money
+		if err := json.Unmarshal([]byte(sent), &m); err != nil || m != (Money{Amount: 1250, Currency: "EUR"}) {
+			t.Fatalf("%s decoded to %+v %v", sent, m, err)
+		}
+	}
+	var m Money
+	if err := json.Unmarshal([]byte(`{"amount":"12.50","currency":"GBP"}`), &m); err == nil {
+		t.Fatal("expected an amount in another currency to be rejected")
+	}
+}
+
+func TestBSON(t *testing.T) {
+	type priced struct {
+		Price Money `bson:"price"`
+	}
+
+	data, err := bson.Marshal(priced{Price: Money{Amount: 1250, Currency: "EUR"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var stored bson.M
+	bson.Unmarshal(data, &stored)
+	if price := stored["price"].(bson.M); price["amount"] != int64(1250) || price["currency"] != "EUR" {
+		t.Fatalf("stored %v", stored)
+	}
+	var decoded priced
+	if err := bson.Unmarshal(data, &decoded); err != nil || decoded.Price != (Money{Amount: 1250, Currency: "EUR"}) {
+		t.Fatalf("decoded %+v %v", decoded, err)
+	}
+
+	legacy, _ := bson.Marshal(bson.M{"price": 4.35})
+	if err := bson.Unmarshal(legacy, &decoded); err != nil || decoded.Price != (Money{Amount: 435, Currency: defaultCurrency}) {
+		t.Fatalf("decoded a legacy price as %+v %v", decoded, err)
+	}
+}
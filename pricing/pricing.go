@@ -0,0 +1,78 @@
+// Package pricing works out what order items and orders cost from the food they are made of, clients never set a total
+package pricing
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ShahSau/culinary-bliss/models"
+	"github.com/ShahSau/culinary-bliss/money"
+)
+
+// DefaultPortion is the portion of an item that does not name one, it costs the price on the menu
+const DefaultPortion = "M"
+
+// Portions maps every portion size to its price as a percentage of the food's price
+var Portions = map[string]int64{
+	"S": 75,
+	"M": 100,
+	"L": 150,
+}
+
+var (
+	ErrUnknownPortion  = errors.New("unknown portion")
+	ErrUnknownModifier = errors.New("unknown modifier")
+)
+
+// Line is what an order item costs
+type Line struct {
+	// Unit_price is one portion with its modifiers
+	Unit_price money.Money
+	// Modifiers are the chosen modifiers at the price the food charges for them
+	Modifiers    []models.Modifier
+	Total_amount money.Money
+}
+
+// Price works out quantity portions of food with the named modifiers.
+// It fails with ErrUnknownPortion if the portion is not one of Portions and with ErrUnknownModifier if the food does not offer a modifier.
+func Price(food models.Food, quantity int, portion string, modifiers []string) (Line, error) {
+	percent, ok := Portions[portion]
+	if !ok {
+		return Line{}, fmt.Errorf("%w %q", ErrUnknownPortion, portion)
+	}
+
+	// a portion is rounded to the minor unit before anything is added to it
+	line := Line{Unit_price: food.Price.Percent(percent), Modifiers: []models.Modifier{}}
+	for _, name := range modifiers {
+		modifier, ok := offered(food, name)
+		if !ok {
+			return Line{}, fmt.Errorf("%w %q for %s", ErrUnknownModifier, name, food.Name)
+		}
+		unit, err := line.Unit_price.Add(modifier.Price)
+		if err != nil {
+			return Line{}, err
+		}
+		line.Modifiers = append(line.Modifiers, modifier)
+		line.Unit_price = unit
+	}
+	line.Total_amount = line.Unit_price.Times(int64(quantity))
+	return line, nil
+}
+
+// Total adds up the items of an order
+func Total(items []models.OrderItem) (money.Money, error) {
+	amounts := make([]money.Money, len(items))
+	for i, item := range items {
+		amounts[i] = item.Total_amount
+	}
+	return money.Sum(amounts...)
+}
+
+func offered(food models.Food, name string) (models.Modifier, bool) {
+	for _, modifier := range food.Modifiers {
+		if modifier.Name == name {
+			return modifier, true
+		}
+	}
+	return models.Modifier{}, false
+}
@@ -0,0 +1,58 @@
+package pricing
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ShahSau/culinary-bliss/models"
+	"github.com/ShahSau/culinary-bliss/money"
+)
+
+var soup = models.Food{Name: "Soup", Price: money.MustParse("4.50"), Modifiers: []models.Modifier{
+	{Name: "Bread", Price: money.MustParse("0.80")},
+	{Name: "Chilli", Price: money.MustParse("0")},
+}}
+
+func TestPrice(t *testing.T) {
+	tests := []struct {
+		name      string
+		quantity  int
+		portion   string
+		modifiers []string
+		unit      string
+		total     string
+	}{
+		{"menu price", 1, "M", nil, "4.50", "4.50"},
+		{"portions scale the price", 2, "L", nil, "6.75", "13.50"},
+		// 3.375 rounds up before the bread is added
+		{"modifiers are not scaled", 3, "S", []string{"Bread", "Chilli"}, "4.18", "12.54"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			line, err := Price(soup, test.quantity, test.portion, test.modifiers)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if line.Unit_price.String() != test.unit || line.Total_amount.String() != test.total || len(line.Modifiers) != len(test.modifiers) {
+				t.Fatalf("got %+v, want %s a portion and %s in total", line, test.unit, test.total)
+			}
+		})
+	}
+}
+
+func TestPriceRejects(t *testing.T) {
+	if _, err := Price(soup, 1, "XL", nil); !errors.Is(err, ErrUnknownPortion) {
+		t.Fatalf("expected an unknown portion, got %v", err)
+	}
+	if _, err := Price(soup, 1, "M", []string{"Bread", "Croutons"}); !errors.Is(err, ErrUnknownModifier) || err.Error() != `unknown modifier "Croutons" for Soup` {
+		t.Fatalf("expected an unknown modifier, got %v", err)
+	}
+}
+
+func TestTotal(t *testing.T) {
+	total, err := Total([]models.OrderItem{{Total_amount: money.MustParse("0.10")}, {Total_amount: money.MustParse("0.20")}})
+	if err != nil || !total.Equal(money.MustParse("0.30")) {
+		t.Fatalf("got %v %v", total, err)
+	}
+}
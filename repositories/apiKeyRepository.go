@@ -0,0 +1,79 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/ShahSau/culinary-bliss/listing"
+	"github.com/ShahSau/culinary-bliss/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// ApiKeyRepository stores API keys, keyed by key_id. Only the hash of a key is stored.
+type ApiKeyRepository interface {
+	Create(ctx context.Context, apiKey models.ApiKey) error
+	List(ctx context.Context, query listing.Query) (listing.Page[models.ApiKey], error)
+	FindActiveByHash(ctx context.Context, keyHash string) (models.ApiKey, error)
+	// Revoke revokes a key that is not revoked yet and returns it afterwards
+	Revoke(ctx context.Context, keyID string, at time.Time) (models.ApiKey, error)
+	TouchLastUsed(ctx context.Context, keyID string, at time.Time) error
+}
+
+type mongoApiKeyRepository struct {
+	collection *mongo.Collection
+}
+
+func (r *mongoApiKeyRepository) Create(ctx context.Context, apiKey models.ApiKey) error {
+	_, err := r.collection.InsertOne(ctx, apiKey)
+	return err
+}
+
+func (r *mongoApiKeyRepository) List(ctx context.Context, query listing.Query) (listing.Page[models.ApiKey], error) {
+	return list[models.ApiKey](ctx, r.collection, query)
+}
+
+func (r *mongoApiKeyRepository) FindActiveByHash(ctx context.Context, keyHash string) (models.ApiKey, error) {
+	return findOne[models.ApiKey](ctx, r.collection, bson.M{"key_hash": keyHash, "revoked": false})
+}
+
+func (r *mongoApiKeyRepository) Revoke(ctx context.Context, keyID string, at time.Time) (models.ApiKey, error) {
+	update := bson.D{{Key: "$set", Value: bson.D{{Key: "revoked", Value: true}, {Key: "revoked_at", Value: at}, {Key: "updated_at", Value: at}}}}
+	return findOneAndUpdate[models.ApiKey](ctx, r.collection, bson.M{"key_id": keyID, "revoked": false}, update, false)
+}
+
+func (r *mongoApiKeyRepository) TouchLastUsed(ctx context.Context, keyID string, at time.Time) error {
+	return updateOne(ctx, r.collection, bson.M{"key_id": keyID}, bson.D{{Key: "$set", Value: bson.D{{Key: "last_used_at", Value: at}}}})
+}
+
+type memoryApiKeyRepository struct {
+	store memoryStore[models.ApiKey]
+}
+
+func (r *memoryApiKeyRepository) Create(ctx context.Context, apiKey models.ApiKey) error {
+	r.store.insert(apiKey)
+	return nil
+}
+
+func (r *memoryApiKeyRepository) List(ctx context.Context, query listing.Query) (listing.Page[models.ApiKey], error) {
+	return r.store.list(query), nil
+}
+
+func (r *memoryApiKeyRepository) FindActiveByHash(ctx context.Context, keyHash string) (models.ApiKey, error) {
+	return r.store.find(func(apiKey models.ApiKey) bool { return apiKey.Key_hash == keyHash && !apiKey.Revoked })
+}
+
+func (r *memoryApiKeyRepository) Revoke(ctx context.Context, keyID string, at time.Time) (models.ApiKey, error) {
+	return r.store.update(func(apiKey models.ApiKey) bool { return apiKey.Key_id == keyID && !apiKey.Revoked }, func(apiKey *models.ApiKey) {
+		revokedAt := at
+		apiKey.Revoked, apiKey.RevokedAt, apiKey.UpdatedAt = true, &revokedAt, at
+	})
+}
+
+func (r *memoryApiKeyRepository) TouchLastUsed(ctx context.Context, keyID string, at time.Time) error {
+	_, err := r.store.update(func(apiKey models.ApiKey) bool { return apiKey.Key_id == keyID }, func(apiKey *models.ApiKey) {
+		lastUsedAt := at
+		apiKey.LastUsedAt = &lastUsedAt
+	})
+	return err
+}
@@ -0,0 +1,31 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/ShahSau/culinary-bliss/models"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// AuditEventRepository is the append only log of security relevant events
+type AuditEventRepository interface {
+	Create(ctx context.Context, event models.AuditEvent) error
+}
+
+type mongoAuditEventRepository struct {
+	collection *mongo.Collection
+}
+
+func (r *mongoAuditEventRepository) Create(ctx context.Context, event models.AuditEvent) error {
+	_, err := r.collection.InsertOne(ctx, event)
+	return err
+}
+
+type memoryAuditEventRepository struct {
+	store memoryStore[models.AuditEvent]
+}
+
+func (r *memoryAuditEventRepository) Create(ctx context.Context, event models.AuditEvent) error {
+	r.store.insert(event)
+	return nil
+}
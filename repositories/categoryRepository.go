@@ -0,0 +1,21 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/ShahSau/culinary-bliss/listing"
+	"github.com/ShahSau/culinary-bliss/models"
+)
+
+// CategoryRepository stores categories, keyed by category_id
+type CategoryRepository interface {
+	List(ctx context.Context, query listing.Query) (listing.Page[models.Category], error)
+	FindByID(ctx context.Context, categoryID string) (models.Category, error)
+	Create(ctx context.Context, category models.Category) error
+	Update(ctx context.Context, category models.Category) error
+	Delete(ctx context.Context, categoryID string) error
+}
+
+func categoryID(category models.Category) string {
+	return category.Category_id
+}
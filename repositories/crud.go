@@ -0,0 +1,69 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/ShahSau/culinary-bliss/listing"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// mongoCrud implements the plain create, read, update and delete of aggregates identified by a single id field
+type mongoCrud[T any] struct {
+	collection *mongo.Collection
+	idField    string
+	id         func(T) string
+}
+
+func (r *mongoCrud[T]) List(ctx context.Context, query listing.Query) (listing.Page[T], error) {
+	return list[T](ctx, r.collection, query)
+}
+
+func (r *mongoCrud[T]) FindByID(ctx context.Context, id string) (T, error) {
+	return findOne[T](ctx, r.collection, bson.M{r.idField: id})
+}
+
+func (r *mongoCrud[T]) Create(ctx context.Context, document T) error {
+	_, err := r.collection.InsertOne(ctx, document)
+	return err
+}
+
+func (r *mongoCrud[T]) Update(ctx context.Context, document T) error {
+	return updateOne(ctx, r.collection, bson.M{r.idField: r.id(document)}, bson.D{{Key: "$set", Value: document}})
+}
+
+func (r *mongoCrud[T]) Delete(ctx context.Context, id string) error {
+	return deleteOne(ctx, r.collection, bson.M{r.idField: id})
+}
+
+// memoryCrud is the in-memory counterpart of mongoCrud
+type memoryCrud[T any] struct {
+	store memoryStore[T]
+	id    func(T) string
+}
+
+func (r *memoryCrud[T]) byID(id string) func(T) bool {
+	return func(document T) bool { return r.id(document) == id }
+}
+
+func (r *memoryCrud[T]) List(ctx context.Context, query listing.Query) (listing.Page[T], error) {
+	return r.store.list(query), nil
+}
+
+func (r *memoryCrud[T]) FindByID(ctx context.Context, id string) (T, error) {
+	return r.store.find(r.byID(id))
+}
+
+func (r *memoryCrud[T]) Create(ctx context.Context, document T) error {
+	r.store.insert(document)
+	return nil
+}
+
+func (r *memoryCrud[T]) Update(ctx context.Context, document T) error {
+	_, err := r.store.update(r.byID(r.id(document)), func(stored *T) { *stored = document })
+	return err
+}
+
+func (r *memoryCrud[T]) Delete(ctx context.Context, id string) error {
+	return r.store.remove(r.byID(id))
+}
@@ -0,0 +1,21 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/ShahSau/culinary-bliss/listing"
+	"github.com/ShahSau/culinary-bliss/models"
+)
+
+// FoodRepository stores foods, keyed by food_id
+type FoodRepository interface {
+	List(ctx context.Context, query listing.Query) (listing.Page[models.Food], error)
+	FindByID(ctx context.Context, foodID string) (models.Food, error)
+	Create(ctx context.Context, food models.Food) error
+	Update(ctx context.Context, food models.Food) error
+	Delete(ctx context.Context, foodID string) error
+}
+
+func foodID(food models.Food) string {
+	return food.Food_id
+}
@@ -0,0 +1,21 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/ShahSau/culinary-bliss/listing"
+	"github.com/ShahSau/culinary-bliss/models"
+)
+
+// InvoiceRepository stores invoices, keyed by invoice_id
+type InvoiceRepository interface {
+	List(ctx context.Context, query listing.Query) (listing.Page[models.Invoice], error)
+	FindByID(ctx context.Context, invoiceID string) (models.Invoice, error)
+	Create(ctx context.Context, invoice models.Invoice) error
+	Update(ctx context.Context, invoice models.Invoice) error
+	Delete(ctx context.Context, invoiceID string) error
+}
+
+func invoiceID(invoice models.Invoice) string {
+	return invoice.Invoice_id
+}
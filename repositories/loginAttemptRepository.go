@@ -0,0 +1,105 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/ShahSau/culinary-bliss/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// LoginAttemptRepository counts failed logins per account or IP address key. Counters are forgotten once they expire.
+type LoginAttemptRepository interface {
+	FindByKeys(ctx context.Context, keys []string) ([]models.LoginAttempt, error)
+	// RecordFailure adds a failure to the counter of the key, starting one if there is none, and returns the counter afterwards
+	RecordFailure(ctx context.Context, key string, at time.Time, expiresAt time.Time) (models.LoginAttempt, error)
+	// Lock resets the failures of the key, locks it until lockedUntil and counts the lockout
+	Lock(ctx context.Context, key string, lockedUntil time.Time, expiresAt time.Time) error
+	// ClearFailures resets the failures of the key and lifts its lock. The lockout count is kept until the counter expires.
+	// Clearing a key without a counter is not an error.
+	ClearFailures(ctx context.Context, key string) error
+}
+
+type mongoLoginAttemptRepository struct {
+	collection *mongo.Collection
+}
+
+func (r *mongoLoginAttemptRepository) FindByKeys(ctx context.Context, keys []string) ([]models.LoginAttempt, error) {
+	return findAll[models.LoginAttempt](ctx, r.collection, bson.M{"key": bson.M{"$in": keys}})
+}
+
+func (r *mongoLoginAttemptRepository) RecordFailure(ctx context.Context, key string, at time.Time, expiresAt time.Time) (models.LoginAttempt, error) {
+	update := bson.D{
+		{Key: "$inc", Value: bson.D{{Key: "failures", Value: 1}}},
+		{Key: "$set", Value: bson.D{{Key: "last_failure_at", Value: at}, {Key: "expires_at", Value: expiresAt}}},
+		{Key: "$setOnInsert", Value: bson.D{{Key: "created_at", Value: at}}},
+	}
+	return findOneAndUpdate[models.LoginAttempt](ctx, r.collection, bson.M{"key": key}, update, true)
+}
+
+func (r *mongoLoginAttemptRepository) Lock(ctx context.Context, key string, lockedUntil time.Time, expiresAt time.Time) error {
+	update := bson.D{
+		{Key: "$set", Value: bson.D{{Key: "failures", Value: 0}, {Key: "locked_until", Value: lockedUntil}, {Key: "expires_at", Value: expiresAt}}},
+		{Key: "$inc", Value: bson.D{{Key: "lockouts", Value: 1}}},
+	}
+	return updateOne(ctx, r.collection, bson.M{"key": key}, update)
+}
+
+func (r *mongoLoginAttemptRepository) ClearFailures(ctx context.Context, key string) error {
+	update := bson.D{
+		{Key: "$set", Value: bson.D{{Key: "failures", Value: 0}}},
+		{Key: "$unset", Value: bson.D{{Key: "locked_until", Value: ""}}},
+	}
+	_, err := r.collection.UpdateOne(ctx, bson.M{"key": key}, update)
+	return err
+}
+
+type memoryLoginAttemptRepository struct {
+	store memoryStore[models.LoginAttempt]
+}
+
+// live matches the unexpired counter of the key, like the TTL index does in Mongo
+func (r *memoryLoginAttemptRepository) live(key string, now time.Time) func(models.LoginAttempt) bool {
+	return func(attempt models.LoginAttempt) bool { return attempt.Key == key && attempt.ExpiresAt.After(now) }
+}
+
+func (r *memoryLoginAttemptRepository) FindByKeys(ctx context.Context, keys []string) ([]models.LoginAttempt, error) {
+	now := time.Now()
+	return r.store.filter(func(attempt models.LoginAttempt) bool {
+		return attempt.ExpiresAt.After(now) && contains(keys, attempt.Key)
+	}), nil
+}
+
+func (r *memoryLoginAttemptRepository) RecordFailure(ctx context.Context, key string, at time.Time, expiresAt time.Time) (models.LoginAttempt, error) {
+	r.store.remove(func(attempt models.LoginAttempt) bool { return attempt.Key == key && !attempt.ExpiresAt.After(at) })
+
+	attempt, err := r.store.update(r.live(key, at), func(attempt *models.LoginAttempt) {
+		attempt.Failures++
+		attempt.LastFailureAt, attempt.ExpiresAt = at, expiresAt
+	})
+	if err == ErrNotFound {
+		attempt = models.LoginAttempt{Key: key, Failures: 1, LastFailureAt: at, ExpiresAt: expiresAt, CreatedAt: at}
+		r.store.insert(attempt)
+		return attempt, nil
+	}
+	return attempt, err
+}
+
+func (r *memoryLoginAttemptRepository) Lock(ctx context.Context, key string, lockedUntil time.Time, expiresAt time.Time) error {
+	_, err := r.store.update(r.live(key, time.Now()), func(attempt *models.LoginAttempt) {
+		attempt.Failures, attempt.LockedUntil, attempt.ExpiresAt = 0, lockedUntil, expiresAt
+		attempt.Lockouts++
+	})
+	return err
+}
+
+func (r *memoryLoginAttemptRepository) ClearFailures(ctx context.Context, key string) error {
+	_, err := r.store.update(r.live(key, time.Now()), func(attempt *models.LoginAttempt) {
+		attempt.Failures, attempt.LockedUntil = 0, time.Time{}
+	})
+	if err == ErrNotFound {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,21 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/ShahSau/culinary-bliss/listing"
+	"github.com/ShahSau/culinary-bliss/models"
+)
+
+// MenuRepository stores menus, keyed by menu_id
+type MenuRepository interface {
+	List(ctx context.Context, query listing.Query) (listing.Page[models.Menu], error)
+	FindByID(ctx context.Context, menuID string) (models.Menu, error)
+	Create(ctx context.Context, menu models.Menu) error
+	Update(ctx context.Context, menu models.Menu) error
+	Delete(ctx context.Context, menuID string) error
+}
+
+func menuID(menu models.Menu) string {
+	return menu.Menu_id
+}
@@ -0,0 +1,68 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/ShahSau/culinary-bliss/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// OneTimeTokenRepository stores the single use tokens of one kind of emailed link, keyed by token hash
+type OneTimeTokenRepository interface {
+	Create(ctx context.Context, token models.OneTimeToken) error
+	// InvalidateUnused marks every unused token of the user as used
+	InvalidateUnused(ctx context.Context, userID string, at time.Time) error
+	// Consume marks an unused, unexpired token as used and returns it
+	Consume(ctx context.Context, tokenHash string, at time.Time) (models.OneTimeToken, error)
+}
+
+type mongoOneTimeTokenRepository struct {
+	collection *mongo.Collection
+}
+
+func (r *mongoOneTimeTokenRepository) Create(ctx context.Context, token models.OneTimeToken) error {
+	_, err := r.collection.InsertOne(ctx, token)
+	return err
+}
+
+func (r *mongoOneTimeTokenRepository) InvalidateUnused(ctx context.Context, userID string, at time.Time) error {
+	update := bson.D{{Key: "$set", Value: bson.D{{Key: "used", Value: true}, {Key: "updated_at", Value: at}}}}
+	_, err := r.collection.UpdateMany(ctx, bson.M{"user_id": userID, "used": false}, update)
+	return err
+}
+
+func (r *mongoOneTimeTokenRepository) Consume(ctx context.Context, tokenHash string, at time.Time) (models.OneTimeToken, error) {
+	filter := bson.M{"token_hash": tokenHash, "used": false, "expires_at": bson.M{"$gt": at}}
+	update := bson.D{{Key: "$set", Value: bson.D{{Key: "used", Value: true}, {Key: "updated_at", Value: at}}}}
+	return findOneAndUpdate[models.OneTimeToken](ctx, r.collection, filter, update, false)
+}
+
+type memoryOneTimeTokenRepository struct {
+	store memoryStore[models.OneTimeToken]
+}
+
+func (r *memoryOneTimeTokenRepository) Create(ctx context.Context, token models.OneTimeToken) error {
+	r.store.insert(token)
+	return nil
+}
+
+func (r *memoryOneTimeTokenRepository) InvalidateUnused(ctx context.Context, userID string, at time.Time) error {
+	_, err := r.store.update(func(token models.OneTimeToken) bool { return token.User_id == userID && !token.Used }, func(token *models.OneTimeToken) {
+		token.Used, token.UpdatedAt = true, at
+	})
+	if err == ErrNotFound {
+		return nil
+	}
+	return err
+}
+
+func (r *memoryOneTimeTokenRepository) Consume(ctx context.Context, tokenHash string, at time.Time) (models.OneTimeToken, error) {
+	usable := func(token models.OneTimeToken) bool {
+		return token.Token_hash == tokenHash && !token.Used && token.ExpiresAt.After(at)
+	}
+	return r.store.update(usable, func(token *models.OneTimeToken) {
+		token.Used, token.UpdatedAt = true, at
+	})
+}
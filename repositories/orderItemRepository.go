@@ -0,0 +1,45 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/ShahSau/culinary-bliss/listing"
+	"github.com/ShahSau/culinary-bliss/models"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// OrderItemRepository stores order items, keyed by order_item_id
+type OrderItemRepository interface {
+	List(ctx context.Context, query listing.Query) (listing.Page[models.OrderItem], error)
+	FindByID(ctx context.Context, orderItemID string) (models.OrderItem, error)
+	Create(ctx context.Context, orderItem models.OrderItem) error
+	Update(ctx context.Context, orderItem models.OrderItem) error
+	Delete(ctx context.Context, orderItemID string) error
+	// DeleteByOrder removes every item of an order, an order without items is not an error
+	DeleteByOrder(ctx context.Context, orderID string) error
+}
+
+func orderItemID(orderItem models.OrderItem) string {
+	return orderItem.Order_item_id
+}
+
+type mongoOrderItemRepository struct {
+	*mongoCrud[models.OrderItem]
+}
+
+func (r *mongoOrderItemRepository) DeleteByOrder(ctx context.Context, orderID string) error {
+	_, err := r.collection.DeleteMany(ctx, bson.M{"order_id": orderID})
+	return err
+}
+
+type memoryOrderItemRepository struct {
+	*memoryCrud[models.OrderItem]
+}
+
+func (r *memoryOrderItemRepository) DeleteByOrder(ctx context.Context, orderID string) error {
+	err := r.store.remove(func(item models.OrderItem) bool { return item.Order_id == orderID })
+	if err == ErrNotFound {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,117 @@
+package repositories
+
+import (
+	"context"
+	"sort"
+
+	"github.com/ShahSau/culinary-bliss/listing"
+	"github.com/ShahSau/culinary-bliss/models"
+	"github.com/ShahSau/culinary-bliss/money"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// OrderRepository stores orders, keyed by order_id
+type OrderRepository interface {
+	List(ctx context.Context, query listing.Query) (listing.Page[models.Order], error)
+	FindByID(ctx context.Context, orderID string) (models.Order, error)
+	Create(ctx context.Context, order models.Order) error
+	Update(ctx context.Context, order models.Order) error
+	Delete(ctx context.Context, orderID string) error
+	// Details joins the order to its table and its items to their food, the items in the order they were added
+	Details(ctx context.Context, orderID string) (models.OrderDetails, error)
+}
+
+func orderID(order models.Order) string {
+	return order.Order_id
+}
+
+type mongoOrderRepository struct {
+	*mongoCrud[models.Order]
+}
+
+// detailsPipeline joins an order with everything its detail view shows
+func detailsPipeline(orderID string) mongo.Pipeline {
+	items := mongo.Pipeline{
+		{{Key: "$match", Value: bson.D{{Key: "$expr", Value: bson.D{{Key: "$eq", Value: bson.A{"$order_id", "$$order_id"}}}}}}},
+		{{Key: "$sort", Value: bson.D{{Key: "created_at", Value: 1}, {Key: "order_item_id", Value: 1}}}},
+		{{Key: "$lookup", Value: bson.D{{Key: "from", Value: "food"}, {Key: "localField", Value: "food_id"}, {Key: "foreignField", Value: "food_id"}, {Key: "as", Value: "food"}}}},
+		{{Key: "$unwind", Value: bson.D{{Key: "path", Value: "$food"}, {Key: "preserveNullAndEmptyArrays", Value: true}}}},
+		{{Key: "$set", Value: bson.D{{Key: "name", Value: "$food.name"}, {Key: "image", Value: "$food.image"}}}},
+		{{Key: "$unset", Value: bson.A{"_id", "food"}}},
+	}
+
+	return mongo.Pipeline{
+		{{Key: "$match", Value: bson.D{{Key: "order_id", Value: orderID}}}},
+		{{Key: "$replaceWith", Value: bson.D{{Key: "order", Value: "$$ROOT"}}}},
+		{{Key: "$lookup", Value: bson.D{{Key: "from", Value: "tables"}, {Key: "localField", Value: "order.table_id"}, {Key: "foreignField", Value: "table_id"}, {Key: "as", Value: "table"}}}},
+		{{Key: "$unwind", Value: bson.D{{Key: "path", Value: "$table"}, {Key: "preserveNullAndEmptyArrays", Value: true}}}},
+		{{Key: "$lookup", Value: bson.D{{Key: "from", Value: "order_items"}, {Key: "let", Value: bson.D{{Key: "order_id", Value: "$order.order_id"}}}, {Key: "pipeline", Value: items}, {Key: "as", Value: "order_items"}}}},
+		{{Key: "$set", Value: bson.D{{Key: "payment_due", Value: bson.D{
+			{Key: "amount", Value: bson.D{{Key: "$sum", Value: "$order_items.total_amount.amount"}}},
+			{Key: "currency", Value: "$order.total_amount.currency"},
+		}}}}},
+	}
+}
+
+func (r *mongoOrderRepository) Details(ctx context.Context, orderID string) (models.OrderDetails, error) {
+	cursor, err := r.collection.Aggregate(ctx, detailsPipeline(orderID))
+	if err != nil {
+		return models.OrderDetails{}, err
+	}
+	var details []models.OrderDetails
+	if err := cursor.All(ctx, &details); err != nil {
+		return models.OrderDetails{}, err
+	}
+	if len(details) == 0 {
+		return models.OrderDetails{}, ErrNotFound
+	}
+	return details[0], nil
+}
+
+// memoryOrderRepository joins with the other in-memory stores the way the pipeline joins collections
+type memoryOrderRepository struct {
+	*memoryCrud[models.Order]
+	tables *memoryCrud[models.Table]
+	items  *memoryCrud[models.OrderItem]
+	foods  *memoryCrud[models.Food]
+}
+
+func (r *memoryOrderRepository) Details(ctx context.Context, orderID string) (models.OrderDetails, error) {
+	order, err := r.FindByID(ctx, orderID)
+	if err != nil {
+		return models.OrderDetails{}, err
+	}
+	details := models.OrderDetails{Order: order, Order_items: []models.OrderDetailItem{}, Payment_due: money.New(0, order.Total_amount.Currency)}
+	if table, err := r.tables.FindByID(ctx, order.Table_id); err == nil {
+		details.Table = &table
+	}
+
+	items := r.items.store.filter(func(item models.OrderItem) bool { return item.Order_id == orderID })
+	sort.SliceStable(items, func(i, j int) bool {
+		if !items[i].CreatedAt.Equal(items[j].CreatedAt) {
+			return items[i].CreatedAt.Before(items[j].CreatedAt)
+		}
+		return items[i].Order_item_id < items[j].Order_item_id
+	})
+	for _, item := range items {
+		// a deleted food leaves the name and image empty, like the unmatched lookup does
+		food, _ := r.foods.FindByID(ctx, item.Food_id)
+		details.Order_items = append(details.Order_items, models.OrderDetailItem{
+			Order_item_id: item.Order_item_id,
+			Food_id:       item.Food_id,
+			Name:          food.Name,
+			Image:         food.Image,
+			Quantity:      item.Quantity,
+			Portion:       item.Portion,
+			Modifiers:     item.Modifiers,
+			Notes:         item.Notes,
+			Unit_price:    item.Unit_price,
+			Total_amount:  item.Total_amount,
+		})
+		if details.Payment_due, err = details.Payment_due.Add(item.Total_amount); err != nil {
+			return models.OrderDetails{}, err
+		}
+	}
+	return details, nil
+}
@@ -0,0 +1,88 @@
+// Package repositories hides where the services keep their data behind one interface per aggregate
+package repositories
+
+import (
+	"errors"
+
+	"github.com/ShahSau/culinary-bliss/models"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// ErrNotFound is returned when no document matches the lookup
+var ErrNotFound = errors.New("not found")
+
+// ErrDuplicate is returned when a write would break a unique index
+var ErrDuplicate = errors.New("duplicate")
+
+// Repositories bundles every repository the services need
+type Repositories struct {
+	Users              UserRepository
+	Roles              RoleRepository
+	TokenFamilies      TokenFamilyRepository
+	RevokedTokens      RevokedTokenRepository
+	PasswordResets     OneTimeTokenRepository
+	EmailVerifications OneTimeTokenRepository
+	LoginAttempts      LoginAttemptRepository
+	AuditEvents        AuditEventRepository
+	ApiKeys            ApiKeyRepository
+	Restaurants        RestaurantRepository
+	Menus              MenuRepository
+	Foods              FoodRepository
+	Categories         CategoryRepository
+	Tables             TableRepository
+	Orders             OrderRepository
+	OrderItems         OrderItemRepository
+	Invoices           InvoiceRepository
+	Transactions       Transactor
+}
+
+// NewMongo returns repositories backed by the collections of the given database
+func NewMongo(db *mongo.Database) *Repositories {
+	return &Repositories{
+		Users:              &mongoUserRepository{collection: db.Collection("users")},
+		Roles:              &mongoRoleRepository{collection: db.Collection("roles")},
+		TokenFamilies:      &mongoTokenFamilyRepository{collection: db.Collection("token_families")},
+		RevokedTokens:      &mongoRevokedTokenRepository{collection: db.Collection("revoked_tokens")},
+		PasswordResets:     &mongoOneTimeTokenRepository{collection: db.Collection("password_resets")},
+		EmailVerifications: &mongoOneTimeTokenRepository{collection: db.Collection("email_verifications")},
+		LoginAttempts:      &mongoLoginAttemptRepository{collection: db.Collection("login_attempts")},
+		AuditEvents:        &mongoAuditEventRepository{collection: db.Collection("audit_events")},
+		ApiKeys:            &mongoApiKeyRepository{collection: db.Collection("api_keys")},
+		Restaurants:        &mongoCrud[models.Restaurant]{collection: db.Collection("restaurants"), idField: "restaurant_id", id: restaurantID},
+		Menus:              &mongoCrud[models.Menu]{collection: db.Collection("menu"), idField: "menu_id", id: menuID},
+		Foods:              &mongoCrud[models.Food]{collection: db.Collection("food"), idField: "food_id", id: foodID},
+		Categories:         &mongoCrud[models.Category]{collection: db.Collection("categories"), idField: "category_id", id: categoryID},
+		Tables:             &mongoCrud[models.Table]{collection: db.Collection("tables"), idField: "table_id", id: tableID},
+		Orders:             &mongoOrderRepository{&mongoCrud[models.Order]{collection: db.Collection("orders"), idField: "order_id", id: orderID}},
+		OrderItems:         &mongoOrderItemRepository{&mongoCrud[models.OrderItem]{collection: db.Collection("order_items"), idField: "order_item_id", id: orderItemID}},
+		Invoices:           &mongoCrud[models.Invoice]{collection: db.Collection("invoice"), idField: "invoice_id", id: invoiceID},
+		Transactions:       &mongoTransactor{client: db.Client()},
+	}
+}
+
+// NewMemory returns repositories that keep everything in memory, for tests
+func NewMemory() *Repositories {
+	tables := &memoryCrud[models.Table]{id: tableID}
+	orderItems := &memoryCrud[models.OrderItem]{id: orderItemID}
+	foods := &memoryCrud[models.Food]{id: foodID}
+	return &Repositories{
+		Users:              &memoryUserRepository{},
+		Roles:              &memoryRoleRepository{},
+		TokenFamilies:      &memoryTokenFamilyRepository{},
+		RevokedTokens:      &memoryRevokedTokenRepository{},
+		PasswordResets:     &memoryOneTimeTokenRepository{},
+		EmailVerifications: &memoryOneTimeTokenRepository{},
+		LoginAttempts:      &memoryLoginAttemptRepository{},
+		AuditEvents:        &memoryAuditEventRepository{},
+		ApiKeys:            &memoryApiKeyRepository{},
+		Restaurants:        &memoryCrud[models.Restaurant]{id: restaurantID},
+		Menus:              &memoryCrud[models.Menu]{id: menuID},
+		Foods:              foods,
+		Categories:         &memoryCrud[models.Category]{id: categoryID},
+		Tables:             tables,
+		Orders:             &memoryOrderRepository{memoryCrud: &memoryCrud[models.Order]{id: orderID}, tables: tables, items: orderItems, foods: foods},
+		OrderItems:         &memoryOrderItemRepository{orderItems},
+		Invoices:           &memoryCrud[models.Invoice]{id: invoiceID},
+		Transactions:       &memoryTransactor{},
+	}
+}
@@ -0,0 +1,21 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/ShahSau/culinary-bliss/listing"
+	"github.com/ShahSau/culinary-bliss/models"
+)
+
+// RestaurantRepository stores restaurants, keyed by restaurant_id
+type RestaurantRepository interface {
+	List(ctx context.Context, query listing.Query) (listing.Page[models.Restaurant], error)
+	FindByID(ctx context.Context, restaurantID string) (models.Restaurant, error)
+	Create(ctx context.Context, restaurant models.Restaurant) error
+	Update(ctx context.Context, restaurant models.Restaurant) error
+	Delete(ctx context.Context, restaurantID string) error
+}
+
+func restaurantID(restaurant models.Restaurant) string {
+	return restaurant.Restaurant_id
+}
@@ -0,0 +1,70 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/ShahSau/culinary-bliss/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// RevokedTokenRepository is the token denylist. Entries are forgotten once they expire.
+type RevokedTokenRepository interface {
+	// Add denylists a key. Adding a key again only moves its expiry.
+	Add(ctx context.Context, entry models.RevokedToken) error
+	// FindByKeys returns the entries of the given keys that have not expired
+	FindByKeys(ctx context.Context, keys []string) ([]models.RevokedToken, error)
+}
+
+type mongoRevokedTokenRepository struct {
+	collection *mongo.Collection
+}
+
+func (r *mongoRevokedTokenRepository) Add(ctx context.Context, entry models.RevokedToken) error {
+	update := bson.D{
+		{Key: "$set", Value: bson.D{{Key: "expires_at", Value: entry.ExpiresAt}}},
+		{Key: "$setOnInsert", Value: bson.D{{Key: "user_id", Value: entry.User_id}, {Key: "created_at", Value: entry.CreatedAt}}},
+	}
+
+	_, err := r.collection.UpdateOne(ctx, bson.M{"key": entry.Key}, update, options.Update().SetUpsert(true))
+	return err
+}
+
+func (r *mongoRevokedTokenRepository) FindByKeys(ctx context.Context, keys []string) ([]models.RevokedToken, error) {
+	// the TTL monitor only runs once a minute
+	filter := bson.M{"key": bson.M{"$in": keys}, "expires_at": bson.M{"$gt": time.Now()}}
+	return findAll[models.RevokedToken](ctx, r.collection, filter)
+}
+
+type memoryRevokedTokenRepository struct {
+	store memoryStore[models.RevokedToken]
+}
+
+func (r *memoryRevokedTokenRepository) Add(ctx context.Context, entry models.RevokedToken) error {
+	_, err := r.store.update(func(stored models.RevokedToken) bool { return stored.Key == entry.Key }, func(stored *models.RevokedToken) {
+		stored.ExpiresAt = entry.ExpiresAt
+	})
+	if err == ErrNotFound {
+		r.store.insert(entry)
+		return nil
+	}
+	return err
+}
+
+func (r *memoryRevokedTokenRepository) FindByKeys(ctx context.Context, keys []string) ([]models.RevokedToken, error) {
+	now := time.Now()
+	return r.store.filter(func(entry models.RevokedToken) bool {
+		return entry.ExpiresAt.After(now) && contains(keys, entry.Key)
+	}), nil
+}
+
+func contains(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
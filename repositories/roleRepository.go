@@ -0,0 +1,51 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/ShahSau/culinary-bliss/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// RoleRepository stores the permissions configured for a role, keyed by name. Roles without a document use the defaults.
+type RoleRepository interface {
+	FindByName(ctx context.Context, name string) (models.Role, error)
+	// Save creates or updates the role and returns it as stored. CreatedAt is only written when the role is created.
+	Save(ctx context.Context, role models.Role) (models.Role, error)
+}
+
+type mongoRoleRepository struct {
+	collection *mongo.Collection
+}
+
+func (r *mongoRoleRepository) FindByName(ctx context.Context, name string) (models.Role, error) {
+	return findOne[models.Role](ctx, r.collection, bson.M{"name": name})
+}
+
+func (r *mongoRoleRepository) Save(ctx context.Context, role models.Role) (models.Role, error) {
+	update := bson.D{
+		{Key: "$set", Value: bson.D{{Key: "permissions", Value: role.Permissions}, {Key: "mfa_required", Value: role.Mfa_required}, {Key: "updated_at", Value: role.UpdatedAt}}},
+		{Key: "$setOnInsert", Value: bson.D{{Key: "name", Value: role.Name}, {Key: "created_at", Value: role.CreatedAt}}},
+	}
+	return findOneAndUpdate[models.Role](ctx, r.collection, bson.M{"name": role.Name}, update, true)
+}
+
+type memoryRoleRepository struct {
+	store memoryStore[models.Role]
+}
+
+func (r *memoryRoleRepository) FindByName(ctx context.Context, name string) (models.Role, error) {
+	return r.store.find(func(role models.Role) bool { return role.Name == name })
+}
+
+func (r *memoryRoleRepository) Save(ctx context.Context, role models.Role) (models.Role, error) {
+	updated, err := r.store.update(func(stored models.Role) bool { return stored.Name == role.Name }, func(stored *models.Role) {
+		stored.Permissions, stored.Mfa_required, stored.UpdatedAt = role.Permissions, role.Mfa_required, role.UpdatedAt
+	})
+	if err == ErrNotFound {
+		r.store.insert(role)
+		return role, nil
+	}
+	return updated, err
+}
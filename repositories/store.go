@@ -0,0 +1,240 @@
+package repositories
+
+import (
+	"context"
+	"sync"
+
+	"github.com/ShahSau/culinary-bliss/listing"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// findOne decodes the first matching document, turning mongo.ErrNoDocuments into ErrNotFound
+func findOne[T any](ctx context.Context, collection *mongo.Collection, filter interface{}, opts ...*options.FindOneOptions) (T, error) {
+	var document T
+	err := collection.FindOne(ctx, filter, opts...).Decode(&document)
+	if err == mongo.ErrNoDocuments {
+		return document, ErrNotFound
+	}
+	return document, err
+}
+
+// findAll decodes every matching document. The result is never nil so it encodes as an empty list.
+func findAll[T any](ctx context.Context, collection *mongo.Collection, filter interface{}, opts ...*options.FindOptions) ([]T, error) {
+	cursor, err := collection.Find(ctx, filter, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	documents := []T{}
+	if err = cursor.All(ctx, &documents); err != nil {
+		return nil, err
+	}
+	return documents, nil
+}
+
+// findOneAndUpdate applies the update and decodes the document as it is afterwards
+func findOneAndUpdate[T any](ctx context.Context, collection *mongo.Collection, filter interface{}, update interface{}, upsert bool) (T, error) {
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After).SetUpsert(upsert)
+
+	var document T
+	err := collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&document)
+	if err == mongo.ErrNoDocuments {
+		return document, ErrNotFound
+	}
+	return document, err
+}
+
+// updateOne applies the update to the first matching document and returns ErrNotFound if there was none
+func updateOne(ctx context.Context, collection *mongo.Collection, filter interface{}, update interface{}) error {
+	result, err := collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// deleteOne removes the first matching document and returns ErrNotFound if there was none
+func deleteOne(ctx context.Context, collection *mongo.Collection, filter interface{}) error {
+	result, err := collection.DeleteOne(ctx, filter)
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// list returns the page of documents a listing query asks for
+func list[T any](ctx context.Context, collection *mongo.Collection, query listing.Query) (listing.Page[T], error) {
+	filter := listFilter(query.Filters)
+	total, err := collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return listing.Page[T]{}, err
+	}
+
+	if query.After != nil {
+		filter = bson.D{{Key: "$and", Value: bson.A{filter, afterFilter(query.Sort, query.After)}}}
+	}
+	// one document more than asked for tells whether there is a next page
+	opts := options.Find().SetSort(listSort(query.Sort)).SetLimit(int64(query.Limit + 1))
+	documents, err := findAll[T](ctx, collection, filter, opts)
+	if err != nil {
+		return listing.Page[T]{}, err
+	}
+	return listing.NewPage(query, documents, total), nil
+}
+
+func listFilter(filters []listing.Filter) bson.D {
+	filter := bson.D{}
+	for _, f := range filters {
+		value := f.Value
+		if values, ok := value.([]interface{}); ok {
+			value = bson.A(values)
+		}
+		filter = append(filter, bson.E{Key: f.Field, Value: bson.D{{Key: "$" + string(f.Op), Value: value}}})
+	}
+	return filter
+}
+
+func listSort(sorts []listing.Sort) bson.D {
+	sort := bson.D{}
+	for _, s := range sorts {
+		direction := 1
+		if s.Desc {
+			direction = -1
+		}
+		sort = append(sort, bson.E{Key: s.Field, Value: direction})
+	}
+	return sort
+}
+
+// afterFilter matches the documents that come after the given sort values: those equal on the first fields and past the next one.
+// Mongo sorts a null or missing value before every other value, but $gt and $lt never match it, so nulls get clauses of their own.
+func afterFilter(sorts []listing.Sort, after []interface{}) bson.D {
+	var or bson.A
+	for i, s := range sorts {
+		clause := bson.D{}
+		for j := 0; j < i; j++ {
+			clause = append(clause, bson.E{Key: sorts[j].Field, Value: after[j]})
+		}
+		switch {
+		case after[i] == nil && s.Desc:
+			// nulls come last in descending order, nothing is past them
+			continue
+		case after[i] == nil:
+			clause = append(clause, bson.E{Key: s.Field, Value: bson.D{{Key: "$ne", Value: nil}}})
+		case s.Desc:
+			clause = append(clause, bson.E{Key: "$or", Value: bson.A{
+				bson.D{{Key: s.Field, Value: bson.D{{Key: "$lt", Value: after[i]}}}},
+				bson.D{{Key: s.Field, Value: nil}},
+			}})
+		default:
+			clause = append(clause, bson.E{Key: s.Field, Value: bson.D{{Key: "$gt", Value: after[i]}}})
+		}
+		or = append(or, clause)
+	}
+	if len(or) == 0 {
+		// every document has an _id, so this matches none
+		return bson.D{{Key: "_id", Value: bson.D{{Key: "$exists", Value: false}}}}
+	}
+	return bson.D{{Key: "$or", Value: or}}
+}
+
+// memoryStore is the in-memory stand-in for a collection. Documents are kept in insertion order.
+type memoryStore[T any] struct {
+	mu        sync.RWMutex
+	documents []T
+}
+
+func (s *memoryStore[T]) insert(document T) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.documents = append(s.documents, document)
+}
+
+// insertUnique adds the document unless an existing one conflicts with it, the way a unique index would
+func (s *memoryStore[T]) insertUnique(document T, conflicts func(T) bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, existing := range s.documents {
+		if conflicts(existing) {
+			return ErrDuplicate
+		}
+	}
+	s.documents = append(s.documents, document)
+	return nil
+}
+
+func (s *memoryStore[T]) find(match func(T) bool) (T, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, document := range s.documents {
+		if match(document) {
+			return document, nil
+		}
+	}
+	var zero T
+	return zero, ErrNotFound
+}
+
+func (s *memoryStore[T]) filter(match func(T) bool) []T {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	documents := []T{}
+	for _, document := range s.documents {
+		if match(document) {
+			documents = append(documents, document)
+		}
+	}
+	return documents
+}
+
+func (s *memoryStore[T]) list(query listing.Query) listing.Page[T] {
+	return listing.Apply(s.filter(func(T) bool { return true }), query)
+}
+
+// update applies change to every matching document and returns the last one changed, or ErrNotFound
+func (s *memoryStore[T]) update(match func(T) bool, change func(*T)) (T, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var updated T
+	found := false
+	for i := range s.documents {
+		if match(s.documents[i]) {
+			change(&s.documents[i])
+			updated, found = s.documents[i], true
+		}
+	}
+	if !found {
+		return updated, ErrNotFound
+	}
+	return updated, nil
+}
+
+// remove deletes every matching document and returns ErrNotFound if there was none
+func (s *memoryStore[T]) remove(match func(T) bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	kept := s.documents[:0]
+	for _, document := range s.documents {
+		if !match(document) {
+			kept = append(kept, document)
+		}
+	}
+	removed := len(kept) != len(s.documents)
+	var zero T
+	for i := len(kept); i < len(s.documents); i++ {
+		s.documents[i] = zero
+	}
+	s.documents = kept
+	if !removed {
+		return ErrNotFound
+	}
+	return nil
+}
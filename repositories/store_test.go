@@ -0,0 +1,64 @@
+package repositories
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ShahSau/culinary-bliss/listing"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestAfterFilterKeepsNullSortValues(t *testing.T) {
+	id := listing.Sort{Field: "order_id", Kind: listing.String}
+	tests := []struct {
+		name  string
+		sorts []listing.Sort
+		after []interface{}
+		want  bson.D
+	}{
+		{
+			name:  "ascending past a value skips the nulls before it",
+			sorts: []listing.Sort{{Field: "total", Kind: listing.Number}, id},
+			after: []interface{}{5.0, "o1"},
+			want: bson.D{{Key: "$or", Value: bson.A{
+				bson.D{{Key: "total", Value: bson.D{{Key: "$gt", Value: 5.0}}}},
+				bson.D{{Key: "total", Value: 5.0}, {Key: "order_id", Value: bson.D{{Key: "$gt", Value: "o1"}}}},
+			}}},
+		},
+		{
+			name:  "ascending past a null continues with every value",
+			sorts: []listing.Sort{{Field: "total", Kind: listing.Number}, id},
+			after: []interface{}{nil, "o1"},
+			want: bson.D{{Key: "$or", Value: bson.A{
+				bson.D{{Key: "total", Value: bson.D{{Key: "$ne", Value: nil}}}},
+				bson.D{{Key: "total", Value: nil}, {Key: "order_id", Value: bson.D{{Key: "$gt", Value: "o1"}}}},
+			}}},
+		},
+		{
+			name:  "descending past a value keeps the nulls after it",
+			sorts: []listing.Sort{{Field: "total", Kind: listing.Number, Desc: true}, id},
+			after: []interface{}{5.0, "o1"},
+			want: bson.D{{Key: "$or", Value: bson.A{
+				bson.D{{Key: "$or", Value: bson.A{
+					bson.D{{Key: "total", Value: bson.D{{Key: "$lt", Value: 5.0}}}},
+					bson.D{{Key: "total", Value: nil}},
+				}}},
+				bson.D{{Key: "total", Value: 5.0}, {Key: "order_id", Value: bson.D{{Key: "$gt", Value: "o1"}}}},
+			}}},
+		},
+		{
+			name:  "descending past a null only continues among the nulls",
+			sorts: []listing.Sort{{Field: "total", Kind: listing.Number, Desc: true}, id},
+			after: []interface{}{nil, "o1"},
+			want: bson.D{{Key: "$or", Value: bson.A{
+				bson.D{{Key: "total", Value: nil}, {Key: "order_id", Value: bson.D{{Key: "$gt", Value: "o1"}}}},
+			}}},
+		},
+	}
+
+	for _, test := range tests {
+		if got := afterFilter(test.sorts, test.after); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s:\nwant %v\n got %v", test.name, test.want, got)
+		}
+	}
+}
@@ -0,0 +1,21 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/ShahSau/culinary-bliss/listing"
+	"github.com/ShahSau/culinary-bliss/models"
+)
+
+// TableRepository stores tables, keyed by table_id
+type TableRepository interface {
+	List(ctx context.Context, query listing.Query) (listing.Page[models.Table], error)
+	FindByID(ctx context.Context, tableID string) (models.Table, error)
+	Create(ctx context.Context, table models.Table) error
+	Update(ctx context.Context, table models.Table) error
+	Delete(ctx context.Context, tableID string) error
+}
+
+func tableID(table models.Table) string {
+	return table.Table_id
+}
@@ -0,0 +1,104 @@
+package repositories
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/ShahSau/culinary-bliss/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// TokenFamilyRepository stores refresh token families, keyed by family_id
+type TokenFamilyRepository interface {
+	Create(ctx context.Context, family models.TokenFamily) error
+	FindByID(ctx context.Context, familyID string) (models.TokenFamily, error)
+	// ListUnrevoked returns the families of a user that were not revoked, most recently used first
+	ListUnrevoked(ctx context.Context, userID string) ([]models.TokenFamily, error)
+	// Rotate replaces the refresh token hash and client details of a family, but only while previousHash is still its current hash
+	// and it was not revoked. It reports false if another rotation or a revocation got there first.
+	Rotate(ctx context.Context, family models.TokenFamily, previousHash string) (bool, error)
+	Revoke(ctx context.Context, familyID string, at time.Time) error
+}
+
+type mongoTokenFamilyRepository struct {
+	collection *mongo.Collection
+}
+
+func (r *mongoTokenFamilyRepository) Create(ctx context.Context, family models.TokenFamily) error {
+	_, err := r.collection.InsertOne(ctx, family)
+	return err
+}
+
+func (r *mongoTokenFamilyRepository) FindByID(ctx context.Context, familyID string) (models.TokenFamily, error) {
+	return findOne[models.TokenFamily](ctx, r.collection, bson.M{"family_id": familyID})
+}
+
+func (r *mongoTokenFamilyRepository) ListUnrevoked(ctx context.Context, userID string) ([]models.TokenFamily, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "updated_at", Value: -1}})
+	return findAll[models.TokenFamily](ctx, r.collection, bson.M{"user_id": userID, "revoked": false}, opts)
+}
+
+func (r *mongoTokenFamilyRepository) Rotate(ctx context.Context, family models.TokenFamily, previousHash string) (bool, error) {
+	filter := bson.M{"family_id": family.Family_id, "refresh_token_hash": previousHash, "revoked": false}
+	update := bson.D{{Key: "$set", Value: bson.D{
+		{Key: "refresh_token_hash", Value: family.Refresh_token_hash},
+		{Key: "user_agent", Value: family.User_agent},
+		{Key: "ip_address", Value: family.Ip_address},
+		{Key: "expires_at", Value: family.ExpiresAt},
+		{Key: "updated_at", Value: family.UpdatedAt},
+	}}}
+
+	err := updateOne(ctx, r.collection, filter, update)
+	if err == ErrNotFound {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (r *mongoTokenFamilyRepository) Revoke(ctx context.Context, familyID string, at time.Time) error {
+	update := bson.D{{Key: "$set", Value: bson.D{{Key: "revoked", Value: true}, {Key: "updated_at", Value: at}}}}
+	return updateOne(ctx, r.collection, bson.M{"family_id": familyID}, update)
+}
+
+type memoryTokenFamilyRepository struct {
+	store memoryStore[models.TokenFamily]
+}
+
+func (r *memoryTokenFamilyRepository) Create(ctx context.Context, family models.TokenFamily) error {
+	r.store.insert(family)
+	return nil
+}
+
+func (r *memoryTokenFamilyRepository) FindByID(ctx context.Context, familyID string) (models.TokenFamily, error) {
+	return r.store.find(func(family models.TokenFamily) bool { return family.Family_id == familyID })
+}
+
+func (r *memoryTokenFamilyRepository) ListUnrevoked(ctx context.Context, userID string) ([]models.TokenFamily, error) {
+	families := r.store.filter(func(family models.TokenFamily) bool { return family.User_id == userID && !family.Revoked })
+	sort.SliceStable(families, func(i, j int) bool { return families[i].UpdatedAt.After(families[j].UpdatedAt) })
+	return families, nil
+}
+
+func (r *memoryTokenFamilyRepository) Rotate(ctx context.Context, family models.TokenFamily, previousHash string) (bool, error) {
+	current := func(stored models.TokenFamily) bool {
+		return stored.Family_id == family.Family_id && stored.Refresh_token_hash == previousHash && !stored.Revoked
+	}
+	_, err := r.store.update(current, func(stored *models.TokenFamily) {
+		stored.Refresh_token_hash, stored.User_agent, stored.Ip_address = family.Refresh_token_hash, family.User_agent, family.Ip_address
+		stored.ExpiresAt, stored.UpdatedAt = family.ExpiresAt, family.UpdatedAt
+	})
+	if err == ErrNotFound {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (r *memoryTokenFamilyRepository) Revoke(ctx context.Context, familyID string, at time.Time) error {
+	_, err := r.store.update(func(family models.TokenFamily) bool { return family.Family_id == familyID }, func(family *models.TokenFamily) {
+		family.Revoked, family.UpdatedAt = true, at
+	})
+	return err
+}
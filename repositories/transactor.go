@@ -0,0 +1,48 @@
+package repositories
+
+import (
+	"context"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readconcern"
+	"go.mongodb.org/mongo-driver/mongo/writeconcern"
+)
+
+// Transactor runs a function so that the writes it makes through the repositories are stored together or not at all.
+// The function must use the context it is given and may run more than once, so it should not have other side effects.
+type Transactor interface {
+	InTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// mongoTransactor runs functions in a Mongo transaction, which needs a replica set.
+// The driver retries the whole function on TransientTransactionError and the commit on UnknownTransactionCommitResult.
+type mongoTransactor struct {
+	client *mongo.Client
+}
+
+func (t *mongoTransactor) InTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	session, err := t.client.StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	opts := options.Transaction().SetReadConcern(readconcern.Snapshot()).SetWriteConcern(writeconcern.Majority())
+	_, err = session.WithTransaction(ctx, func(sessionCtx mongo.SessionContext) (interface{}, error) {
+		return nil, fn(sessionCtx)
+	}, opts)
+	return err
+}
+
+// memoryTransactor runs one function at a time. It does not roll back, callers check everything before they write.
+type memoryTransactor struct {
+	mu sync.Mutex
+}
+
+func (t *memoryTransactor) InTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return fn(ctx)
+}
@@ -0,0 +1,258 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/ShahSau/culinary-bliss/listing"
+	"github.com/ShahSau/culinary-bliss/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// UserRepository stores user accounts, keyed by user_id
+type UserRepository interface {
+	List(ctx context.Context, query listing.Query) (listing.Page[models.User], error)
+	FindByID(ctx context.Context, userID string) (models.User, error)
+	FindByEmail(ctx context.Context, email string) (models.User, error)
+	ExistsByEmail(ctx context.Context, email string) (bool, error)
+	ExistsByPhone(ctx context.Context, phone string) (bool, error)
+	Create(ctx context.Context, user models.User) error
+	Update(ctx context.Context, user models.User) error
+	Delete(ctx context.Context, userID string) error
+
+	SetPassword(ctx context.Context, userID string, passwordHash string, at time.Time) error
+	SetRole(ctx context.Context, userID string, role string, at time.Time) (models.User, error)
+	Activate(ctx context.Context, userID string, status string, at time.Time) (models.User, error)
+
+	SetMfaPendingSecret(ctx context.Context, userID string, secret string, at time.Time) error
+	EnableMfa(ctx context.Context, userID string, secret string, recoveryCodeHashes []string, at time.Time) error
+	DisableMfa(ctx context.Context, userID string, at time.Time) error
+	// AdvanceMfaStep records the TOTP time step a code was used for. It reports false if that step or a later one was already used.
+	AdvanceMfaStep(ctx context.Context, userID string, step int64) (bool, error)
+	// UseRecoveryCode removes a recovery code. It reports false if the user does not have it.
+	UseRecoveryCode(ctx context.Context, userID string, codeHash string) (bool, error)
+}
+
+type mongoUserRepository struct {
+	collection *mongo.Collection
+}
+
+func (r *mongoUserRepository) List(ctx context.Context, query listing.Query) (listing.Page[models.User], error) {
+	return list[models.User](ctx, r.collection, query)
+}
+
+func (r *mongoUserRepository) FindByID(ctx context.Context, userID string) (models.User, error) {
+	return findOne[models.User](ctx, r.collection, bson.M{"user_id": userID})
+}
+
+func (r *mongoUserRepository) FindByEmail(ctx context.Context, email string) (models.User, error) {
+	return findOne[models.User](ctx, r.collection, bson.M{"email": email})
+}
+
+func (r *mongoUserRepository) ExistsByEmail(ctx context.Context, email string) (bool, error) {
+	count, err := r.collection.CountDocuments(ctx, bson.M{"email": email}, options.Count().SetLimit(1))
+	return count > 0, err
+}
+
+func (r *mongoUserRepository) ExistsByPhone(ctx context.Context, phone string) (bool, error) {
+	count, err := r.collection.CountDocuments(ctx, bson.M{"phone": phone}, options.Count().SetLimit(1))
+	return count > 0, err
+}
+
+func (r *mongoUserRepository) Create(ctx context.Context, user models.User) error {
+	_, err := r.collection.InsertOne(ctx, user)
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrDuplicate
+	}
+	return err
+}
+
+func (r *mongoUserRepository) Update(ctx context.Context, user models.User) error {
+	return r.set(ctx, user.User_id, user)
+}
+
+func (r *mongoUserRepository) Delete(ctx context.Context, userID string) error {
+	return deleteOne(ctx, r.collection, bson.M{"user_id": userID})
+}
+
+func (r *mongoUserRepository) SetPassword(ctx context.Context, userID string, passwordHash string, at time.Time) error {
+	return r.set(ctx, userID, bson.D{{Key: "password", Value: passwordHash}, {Key: "updated_at", Value: at}})
+}
+
+func (r *mongoUserRepository) SetRole(ctx context.Context, userID string, role string, at time.Time) (models.User, error) {
+	update := bson.D{{Key: "$set", Value: bson.D{{Key: "role", Value: role}, {Key: "updated_at", Value: at}}}}
+	return findOneAndUpdate[models.User](ctx, r.collection, bson.M{"user_id": userID}, update, false)
+}
+
+func (r *mongoUserRepository) Activate(ctx context.Context, userID string, status string, at time.Time) (models.User, error) {
+	update := bson.D{{Key: "$set", Value: bson.D{{Key: "status", Value: status}, {Key: "verified_at", Value: at}, {Key: "updated_at", Value: at}}}}
+	return findOneAndUpdate[models.User](ctx, r.collection, bson.M{"user_id": userID}, update, false)
+}
+
+func (r *mongoUserRepository) SetMfaPendingSecret(ctx context.Context, userID string, secret string, at time.Time) error {
+	return r.set(ctx, userID, bson.D{{Key: "mfa_pending_secret", Value: secret}, {Key: "updated_at", Value: at}})
+}
+
+func (r *mongoUserRepository) EnableMfa(ctx context.Context, userID string, secret string, recoveryCodeHashes []string, at time.Time) error {
+	update := bson.D{
+		{Key: "$set", Value: bson.D{
+			{Key: "mfa_enabled", Value: true},
+			{Key: "mfa_secret", Value: secret},
+			{Key: "mfa_recovery_codes", Value: recoveryCodeHashes},
+			{Key: "updated_at", Value: at},
+		}},
+		{Key: "$unset", Value: bson.D{{Key: "mfa_pending_secret", Value: ""}}},
+	}
+	return updateOne(ctx, r.collection, bson.M{"user_id": userID}, update)
+}
+
+func (r *mongoUserRepository) DisableMfa(ctx context.Context, userID string, at time.Time) error {
+	update := bson.D{
+		{Key: "$set", Value: bson.D{{Key: "mfa_enabled", Value: false}, {Key: "updated_at", Value: at}}},
+		{Key: "$unset", Value: bson.D{{Key: "mfa_secret", Value: ""}, {Key: "mfa_pending_secret", Value: ""}, {Key: "mfa_recovery_codes", Value: ""}, {Key: "mfa_last_step", Value: ""}}},
+	}
+	return updateOne(ctx, r.collection, bson.M{"user_id": userID}, update)
+}
+
+func (r *mongoUserRepository) AdvanceMfaStep(ctx context.Context, userID string, step int64) (bool, error) {
+	filter := bson.M{"user_id": userID, "$or": bson.A{
+		bson.M{"mfa_last_step": bson.M{"$lt": step}},
+		bson.M{"mfa_last_step": bson.M{"$exists": false}},
+	}}
+	result, err := r.collection.UpdateOne(ctx, filter, bson.D{{Key: "$set", Value: bson.D{{Key: "mfa_last_step", Value: step}}}})
+	if err != nil {
+		return false, err
+	}
+	return result.ModifiedCount > 0, nil
+}
+
+func (r *mongoUserRepository) UseRecoveryCode(ctx context.Context, userID string, codeHash string) (bool, error) {
+	filter := bson.M{"user_id": userID, "mfa_recovery_codes": codeHash}
+	result, err := r.collection.UpdateOne(ctx, filter, bson.D{{Key: "$pull", Value: bson.D{{Key: "mfa_recovery_codes", Value: codeHash}}}})
+	if err != nil {
+		return false, err
+	}
+	return result.ModifiedCount > 0, nil
+}
+
+func (r *mongoUserRepository) set(ctx context.Context, userID string, fields interface{}) error {
+	return updateOne(ctx, r.collection, bson.M{"user_id": userID}, bson.D{{Key: "$set", Value: fields}})
+}
+
+type memoryUserRepository struct {
+	store memoryStore[models.User]
+}
+
+func (r *memoryUserRepository) byID(userID string) func(models.User) bool {
+	return func(user models.User) bool { return user.User_id == userID }
+}
+
+func (r *memoryUserRepository) List(ctx context.Context, query listing.Query) (listing.Page[models.User], error) {
+	return r.store.list(query), nil
+}
+
+func (r *memoryUserRepository) FindByID(ctx context.Context, userID string) (models.User, error) {
+	return r.store.find(r.byID(userID))
+}
+
+func (r *memoryUserRepository) FindByEmail(ctx context.Context, email string) (models.User, error) {
+	return r.store.find(func(user models.User) bool { return user.Email == email })
+}
+
+func (r *memoryUserRepository) ExistsByEmail(ctx context.Context, email string) (bool, error) {
+	_, err := r.FindByEmail(ctx, email)
+	return err == nil, nil
+}
+
+func (r *memoryUserRepository) ExistsByPhone(ctx context.Context, phone string) (bool, error) {
+	_, err := r.store.find(func(user models.User) bool { return user.Phone == phone })
+	return err == nil, nil
+}
+
+func (r *memoryUserRepository) Create(ctx context.Context, user models.User) error {
+	return r.store.insertUnique(user, func(existing models.User) bool {
+		return existing.User_id == user.User_id || existing.Email == user.Email || existing.Phone == user.Phone
+	})
+}
+
+func (r *memoryUserRepository) Update(ctx context.Context, user models.User) error {
+	_, err := r.store.update(r.byID(user.User_id), func(stored *models.User) { *stored = user })
+	return err
+}
+
+func (r *memoryUserRepository) Delete(ctx context.Context, userID string) error {
+	return r.store.remove(r.byID(userID))
+}
+
+func (r *memoryUserRepository) SetPassword(ctx context.Context, userID string, passwordHash string, at time.Time) error {
+	_, err := r.store.update(r.byID(userID), func(user *models.User) {
+		user.Password, user.UpdatedAt = passwordHash, at
+	})
+	return err
+}
+
+func (r *memoryUserRepository) SetRole(ctx context.Context, userID string, role string, at time.Time) (models.User, error) {
+	return r.store.update(r.byID(userID), func(user *models.User) {
+		user.Role, user.UpdatedAt = role, at
+	})
+}
+
+func (r *memoryUserRepository) Activate(ctx context.Context, userID string, status string, at time.Time) (models.User, error) {
+	return r.store.update(r.byID(userID), func(user *models.User) {
+		verifiedAt := at
+		user.Status, user.VerifiedAt, user.UpdatedAt = status, &verifiedAt, at
+	})
+}
+
+func (r *memoryUserRepository) SetMfaPendingSecret(ctx context.Context, userID string, secret string, at time.Time) error {
+	_, err := r.store.update(r.byID(userID), func(user *models.User) {
+		user.Mfa_pending_secret, user.UpdatedAt = secret, at
+	})
+	return err
+}
+
+func (r *memoryUserRepository) EnableMfa(ctx context.Context, userID string, secret string, recoveryCodeHashes []string, at time.Time) error {
+	_, err := r.store.update(r.byID(userID), func(user *models.User) {
+		user.Mfa_enabled, user.Mfa_secret, user.Mfa_pending_secret, user.UpdatedAt = true, secret, "", at
+		user.Mfa_recovery_codes = append([]string(nil), recoveryCodeHashes...)
+	})
+	return err
+}
+
+func (r *memoryUserRepository) DisableMfa(ctx context.Context, userID string, at time.Time) error {
+	_, err := r.store.update(r.byID(userID), func(user *models.User) {
+		user.Mfa_enabled, user.Mfa_secret, user.Mfa_pending_secret, user.Mfa_recovery_codes, user.Mfa_last_step = false, "", "", nil, 0
+		user.UpdatedAt = at
+	})
+	return err
+}
+
+func (r *memoryUserRepository) AdvanceMfaStep(ctx context.Context, userID string, step int64) (bool, error) {
+	_, err := r.store.update(func(user models.User) bool { return user.User_id == userID && user.Mfa_last_step < step }, func(user *models.User) {
+		user.Mfa_last_step = step
+	})
+	if err == ErrNotFound {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (r *memoryUserRepository) UseRecoveryCode(ctx context.Context, userID string, codeHash string) (bool, error) {
+	_, err := r.store.update(func(user models.User) bool {
+		return user.User_id == userID && contains(user.Mfa_recovery_codes, codeHash)
+	}, func(user *models.User) {
+		var remaining []string
+		for _, code := range user.Mfa_recovery_codes {
+			if code != codeHash {
+				remaining = append(remaining, code)
+			}
+		}
+		user.Mfa_recovery_codes = remaining
+	})
+	if err == ErrNotFound {
+		return false, nil
+	}
+	return err == nil, err
+}
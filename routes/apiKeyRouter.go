@@ -0,0 +1,14 @@
+package routes
+
+import (
+	"github.com/ShahSau/culinary-bliss/controllers"
+	"github.com/ShahSau/culinary-bliss/helpers"
+	"github.com/ShahSau/culinary-bliss/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+func ApiKeyRoutes(c *gin.Engine, ctl *controllers.Controller) {
+	c.POST("/api-keys", middleware.RequirePermission(helpers.PermManageApiKeys), ctl.CreateApiKey)
+	c.GET("/api-keys", middleware.RequirePermission(helpers.PermManageApiKeys), ctl.GetApiKeys)
+	c.DELETE("/api-keys/:id", middleware.RequirePermission(helpers.PermManageApiKeys), ctl.RevokeApiKey)
+}
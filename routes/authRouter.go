@@ -5,8 +5,14 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func AuthRoutes(c *gin.Engine) {
-	c.POST("/login", controllers.Login)
-	c.POST("/register", controllers.Register)
-	c.POST("/logout", controllers.Logout)
+func AuthRoutes(c *gin.Engine, ctl *controllers.Controller, auth gin.HandlerFunc) {
+	c.POST("/login", ctl.Login)
+	c.POST("/login/mfa", ctl.LoginMfa)
+	c.POST("/login/mfa/enroll", ctl.LoginMfaEnroll)
+	c.POST("/register", ctl.Register)
+	c.POST("/logout", auth, ctl.Logout)
+	c.POST("/token/refresh", ctl.RefreshToken)
+	c.POST("/password/forgot", ctl.ForgotPassword)
+	c.POST("/password/reset", ctl.ResetPasswordWithToken)
+	c.POST("/verify-email", ctl.VerifyEmail)
 }
@@ -2,13 +2,15 @@ package routes
 
 import (
 	"github.com/ShahSau/culinary-bliss/controllers"
+	"github.com/ShahSau/culinary-bliss/helpers"
+	"github.com/ShahSau/culinary-bliss/middleware"
 	"github.com/gin-gonic/gin"
 )
 
-func CatgeoryRoutes(c *gin.Engine) {
-	c.GET("/categeory/:id", controllers.GetCategoryByID)
-	c.POST("/categeory", controllers.CreateCategory)
-	c.PUT("/categeory/:id", controllers.UpdateCategory)
-	c.DELETE("/categeory/:id", controllers.DeleteCategory)
+func CatgeoryRoutes(c *gin.Engine, ctl *controllers.Controller) {
+	c.GET("/categeory/:id", ctl.GetCategoryByID)
+	c.POST("/categeory", middleware.RequirePermission(helpers.PermManageCategories), ctl.CreateCategory)
+	c.PUT("/categeory/:id", middleware.RequirePermission(helpers.PermManageCategories), ctl.UpdateCategory)
+	c.DELETE("/categeory/:id", middleware.RequirePermission(helpers.PermManageCategories), ctl.DeleteCategory)
 
 }
@@ -2,11 +2,13 @@ package routes
 
 import (
 	"github.com/ShahSau/culinary-bliss/controllers"
+	"github.com/ShahSau/culinary-bliss/helpers"
+	"github.com/ShahSau/culinary-bliss/middleware"
 	"github.com/gin-gonic/gin"
 )
 
-func FoodRoutes(c *gin.Engine) {
-	c.POST("/food", controllers.CreateFood)
-	c.PUT("/food/:id", controllers.UpdateFood)
-	c.DELETE("/food/:id", controllers.DeleteFood)
+func FoodRoutes(c *gin.Engine, ctl *controllers.Controller) {
+	c.POST("/food", middleware.RequirePermission(helpers.PermManageFood), ctl.CreateFood)
+	c.PUT("/food/:id", middleware.RequirePermission(helpers.PermManageFood), ctl.UpdateFood)
+	c.DELETE("/food/:id", middleware.RequirePermission(helpers.PermManageFood), ctl.DeleteFood)
 }
@@ -5,15 +5,16 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func GlobalRoutes(c *gin.Engine) {
-	c.GET("/categories", controllers.GetCategories)
-	c.GET("/table", controllers.GetTables)
-	c.GET("/table/:id", controllers.GetTable)
-	c.GET("/menu", controllers.GetMenus)
-	c.GET("/menu/:id", controllers.GetMenu)
-	c.GET("/restaurants", controllers.GetRestaurants)
-	c.GET("/restaurants/:id", controllers.GetRestaurant)
-	c.GET("/restaurants/menus/:id", controllers.MenuByRestaurant)
-	c.GET("/foods", controllers.GetFoods)
-	c.GET("/food/:id", controllers.GetFood)
+func GlobalRoutes(c *gin.Engine, ctl *controllers.Controller) {
+	c.GET("/categories", ctl.GetCategories)
+	c.GET("/table", ctl.GetTables)
+	c.GET("/table/:id", ctl.GetTable)
+	c.GET("/menu", ctl.GetMenus)
+	c.GET("/menu/:id", ctl.GetMenu)
+	c.GET("/restaurants", ctl.GetRestaurants)
+	c.GET("/restaurants/:id", ctl.GetRestaurant)
+	c.GET("/restaurants/menus/:id", ctl.MenuByRestaurant)
+	c.GET("/foods", ctl.GetFoods)
+	c.GET("/food/:id", ctl.GetFood)
+	c.GET("/.well-known/jwks.json", controllers.GetJWKS)
 }
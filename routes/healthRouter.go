@@ -0,0 +1,11 @@
+package routes
+
+import (
+	"github.com/ShahSau/culinary-bliss/controllers"
+	"github.com/gin-gonic/gin"
+)
+
+func HealthRoutes(c *gin.Engine, health *controllers.Health) {
+	c.GET("/healthz", health.Healthz)
+	c.GET("/readyz", health.Readyz)
+}
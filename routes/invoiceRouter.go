@@ -2,13 +2,15 @@ package routes
 
 import (
 	"github.com/ShahSau/culinary-bliss/controllers"
+	"github.com/ShahSau/culinary-bliss/helpers"
+	"github.com/ShahSau/culinary-bliss/middleware"
 	"github.com/gin-gonic/gin"
 )
 
-func InvoiceRoutes(c *gin.Engine) {
-	c.GET("/invoice", controllers.GetInvoices) //admin
-	c.GET("/invoice/:id", controllers.GetInvoice)
-	c.POST("/invoice", controllers.CreateInvoice)
-	c.PUT("/invoice/:id", controllers.UpdateInvoice)    //admin
-	c.DELETE("/invoice/:id", controllers.DeleteInvoice) //admin
+func InvoiceRoutes(c *gin.Engine, ctl *controllers.Controller) {
+	c.GET("/invoice", middleware.RequirePermission(helpers.PermReadInvoices), ctl.GetInvoices)
+	c.GET("/invoice/:id", ctl.GetInvoice)
+	c.POST("/invoice", ctl.CreateInvoice)
+	c.PUT("/invoice/:id", middleware.RequirePermission(helpers.PermWriteInvoices), ctl.UpdateInvoice)
+	c.DELETE("/invoice/:id", middleware.RequirePermission(helpers.PermDeleteInvoices), ctl.DeleteInvoice)
 }
@@ -2,11 +2,13 @@ package routes
 
 import (
 	"github.com/ShahSau/culinary-bliss/controllers"
+	"github.com/ShahSau/culinary-bliss/helpers"
+	"github.com/ShahSau/culinary-bliss/middleware"
 	"github.com/gin-gonic/gin"
 )
 
-func MenuRoutes(c *gin.Engine) {
-	c.POST("/menu", controllers.CreateMenu)
-	c.PUT("/menu/:id", controllers.UpdateMenu)
-	c.DELETE("/menu/:id", controllers.DeleteMenu)
+func MenuRoutes(c *gin.Engine, ctl *controllers.Controller) {
+	c.POST("/menu", middleware.RequirePermission(helpers.PermManageMenus), ctl.CreateMenu)
+	c.PUT("/menu/:id", middleware.RequirePermission(helpers.PermManageMenus), ctl.UpdateMenu)
+	c.DELETE("/menu/:id", middleware.RequirePermission(helpers.PermManageMenus), ctl.DeleteMenu)
 }
@@ -0,0 +1,12 @@
+package routes
+
+import (
+	"github.com/ShahSau/culinary-bliss/controllers"
+	"github.com/gin-gonic/gin"
+)
+
+func MfaRoutes(c *gin.Engine, ctl *controllers.Controller) {
+	c.POST("/mfa/enroll", ctl.EnrollMfa)
+	c.POST("/mfa/confirm", ctl.ConfirmMfa)
+	c.POST("/mfa/disable", ctl.DisableMfa)
+}
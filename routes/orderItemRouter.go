@@ -2,13 +2,19 @@ package routes
 
 import (
 	"github.com/ShahSau/culinary-bliss/controllers"
+	"github.com/ShahSau/culinary-bliss/helpers"
+	"github.com/ShahSau/culinary-bliss/middleware"
 	"github.com/gin-gonic/gin"
 )
 
-func OrderItemRoutes(c *gin.Engine) {
-	c.GET("/orderItem", controllers.GetOrderItems)
-	c.GET("/orderItem/:id", controllers.GetOrderItem)
-	c.POST("/orderItem", controllers.CreateOrderItem)
-	c.PUT("/orderItem/:id", controllers.UpdateOrderItem)
-	c.DELETE("/orderItem/:id", controllers.DeleteOrderItem)
+// OrderItemRoutes are open to API keys, the service keeps a key to the items of the orders of its restaurant
+func OrderItemRoutes(c *gin.Engine, ctl *controllers.Controller) {
+	read := middleware.RequirePermission(helpers.PermReadOrderItems)
+	write := middleware.RequirePermission(helpers.PermWriteOrderItems)
+
+	c.GET("/orderItem", read, ctl.GetOrderItems)
+	c.GET("/orderItem/:id", read, ctl.GetOrderItem)
+	c.POST("/orderItem", write, ctl.CreateOrderItem)
+	c.PUT("/orderItem/:id", write, ctl.UpdateOrderItem)
+	c.DELETE("/orderItem/:id", write, ctl.DeleteOrderItem)
 }
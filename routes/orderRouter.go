@@ -2,13 +2,34 @@ package routes
 
 import (
 	"github.com/ShahSau/culinary-bliss/controllers"
+	"github.com/ShahSau/culinary-bliss/helpers"
+	"github.com/ShahSau/culinary-bliss/middleware"
 	"github.com/gin-gonic/gin"
 )
 
-func OrderRoutes(c *gin.Engine) {
-	c.GET("/orders", controllers.GetOrders)
-	c.GET("/orders/:id", controllers.GetOrder)
-	c.POST("/orders", controllers.CreateOrder)
-	c.PUT("/orders/:id", controllers.UpdateOrder)
-	c.DELETE("/orders/:id", controllers.DeleteOrder)
+// OrderRoutes are open to API keys, the service keeps a key to the orders of its restaurant.
+// Keys have no role, so they cannot move orders, and they cannot delete them.
+func OrderRoutes(c *gin.Engine, ctl *controllers.Controller) {
+	read := middleware.RequirePermission(helpers.PermReadOrders)
+	write := middleware.RequirePermission(helpers.PermWriteOrders)
+
+	c.GET("/orders", read, ctl.GetOrders)
+	c.GET("/orders/:id", read, ctl.GetOrder)
+	c.GET("/orders/:id/details", read, ctl.GetOrderDetails)
+	c.POST("/orders", write, ctl.CreateOrder)
+	c.POST("/orders/with-items", write, ctl.PlaceOrder)
+	c.PUT("/orders/:id", write, ctl.UpdateOrder)
+	c.POST("/orders/:id/accept", write, orderRoles(helpers.OrderActionAccept), ctl.AcceptOrder)
+	c.POST("/orders/:id/prepare", write, orderRoles(helpers.OrderActionPrepare), ctl.PrepareOrder)
+	c.POST("/orders/:id/ready", write, orderRoles(helpers.OrderActionReady), ctl.ReadyOrder)
+	c.POST("/orders/:id/serve", write, orderRoles(helpers.OrderActionServe), ctl.ServeOrder)
+	c.POST("/orders/:id/pay", write, orderRoles(helpers.OrderActionPay), ctl.PayOrder)
+	c.POST("/orders/:id/cancel", write, orderRoles(helpers.OrderActionCancel), ctl.CancelOrder)
+	c.POST("/orders/:id/refund", write, orderRoles(helpers.OrderActionRefund), ctl.RefundOrder)
+	c.DELETE("/orders/:id", middleware.RefuseApiKeys(), middleware.RequirePermission(helpers.PermDeleteOrders), ctl.DeleteOrder)
+}
+
+// orderRoles only lets through the roles that can make the move from some status, the service checks the status of the order
+func orderRoles(action string) gin.HandlerFunc {
+	return middleware.RequireRole(helpers.OrderActionRoles(action)...)
 }
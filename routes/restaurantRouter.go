@@ -2,13 +2,16 @@ package routes
 
 import (
 	"github.com/ShahSau/culinary-bliss/controllers"
+	"github.com/ShahSau/culinary-bliss/helpers"
+	"github.com/ShahSau/culinary-bliss/middleware"
 	"github.com/gin-gonic/gin"
 )
 
-func RestaurantRoutes(c *gin.Engine) {
-	c.POST("/restaurants", controllers.CreateRestaurant)
-	c.PUT("/restaurants/:id", controllers.UpdateRestaurant)
-	c.DELETE("/restaurants/:id", controllers.DeleteRestaurant)
-	c.PUT("/restaurants/rating/:id", controllers.AddRatingtoRestaurant)
+// RestaurantRoutes are open to API keys, but a key can only update its own restaurant. Creating, deleting and rating restaurants is left to users.
+func RestaurantRoutes(c *gin.Engine, ctl *controllers.Controller) {
+	c.POST("/restaurants", middleware.RefuseApiKeys(), middleware.RequirePermission(helpers.PermManageRestaurants), ctl.CreateRestaurant)
+	c.PUT("/restaurants/:id", middleware.RequireRestaurantScope("id"), middleware.RequirePermission(helpers.PermManageRestaurants), ctl.UpdateRestaurant)
+	c.DELETE("/restaurants/:id", middleware.RefuseApiKeys(), middleware.RequirePermission(helpers.PermManageRestaurants), ctl.DeleteRestaurant)
+	c.PUT("/restaurants/rating/:id", middleware.RefuseApiKeys(), ctl.AddRatingtoRestaurant)
 
 }
@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"github.com/ShahSau/culinary-bliss/controllers"
+	"github.com/ShahSau/culinary-bliss/helpers"
+	"github.com/ShahSau/culinary-bliss/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+func RoleRoutes(c *gin.Engine, ctl *controllers.Controller) {
+	admin := middleware.RequirePermission(helpers.PermManageRoles)
+
+	c.GET("/roles", admin, ctl.GetRoles)
+	c.GET("/roles/:name", admin, ctl.GetRole)
+	// changing what a role may do, or who has it, can hand out any permission, so only admins can
+	c.PUT("/roles/:name", middleware.RequireRole(helpers.RoleAdmin), admin, ctl.UpdateRole)
+	c.PUT("/users/:id/role", middleware.RequireRole(helpers.RoleAdmin), admin, ctl.UpdateUserRole)
+}
@@ -2,11 +2,13 @@ package routes
 
 import (
 	"github.com/ShahSau/culinary-bliss/controllers"
+	"github.com/ShahSau/culinary-bliss/helpers"
+	"github.com/ShahSau/culinary-bliss/middleware"
 	"github.com/gin-gonic/gin"
 )
 
-func TableRoutes(c *gin.Engine) {
-	c.POST("/table", controllers.CreateTable)
-	c.PUT("/table/:id", controllers.UpdateTable)
-	c.DELETE("/table/:id", controllers.DeleteTable)
+func TableRoutes(c *gin.Engine, ctl *controllers.Controller) {
+	c.POST("/table", middleware.RequirePermission(helpers.PermManageTables), ctl.CreateTable)
+	c.PUT("/table/:id", middleware.RequirePermission(helpers.PermManageTables), ctl.UpdateTable)
+	c.DELETE("/table/:id", middleware.RequirePermission(helpers.PermManageTables), ctl.DeleteTable)
 }
@@ -2,13 +2,22 @@ package routes
 
 import (
 	"github.com/ShahSau/culinary-bliss/controllers"
+	"github.com/ShahSau/culinary-bliss/helpers"
+	"github.com/ShahSau/culinary-bliss/middleware"
 	"github.com/gin-gonic/gin"
 )
 
-func UserRoutes(c *gin.Engine) {
-	c.GET("/users", controllers.GetUsers)
-	c.GET("/users/:id", controllers.GetUser)
-	c.PUT("/users/:id", controllers.UpdateUser)
-	c.DELETE("/users/:id", controllers.DeleteUser)
-	c.POST("/reset-password", controllers.ResetPassword)
+func UserRoutes(c *gin.Engine, ctl *controllers.Controller) {
+	c.GET("/users", middleware.RequirePermission(helpers.PermReadUsers), ctl.GetUsers)
+	c.GET("/users/:id", middleware.RequireSelfOrPermission("id", helpers.PermReadUsers), ctl.GetUser)
+	c.PUT("/users/:id", middleware.RequireSelfOrPermission("id", helpers.PermWriteUsers), ctl.UpdateUser)
+	c.PUT("/users/:id/credentials", middleware.RequireSelf("id"), ctl.ChangeCredentials)
+	c.DELETE("/users/:id", middleware.RequirePermission(helpers.PermDeleteUsers), ctl.DeleteUser)
+	c.POST("/reset-password", ctl.ResetPassword)
+	c.GET("/users/:id/sessions", middleware.RequireSelfOrPermission("id", helpers.PermRevokeSessions), ctl.GetSessions)
+	c.DELETE("/users/:id/sessions/:session_id", middleware.RequireSelfOrPermission("id", helpers.PermRevokeSessions), ctl.RevokeSession)
+	c.POST("/users/:id/verification/resend", middleware.RequirePermission(helpers.PermWriteUsers), ctl.ResendVerification)
+	c.POST("/users/:id/verify", middleware.RequirePermission(helpers.PermWriteUsers), ctl.VerifyUser)
+	c.POST("/users/:id/unlock", middleware.RequirePermission(helpers.PermWriteUsers), ctl.UnlockUser)
+	c.POST("/users/:id/logout-all", middleware.RequireSelfOrPermission("id", helpers.PermRevokeSessions), ctl.LogoutAllSessions)
 }
@@ -0,0 +1,487 @@
+//go:build integration
+
+package main
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ShahSau/culinary-bliss/helpers"
+)
+
+func TestHealthAndDocs(t *testing.T) {
+	a := newApp(t)
+
+	a.call(http.MethodGet, "/healthz", nil, nil, http.StatusOK)
+	ready := a.call(http.MethodGet, "/readyz", nil, nil, http.StatusOK)
+	if checks, _ := ready["checks"].(map[string]interface{}); ready["status"] != "ready" || checks["mongo"] == nil || checks["signing_keys"] == nil {
+		t.Fatalf("expected both checks to be reported, got %v", ready)
+	}
+
+	jwks := a.call(http.MethodGet, "/.well-known/jwks.json", nil, nil, http.StatusOK)
+	if keys, _ := jwks["keys"].([]interface{}); len(keys) == 0 {
+		t.Fatalf("expected the signing key to be published, got %v", jwks)
+	}
+
+	doc := a.call(http.MethodGet, "/swagger/doc.json", nil, nil, http.StatusOK)
+	if doc["paths"] == nil {
+		t.Fatalf("expected the swagger document, got %v", doc)
+	}
+}
+
+func TestRegisterVerifyLoginAndLogout(t *testing.T) {
+	a := newApp(t)
+	email := "ada@example.com"
+
+	a.call(http.MethodPost, "/register", nil, map[string]string{
+		"first_name": "Ada", "last_name": "Lovelace", "email": email, "password": password, "phone": "+44 20 7946 0958",
+	}, http.StatusCreated)
+	a.call(http.MethodPost, "/register", nil, map[string]string{
+		"first_name": "Ada", "last_name": "Lovelace", "email": email, "password": password, "phone": "+44 20 7946 0959",
+	}, http.StatusConflict)
+
+	a.call(http.MethodPost, "/login", nil, map[string]string{"email": email, "password": password}, http.StatusForbidden)
+	a.call(http.MethodPost, "/verify-email", nil, map[string]string{"token": a.mail.token(t, email)}, http.StatusOK)
+
+	_, refreshToken := a.login(email)
+	a.call(http.MethodPost, "/login", nil, map[string]string{"email": email, "password": "wrong"}, http.StatusUnauthorized)
+
+	refreshed := a.call(http.MethodPost, "/token/refresh", nil, map[string]string{"refresh_token": refreshToken}, http.StatusOK)
+	token := refreshed["token"].(string)
+	a.call(http.MethodPost, "/logout", bearer(token), nil, http.StatusOK)
+	a.call(http.MethodPost, "/logout", bearer(token), nil, http.StatusUnauthorized)
+
+	// replaying a refresh token ends the session it belongs to
+	_, refreshToken = a.login(email)
+	refreshed = a.call(http.MethodPost, "/token/refresh", nil, map[string]string{"refresh_token": refreshToken}, http.StatusOK)
+	a.call(http.MethodPost, "/token/refresh", nil, map[string]string{"refresh_token": refreshToken}, http.StatusUnauthorized)
+	a.call(http.MethodPost, "/logout", bearer(refreshed["token"].(string)), nil, http.StatusUnauthorized)
+
+	a.call(http.MethodPost, "/password/forgot", nil, map[string]string{"email": email}, http.StatusOK)
+	a.call(http.MethodPost, "/password/reset", nil, map[string]string{"token": a.mail.token(t, email), "new_password": "a new password"}, http.StatusOK)
+	a.call(http.MethodPost, "/login", nil, map[string]string{"email": email, "password": password}, http.StatusUnauthorized)
+	a.call(http.MethodPost, "/login", nil, map[string]string{"email": email, "password": "a new password"}, http.StatusOK)
+}
+
+func TestMfaEnrollmentAndLogin(t *testing.T) {
+	a := newApp(t)
+	admin := a.seedUser(helpers.RoleAdmin, "admin@example.com")
+	customer := a.seedUser(helpers.RoleCustomer, "customer@example.com")
+	waiter := a.seedUser(helpers.RoleWaiter, "waiter@example.com")
+	adminToken, _ := a.login(admin.Email)
+
+	// a user turns two-factor authentication on and later off again
+	token, _ := a.login(customer.Email)
+	secret := data(t, a.call(http.MethodPost, "/mfa/enroll", bearer(token), nil, http.StatusOK))["secret"].(string)
+	confirmed := data(t, a.call(http.MethodPost, "/mfa/confirm", bearer(token), map[string]string{"code": totp(t, secret, 0)}, http.StatusOK))
+	recoveryCodes := confirmed["recovery_codes"].([]interface{})
+
+	challenge := a.call(http.MethodPost, "/login", nil, map[string]string{"email": customer.Email, "password": password}, http.StatusOK)
+	if challenge["mfa_required"] != true || challenge["token"] != nil {
+		t.Fatalf("expected a second factor to be asked for, got %v", challenge)
+	}
+	loggedIn := a.call(http.MethodPost, "/login/mfa", nil, map[string]interface{}{"mfa_token": challenge["mfa_token"], "recovery_code": recoveryCodes[0]}, http.StatusOK)
+	token = loggedIn["token"].(string)
+	// a later code, the current one was used to confirm
+	a.call(http.MethodPost, "/mfa/disable", bearer(token), map[string]string{"code": totp(t, secret, 1)}, http.StatusOK)
+
+	// a role can require it, its users then enroll while logging in
+	a.call(http.MethodPut, "/roles/"+helpers.RoleWaiter, bearer(adminToken), map[string]interface{}{
+		"permissions": helpers.DefaultRolePermissions[helpers.RoleWaiter], "mfa_required": true,
+	}, http.StatusOK)
+	challenge = a.call(http.MethodPost, "/login", nil, map[string]string{"email": waiter.Email, "password": password}, http.StatusOK)
+	if challenge["mfa_enrollment_required"] != true {
+		t.Fatalf("expected the waiter to have to enroll, got %v", challenge)
+	}
+	enrollment := data(t, a.call(http.MethodPost, "/login/mfa/enroll", nil, map[string]interface{}{"mfa_token": challenge["mfa_token"]}, http.StatusOK))
+	loggedIn = a.call(http.MethodPost, "/login/mfa", nil, map[string]interface{}{
+		"mfa_token": challenge["mfa_token"], "code": totp(t, enrollment["secret"].(string), 0),
+	}, http.StatusOK)
+	if codes, _ := loggedIn["recovery_codes"].([]interface{}); len(codes) == 0 || loggedIn["token"] == nil {
+		t.Fatalf("expected tokens and recovery codes, got %v", loggedIn)
+	}
+}
+
+// totp is the code of the secret steps periods from now
+func totp(t *testing.T, secret string, steps int) string {
+	t.Helper()
+	code, err := helpers.TOTPCode(secret, time.Now().Add(time.Duration(steps)*30*time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return code
+}
+
+func TestUserAdministration(t *testing.T) {
+	a := newApp(t)
+	admin := a.seedUser(helpers.RoleAdmin, "admin@example.com")
+	customer := a.seedUser(helpers.RoleCustomer, "customer@example.com")
+	adminToken, _ := a.login(admin.Email)
+	customerToken, _ := a.login(customer.Email)
+	users := "/users/" + customer.User_id
+
+	a.call(http.MethodGet, "/users", nil, nil, http.StatusUnauthorized)
+	a.call(http.MethodGet, "/users", bearer(customerToken), nil, http.StatusForbidden)
+	list := a.call(http.MethodGet, "/users?limit=1", bearer(adminToken), nil, http.StatusOK)
+	if list["total"].(float64) != 2 || len(items(t, list)) != 1 || list["next_cursor"] == "" {
+		t.Fatalf("unexpected page %v", list)
+	}
+
+	a.call(http.MethodGet, users, bearer(adminToken), nil, http.StatusOK)
+	updated := data(t, a.call(http.MethodPut, users, bearer(customerToken), map[string]string{"first_name": "Grace"}, http.StatusOK))
+	if updated["first_name"] != "Grace" || updated["last_name"] != customer.Last_name {
+		t.Fatalf("expected a partial update, got %v", updated)
+	}
+	if _, leaked := updated["password"]; leaked {
+		t.Fatalf("expected no password in %v", updated)
+	}
+	a.call(http.MethodGet, "/users/"+admin.User_id, bearer(customerToken), nil, http.StatusForbidden)
+	a.call(http.MethodPut, "/users/"+admin.User_id, bearer(customerToken), map[string]string{"first_name": "Mallory"}, http.StatusForbidden)
+	a.call(http.MethodPost, "/reset-password", bearer(customerToken), map[string]string{
+		"email": customer.Email, "old_password": password, "new_password": password,
+	}, http.StatusOK)
+	a.call(http.MethodPost, "/reset-password", bearer(adminToken), map[string]string{
+		"email": customer.Email, "old_password": password, "new_password": "taken over",
+	}, http.StatusForbidden)
+
+	a.call(http.MethodPut, users+"/credentials", bearer(adminToken), map[string]string{"current_password": password, "new_password": "taken over"}, http.StatusForbidden)
+	a.call(http.MethodPut, users+"/credentials", bearer(customerToken), map[string]string{"current_password": "guess", "new_password": "taken over"}, http.StatusUnauthorized)
+	a.call(http.MethodPut, users+"/credentials", bearer(customerToken), map[string]string{"current_password": password, "email": admin.Email}, http.StatusConflict)
+	a.call(http.MethodPut, users+"/credentials", bearer(customerToken), map[string]string{"current_password": password, "new_password": password}, http.StatusOK)
+
+	sessions := a.call(http.MethodGet, users+"/sessions", bearer(customerToken), nil, http.StatusOK)
+	session := items(t, sessions)[0].(map[string]interface{})
+	a.call(http.MethodGet, "/users/"+admin.User_id+"/sessions", bearer(customerToken), nil, http.StatusForbidden)
+	a.call(http.MethodDelete, users+"/sessions/"+session["session_id"].(string), bearer(adminToken), nil, http.StatusOK)
+	a.call(http.MethodGet, users+"/sessions", bearer(customerToken), nil, http.StatusUnauthorized)
+
+	customerToken, _ = a.login(customer.Email)
+	loggedOut := data(t, a.call(http.MethodPost, users+"/logout-all", bearer(customerToken), nil, http.StatusOK))
+	if loggedOut["revoked_sessions"].(float64) != 1 {
+		t.Fatalf("expected one session to be revoked, got %v", loggedOut)
+	}
+
+	pending := data(t, a.call(http.MethodPost, "/register", nil, map[string]string{
+		"first_name": "Alan", "last_name": "Turing", "email": "alan@example.com", "password": password, "phone": "+44 20 7946 0100",
+	}, http.StatusCreated))
+	pendingUser := "/users/" + pending["user_id"].(string)
+	a.call(http.MethodPost, pendingUser+"/verification/resend", bearer(adminToken), nil, http.StatusOK)
+	a.call(http.MethodPost, pendingUser+"/verify", bearer(adminToken), nil, http.StatusOK)
+	a.call(http.MethodPost, pendingUser+"/verification/resend", bearer(adminToken), nil, http.StatusBadRequest)
+	a.call(http.MethodPost, pendingUser+"/unlock", bearer(adminToken), nil, http.StatusOK)
+
+	roles := a.call(http.MethodGet, "/roles", bearer(adminToken), nil, http.StatusOK)
+	if len(roles["data"].([]interface{})) != len(helpers.Roles) {
+		t.Fatalf("expected every role, got %v", roles)
+	}
+	a.call(http.MethodGet, "/roles/"+helpers.RoleManager, bearer(adminToken), nil, http.StatusOK)
+	a.call(http.MethodPut, pendingUser+"/role", bearer(adminToken), map[string]string{"role": helpers.RoleManager}, http.StatusOK)
+	managerToken, _ := a.login("alan@example.com")
+	a.call(http.MethodGet, "/users", bearer(managerToken), nil, http.StatusOK)
+
+	// roles:manage does not let a manager make anyone an admin, and changing the role ends the sessions of its users
+	a.call(http.MethodPut, "/roles/"+helpers.RoleManager, bearer(adminToken), map[string]interface{}{
+		"permissions": append([]string{helpers.PermManageRoles}, helpers.DefaultRolePermissions[helpers.RoleManager]...),
+	}, http.StatusOK)
+	a.call(http.MethodGet, "/users", bearer(managerToken), nil, http.StatusUnauthorized)
+	managerToken, _ = a.login("alan@example.com")
+	a.call(http.MethodPut, pendingUser+"/role", bearer(managerToken), map[string]string{"role": helpers.RoleAdmin}, http.StatusForbidden)
+
+	a.call(http.MethodDelete, pendingUser, bearer(managerToken), nil, http.StatusForbidden)
+	a.call(http.MethodDelete, pendingUser, bearer(adminToken), nil, http.StatusOK)
+	a.call(http.MethodGet, pendingUser, bearer(adminToken), nil, http.StatusNotFound)
+}
+
+func TestChangingTheEmailWaitsForTheNewAddress(t *testing.T) {
+	a := newApp(t)
+	customer := a.seedUser(helpers.RoleCustomer, "customer@example.com")
+	token, _ := a.login(customer.Email)
+
+	changed := data(t, a.call(http.MethodPut, "/users/"+customer.User_id+"/credentials", bearer(token), map[string]string{
+		"current_password": password, "email": "new@example.com",
+	}, http.StatusOK))
+	if changed["email"] != customer.Email {
+		t.Fatalf("expected the account to keep its address until the new one is verified, got %v", changed)
+	}
+	a.login(customer.Email)
+
+	a.call(http.MethodPost, "/verify-email", nil, map[string]string{"token": a.mail.token(t, "new@example.com")}, http.StatusOK)
+	a.login("new@example.com")
+	a.call(http.MethodPost, "/login", nil, map[string]string{"email": customer.Email, "password": password}, http.StatusUnauthorized)
+}
+
+func TestCatalog(t *testing.T) {
+	a := newApp(t)
+	manager := a.seedUser(helpers.RoleManager, "manager@example.com")
+	waiter := a.seedUser(helpers.RoleWaiter, "waiter@example.com")
+	token, _ := a.login(manager.Email)
+	waiterToken, _ := a.login(waiter.Email)
+	start := time.Now().UTC().Truncate(time.Second)
+
+	menu := data(t, a.call(http.MethodPost, "/menu", bearer(token), map[string]interface{}{
+		"name": "Lunch", "description": "Served at noon", "start_date": start, "end_date": start.Add(24 * time.Hour),
+	}, http.StatusCreated))
+	menuID := menu["menu_id"].(string)
+	a.call(http.MethodPost, "/menu", bearer(waiterToken), map[string]interface{}{
+		"name": "Lunch", "description": "Served at noon", "start_date": start, "end_date": start.Add(24 * time.Hour),
+	}, http.StatusForbidden)
+	a.call(http.MethodPut, "/menu/"+menuID, bearer(token), map[string]interface{}{"end_date": start.Add(-time.Hour)}, http.StatusBadRequest)
+	a.call(http.MethodPut, "/menu/"+menuID, bearer(token), map[string]interface{}{"description": "Served all afternoon"}, http.StatusOK)
+	a.call(http.MethodGet, "/menu", nil, nil, http.StatusOK)
+	a.call(http.MethodGet, "/menu/"+menuID, nil, nil, http.StatusOK)
+
+	food := data(t, a.call(http.MethodPost, "/food", bearer(token), map[string]interface{}{
+		"name": "Soup", "description": "Hot", "price": 4.5, "image": "soup.png", "menu_id": menuID,
+	}, http.StatusAccepted))
+	foodID := food["food_id"].(string)
+	a.call(http.MethodPost, "/food", bearer(token), map[string]interface{}{
+		"name": "Soup", "description": "Hot", "price": -1, "image": "soup.png", "menu_id": menuID,
+	}, http.StatusBadRequest)
+	a.call(http.MethodPut, "/food/"+foodID, bearer(token), map[string]interface{}{"price": 5}, http.StatusAccepted)
+	foods := a.call(http.MethodGet, "/foods?price[gte]=5&menu_id="+menuID, nil, nil, http.StatusOK)
+	if foods["total"].(float64) != 1 {
+		t.Fatalf("expected the updated food to match, got %v", foods)
+	}
+	a.call(http.MethodGet, "/food/"+foodID, nil, nil, http.StatusAccepted)
+
+	category := data(t, a.call(http.MethodPost, "/categeory", bearer(token), map[string]string{"title": "Starters", "image": "starters.png"}, http.StatusCreated))
+	categoryID := category["category_id"].(string)
+	a.call(http.MethodPut, "/categeory/"+categoryID, bearer(token), map[string]string{"title": "Small plates"}, http.StatusOK)
+	a.call(http.MethodGet, "/categories", nil, nil, http.StatusOK)
+	a.call(http.MethodGet, "/categeory/"+categoryID, bearer(waiterToken), nil, http.StatusOK)
+
+	table := data(t, a.call(http.MethodPost, "/table", bearer(token), map[string]interface{}{
+		"number_of_guests": 4, "table_number": 1, "table_status": "FREE",
+	}, http.StatusCreated))
+	tableID := table["table_id"].(string)
+	a.call(http.MethodPut, "/table/"+tableID, bearer(token), map[string]interface{}{"number_of_guests": 6}, http.StatusOK)
+	a.call(http.MethodGet, "/table", nil, nil, http.StatusOK)
+	a.call(http.MethodGet, "/table/"+tableID, nil, nil, http.StatusOK)
+
+	restaurant := data(t, a.call(http.MethodPost, "/restaurants", bearer(token), map[string]interface{}{
+		"title": "Bliss", "image": "bliss.png", "time": "12:00-22:00", "pickup": true, "menu": []string{menuID},
+	}, http.StatusOK))
+	restaurantID := restaurant["restaurant_id"].(string)
+	a.call(http.MethodPut, "/restaurants/"+restaurantID, bearer(token), map[string]interface{}{"delivery": true}, http.StatusOK)
+	rated := data(t, a.call(http.MethodPut, "/restaurants/rating/"+restaurantID, bearer(waiterToken), map[string]interface{}{"rating": 4}, http.StatusOK))
+	if rated["rating"].(float64) != 4 || rated["ratingCount"].(float64) != 1 {
+		t.Fatalf("unexpected rating %v", rated)
+	}
+	a.call(http.MethodGet, "/restaurants", nil, nil, http.StatusOK)
+	a.call(http.MethodGet, "/restaurants/"+restaurantID, nil, nil, http.StatusOK)
+	a.call(http.MethodGet, "/restaurants/menus/"+restaurantID, nil, nil, http.StatusOK)
+
+	a.call(http.MethodDelete, "/restaurants/"+restaurantID, bearer(token), nil, http.StatusOK)
+	a.call(http.MethodDelete, "/table/"+tableID, bearer(token), nil, http.StatusOK)
+	a.call(http.MethodDelete, "/categeory/"+categoryID, bearer(token), nil, http.StatusOK)
+	a.call(http.MethodDelete, "/food/"+foodID, bearer(token), nil, http.StatusAccepted)
+	a.call(http.MethodDelete, "/menu/"+menuID, bearer(token), nil, http.StatusOK)
+	a.call(http.MethodGet, "/menu/"+menuID, nil, nil, http.StatusNotFound)
+}
+
+func TestOrdersAndInvoices(t *testing.T) {
+	a := newApp(t)
+	manager := a.seedUser(helpers.RoleManager, "manager@example.com")
+	kitchen := a.seedUser(helpers.RoleKitchen, "kitchen@example.com")
+	token, _ := a.login(manager.Email)
+	kitchenToken, _ := a.login(kitchen.Email)
+	start := time.Now().UTC().Truncate(time.Second)
+
+	menu := data(t, a.call(http.MethodPost, "/menu", bearer(token), map[string]interface{}{
+		"name": "Dinner", "description": "Served at night", "start_date": start, "end_date": start.Add(24 * time.Hour),
+	}, http.StatusCreated))
+	food := data(t, a.call(http.MethodPost, "/food", bearer(token), map[string]interface{}{
+		"name": "Stew", "description": "Slow cooked", "price": 12, "image": "stew.png", "menu_id": menu["menu_id"],
+	}, http.StatusAccepted))
+	table := data(t, a.call(http.MethodPost, "/table", bearer(token), map[string]interface{}{
+		"number_of_guests": 2, "table_number": 3, "table_status": "FREE",
+	}, http.StatusCreated))
+
+	order := data(t, a.call(http.MethodPost, "/orders", bearer(token), map[string]interface{}{
+		"table_id": table["table_id"],
+	}, http.StatusCreated))
+	orderID := order["order_id"].(string)
+	a.call(http.MethodPut, "/orders/"+orderID, bearer(kitchenToken), map[string]interface{}{"total_amount": 12}, http.StatusBadRequest)
+	a.call(http.MethodPut, "/orders/"+orderID, bearer(kitchenToken), map[string]interface{}{"table_id": table["table_id"], "total_amount": 0}, http.StatusOK)
+	a.call(http.MethodPost, "/orderItem", bearer(token), map[string]interface{}{
+		"food_id": food["food_id"], "order_id": orderID, "quantity": 1, "total_amount": 10,
+	}, http.StatusBadRequest)
+	item := data(t, a.call(http.MethodPost, "/orderItem", bearer(token), map[string]interface{}{
+		"food_id": food["food_id"], "order_id": orderID, "quantity": 1, "portion": "M", "total_amount": 12,
+	}, http.StatusCreated))
+	itemID := item["order_item_id"].(string)
+	a.call(http.MethodPut, "/orderItem/"+itemID, bearer(kitchenToken), map[string]interface{}{"quantity": 2}, http.StatusForbidden)
+	a.call(http.MethodPut, "/orderItem/"+itemID, bearer(token), map[string]interface{}{"quantity": 2}, http.StatusOK)
+	items := a.call(http.MethodGet, "/orderItem?order_id="+orderID, bearer(kitchenToken), nil, http.StatusOK)
+	if items["total"].(float64) != 1 {
+		t.Fatalf("expected the item of the order, got %v", items)
+	}
+	a.call(http.MethodGet, "/orderItem/"+itemID, bearer(kitchenToken), nil, http.StatusOK)
+
+	a.call(http.MethodPost, "/orders/"+orderID+"/prepare", bearer(kitchenToken), nil, http.StatusConflict)
+	a.call(http.MethodPost, "/orders/"+orderID+"/accept", bearer(kitchenToken), nil, http.StatusForbidden)
+	a.call(http.MethodPost, "/orders/"+orderID+"/accept", bearer(token), nil, http.StatusOK)
+	a.call(http.MethodPost, "/orders/"+orderID+"/prepare", bearer(kitchenToken), nil, http.StatusOK)
+	if preparing := a.call(http.MethodGet, "/orders?order_status=PREPARING", bearer(kitchenToken), nil, http.StatusOK); preparing["total"].(float64) != 1 {
+		t.Fatalf("expected the order to be preparing, got %v", preparing)
+	}
+	a.call(http.MethodGet, "/orders/"+orderID, bearer(kitchenToken), nil, http.StatusOK)
+
+	// the kitchen has started, the items are fixed
+	a.call(http.MethodPost, "/orderItem", bearer(token), map[string]interface{}{
+		"food_id": food["food_id"], "order_id": orderID, "quantity": 1, "portion": "M",
+	}, http.StatusConflict)
+	a.call(http.MethodPut, "/orderItem/"+itemID, bearer(token), map[string]interface{}{"quantity": 3}, http.StatusConflict)
+	a.call(http.MethodDelete, "/orderItem/"+itemID, bearer(token), nil, http.StatusConflict)
+
+	invoice := data(t, a.call(http.MethodPost, "/invoice", bearer(token), map[string]string{"order_id": orderID, "payment_method": "CARD"}, http.StatusCreated))
+	invoiceID := invoice["invoice_id"].(string)
+	if total, _ := invoice["total_amount"].(map[string]interface{}); total["amount"] != "24.00" || total["currency"] != "USD" {
+		t.Fatalf("expected the invoice to charge the order total, got %v", invoice)
+	}
+	a.call(http.MethodPost, "/invoice", bearer(token), map[string]string{"order_id": orderID, "payment_method": "CHEQUE"}, http.StatusBadRequest)
+	a.call(http.MethodPut, "/invoice/"+invoiceID, bearer(token), map[string]string{"payment_method": "CASH"}, http.StatusOK)
+	a.call(http.MethodGet, "/invoice", bearer(kitchenToken), nil, http.StatusForbidden)
+	a.call(http.MethodGet, "/invoice", bearer(token), nil, http.StatusOK)
+	a.call(http.MethodGet, "/invoice/"+invoiceID, bearer(token), nil, http.StatusOK)
+
+	a.call(http.MethodPost, "/orders/"+orderID+"/ready", bearer(kitchenToken), nil, http.StatusOK)
+	a.call(http.MethodPost, "/orders/"+orderID+"/serve", bearer(kitchenToken), nil, http.StatusForbidden)
+	a.call(http.MethodPost, "/orders/"+orderID+"/serve", bearer(token), nil, http.StatusOK)
+	a.call(http.MethodPost, "/orders/"+orderID+"/pay", bearer(kitchenToken), nil, http.StatusForbidden)
+	order = data(t, a.call(http.MethodPost, "/orders/"+orderID+"/pay", bearer(token), nil, http.StatusOK))
+	if history := order["status_history"].([]interface{}); order["order_status"] != "PAID" || len(history) != 6 {
+		t.Fatalf("expected the order to be paid after six statuses, got %v", order)
+	}
+	a.call(http.MethodPost, "/orders/"+orderID+"/cancel", bearer(token), nil, http.StatusConflict)
+	a.call(http.MethodPost, "/orders/"+orderID+"/refund", bearer(kitchenToken), nil, http.StatusForbidden)
+	if order = data(t, a.call(http.MethodPost, "/orders/"+orderID+"/refund", bearer(token), nil, http.StatusOK)); order["order_status"] != "REFUNDED" {
+		t.Fatalf("expected the order to be refunded, got %v", order)
+	}
+
+	a.call(http.MethodDelete, "/invoice/"+invoiceID, bearer(token), nil, http.StatusForbidden)
+	a.call(http.MethodDelete, "/orders/"+orderID, bearer(token), nil, http.StatusOK)
+	a.call(http.MethodGet, "/orders/"+orderID, bearer(token), nil, http.StatusNotFound)
+}
+
+func TestPlaceOrderIsAtomic(t *testing.T) {
+	a := newApp(t)
+	manager := a.seedUser(helpers.RoleManager, "manager@example.com")
+	token, _ := a.login(manager.Email)
+	start := time.Now().UTC().Truncate(time.Second)
+
+	menu := data(t, a.call(http.MethodPost, "/menu", bearer(token), map[string]interface{}{
+		"name": "Dinner", "description": "Served at night", "start_date": start, "end_date": start.Add(24 * time.Hour),
+	}, http.StatusCreated))
+	food := data(t, a.call(http.MethodPost, "/food", bearer(token), map[string]interface{}{
+		"name": "Stew", "description": "Slow cooked", "price": 12, "image": "stew.png", "menu_id": menu["menu_id"],
+	}, http.StatusAccepted))
+	table := data(t, a.call(http.MethodPost, "/table", bearer(token), map[string]interface{}{
+		"number_of_guests": 2, "table_number": 3, "table_status": "FREE",
+	}, http.StatusCreated))
+
+	pack := func(foodIDs ...string) map[string]interface{} {
+		var lines []map[string]interface{}
+		for _, id := range foodIDs {
+			lines = append(lines, map[string]interface{}{"food_id": id, "quantity": 1, "notes": "No salt"})
+		}
+		return map[string]interface{}{"table_id": table["table_id"], "order_items": lines}
+	}
+	foodID := food["food_id"].(string)
+	a.call(http.MethodPost, "/orders/with-items", bearer(token), pack(foodID, "missing"), http.StatusBadRequest)
+	if orders := a.call(http.MethodGet, "/orders", bearer(token), nil, http.StatusOK); orders["total"].(float64) != 0 {
+		t.Fatalf("expected no order to be stored, got %v", orders)
+	}
+
+	placed := data(t, a.call(http.MethodPost, "/orders/with-items", bearer(token), pack(foodID, foodID), http.StatusCreated))
+	order, _ := placed["order"].(map[string]interface{})
+	if total, _ := order["total_amount"].(map[string]interface{}); total["amount"] != "24.00" {
+		t.Fatalf("expected the order to cost two stews, got %v", placed)
+	}
+	if lines, _ := placed["order_items"].([]interface{}); len(lines) != 2 || lines[0].(map[string]interface{})["notes"] != "No salt" {
+		t.Fatalf("expected both stews with their notes, got %v", placed)
+	}
+	details := data(t, a.call(http.MethodGet, "/orders/"+order["order_id"].(string)+"/details", bearer(token), nil, http.StatusOK))
+	detailTable, _ := details["table"].(map[string]interface{})
+	lines, _ := details["order_items"].([]interface{})
+	due, _ := details["payment_due"].(map[string]interface{})
+	if detailTable["table_number"] != 3.0 || len(lines) != 2 || due["amount"] != "24.00" {
+		t.Fatalf("unexpected order details %v", details)
+	}
+	if line := lines[0].(map[string]interface{}); line["name"] != "Stew" || line["image"] != "stew.png" || line["quantity"] != 1.0 {
+		t.Fatalf("expected the line joined to its food, got %v", line)
+	}
+	a.call(http.MethodGet, "/orders/missing/details", bearer(token), nil, http.StatusNotFound)
+
+	stored := data(t, a.call(http.MethodGet, "/table/"+table["table_id"].(string), nil, nil, http.StatusOK))
+	if stored["table_status"] != "OCCUPIED" {
+		t.Fatalf("expected the table to be occupied, got %v", stored)
+	}
+}
+
+func TestApiKeys(t *testing.T) {
+	a := newApp(t)
+	admin := a.seedUser(helpers.RoleAdmin, "admin@example.com")
+	token, _ := a.login(admin.Email)
+
+	restaurant := data(t, a.call(http.MethodPost, "/restaurants", bearer(token), map[string]interface{}{
+		"title": "Bliss", "image": "bliss.png", "time": "12:00-22:00",
+	}, http.StatusOK))
+	other := data(t, a.call(http.MethodPost, "/restaurants", bearer(token), map[string]interface{}{
+		"title": "Elsewhere", "image": "elsewhere.png", "time": "08:00-14:00",
+	}, http.StatusOK))
+
+	// no route open to API keys asks for orders:delete, so a key cannot be given it
+	a.call(http.MethodPost, "/api-keys", bearer(token), map[string]interface{}{
+		"name": "till", "restaurant_id": restaurant["restaurant_id"], "permissions": []string{helpers.PermDeleteOrders},
+	}, http.StatusBadRequest)
+	created := a.call(http.MethodPost, "/api-keys", bearer(token), map[string]interface{}{
+		"name": "kitchen printer", "restaurant_id": restaurant["restaurant_id"],
+		"permissions": []string{helpers.PermReadOrders, helpers.PermWriteOrders, helpers.PermReadOrderItems, helpers.PermManageRestaurants},
+	}, http.StatusCreated)
+	key := created["api_key"].(string)
+	keyID := data(t, created)["key_id"].(string)
+
+	a.call(http.MethodPut, "/restaurants/"+restaurant["restaurant_id"].(string), apiKey(key), map[string]interface{}{"pickup": true}, http.StatusOK)
+	a.call(http.MethodPut, "/restaurants/"+other["restaurant_id"].(string), apiKey(key), map[string]interface{}{"pickup": true}, http.StatusForbidden)
+	a.call(http.MethodDelete, "/restaurants/"+restaurant["restaurant_id"].(string), apiKey(key), nil, http.StatusForbidden)
+	a.call(http.MethodPut, "/restaurants/rating/"+restaurant["restaurant_id"].(string), apiKey(key), map[string]interface{}{"rating": 5}, http.StatusForbidden)
+	a.call(http.MethodPost, "/restaurants", apiKey(key), map[string]interface{}{
+		"title": "Mine", "image": "mine.png", "time": "12:00-22:00",
+	}, http.StatusForbidden)
+
+	table := data(t, a.call(http.MethodPost, "/table", bearer(token), map[string]interface{}{
+		"number_of_guests": 2, "table_number": 1, "table_status": "FREE", "restaurant_id": restaurant["restaurant_id"],
+	}, http.StatusCreated))
+	otherTable := data(t, a.call(http.MethodPost, "/table", bearer(token), map[string]interface{}{
+		"number_of_guests": 2, "table_number": 2, "table_status": "FREE", "restaurant_id": other["restaurant_id"],
+	}, http.StatusCreated))
+	otherOrder := data(t, a.call(http.MethodPost, "/orders", bearer(token), map[string]interface{}{"table_id": otherTable["table_id"]}, http.StatusCreated))
+
+	order := data(t, a.call(http.MethodPost, "/orders", apiKey(key), map[string]interface{}{"table_id": table["table_id"]}, http.StatusCreated))
+	if order["restaurant_id"] != restaurant["restaurant_id"] {
+		t.Fatalf("expected the order to be in the restaurant of its table, got %v", order)
+	}
+	a.call(http.MethodPost, "/orders", apiKey(key), map[string]interface{}{"table_id": otherTable["table_id"]}, http.StatusNotFound)
+	if orders := a.call(http.MethodGet, "/orders", apiKey(key), nil, http.StatusOK); orders["total"].(float64) != 1 {
+		t.Fatalf("expected the key to only list the orders of its restaurant, got %v", orders)
+	}
+	a.call(http.MethodGet, "/orders/"+order["order_id"].(string), apiKey(key), nil, http.StatusOK)
+	a.call(http.MethodGet, "/orders/"+otherOrder["order_id"].(string), apiKey(key), nil, http.StatusNotFound)
+	a.call(http.MethodGet, "/orders/"+otherOrder["order_id"].(string)+"/details", apiKey(key), nil, http.StatusNotFound)
+	a.call(http.MethodGet, "/orderItem?order_id="+order["order_id"].(string), apiKey(key), nil, http.StatusOK)
+	a.call(http.MethodGet, "/orderItem?order_id="+otherOrder["order_id"].(string), apiKey(key), nil, http.StatusNotFound)
+	a.call(http.MethodGet, "/orderItem", apiKey(key), nil, http.StatusBadRequest)
+	a.call(http.MethodPost, "/orders/"+order["order_id"].(string)+"/accept", apiKey(key), nil, http.StatusForbidden)
+	a.call(http.MethodDelete, "/orders/"+order["order_id"].(string), apiKey(key), nil, http.StatusForbidden)
+	a.call(http.MethodGet, "/invoice", apiKey(key), nil, http.StatusForbidden)
+	a.call(http.MethodPost, "/logout", apiKey(key), nil, http.StatusBadRequest)
+
+	keys := a.call(http.MethodGet, "/api-keys", bearer(token), nil, http.StatusOK)
+	if keys["total"].(float64) != 1 {
+		t.Fatalf("expected the key to be listed, got %v", keys)
+	}
+	a.call(http.MethodDelete, "/api-keys/"+keyID, bearer(token), nil, http.StatusOK)
+	a.call(http.MethodPut, "/restaurants/"+restaurant["restaurant_id"].(string), apiKey(key), map[string]interface{}{"pickup": true}, http.StatusUnauthorized)
+}
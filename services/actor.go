@@ -0,0 +1,36 @@
+package services
+
+import (
+	"time"
+
+	"github.com/ShahSau/culinary-bliss/listing"
+)
+
+// Actor is who an operation is performed for: a logged in user, an API key, or nobody yet for logins and sign ups.
+// Controllers build it from the request, background jobs and tools build it themselves.
+type Actor struct {
+	User_id          string
+	Email            string
+	Role             string
+	Permissions      []string
+	Api_key_id       string
+	Restaurant_id    string
+	Token_id         string
+	Family_id        string
+	Token_expires_at time.Time
+	Ip_address       string
+	User_agent       string
+}
+
+// inRestaurant reports if the actor may see what belongs to the restaurant. API keys only see their own restaurant, users see every one.
+func (a Actor) inRestaurant(restaurantID string) bool {
+	return a.Restaurant_id == "" || a.Restaurant_id == restaurantID
+}
+
+// restaurantFilter keeps a list of an API key to the restaurant of the key, the lists of users are not narrowed
+func (a Actor) restaurantFilter(query listing.Query) listing.Query {
+	if a.Restaurant_id != "" {
+		query.Filters = append(query.Filters, listing.Filter{Field: "restaurant_id", Op: listing.Eq, Value: a.Restaurant_id})
+	}
+	return query
+}
@@ -0,0 +1,137 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ShahSau/culinary-bliss/apperrors"
+	"github.com/ShahSau/culinary-bliss/helpers"
+	"github.com/ShahSau/culinary-bliss/listing"
+	"github.com/ShahSau/culinary-bliss/models"
+	"github.com/ShahSau/culinary-bliss/repositories"
+	"github.com/ShahSau/culinary-bliss/types"
+	"github.com/ShahSau/culinary-bliss/validation"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// apiKeyLastUsedPrecision limits how often using a key writes its last used time
+const apiKeyLastUsedPrecision = time.Minute
+
+var errInvalidApiKey = apperrors.Unauthorized("invalid API key")
+
+// CreateApiKey stores a new key scoped to a restaurant and returns it together with the plaintext key, which is not stored
+func (s *Service) CreateApiKey(ctx context.Context, actor Actor, req types.ApiKey) (models.ApiKey, string, error) {
+	if err := validation.Struct(req); err != nil {
+		return models.ApiKey{}, "", err
+	}
+	for _, permission := range req.Permissions {
+		if !helpers.IsValidPermission(permission) {
+			return models.ApiKey{}, "", apperrors.Validationf("unknown permission %q", permission)
+		}
+		if !helpers.IsApiKeyPermission(permission) {
+			return models.ApiKey{}, "", apperrors.Validationf("an API key cannot be given the %q permission, none of the routes open to API keys asks for it", permission)
+		}
+		// nobody can hand a machine more than they are allowed to do themselves
+		if !helpers.HasPermission(actor.Permissions, permission) {
+			return models.ApiKey{}, "", apperrors.Validationf("you cannot grant the %q permission", permission)
+		}
+	}
+
+	if _, err := s.GetRestaurantByID(ctx, actor, req.Restaurant_id); err != nil {
+		if errors.Is(err, apperrors.ErrNotFound) {
+			return models.ApiKey{}, "", apperrors.Validation("restaurant not found")
+		}
+		return models.ApiKey{}, "", err
+	}
+
+	key, err := helpers.GenerateApiKey()
+	if err != nil {
+		return models.ApiKey{}, "", err
+	}
+
+	createdAt, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+	apiKey := models.ApiKey{
+		ID:            primitive.NewObjectID(),
+		Name:          req.Name,
+		Prefix:        key[:len(helpers.ApiKeyPrefix)+8],
+		Key_hash:      helpers.HashToken(key),
+		Restaurant_id: req.Restaurant_id,
+		Permissions:   req.Permissions,
+		Created_by:    actor.User_id,
+		CreatedAt:     createdAt,
+		UpdatedAt:     createdAt,
+	}
+	apiKey.Key_id = apiKey.ID.Hex()
+
+	err = s.repos.ApiKeys.Create(ctx, apiKey)
+	if err != nil {
+		return models.ApiKey{}, "", err
+	}
+
+	s.RecordAuditEvent(ctx, models.AuditEvent{Event: AuditApiKeyCreated, Ip_address: actor.Ip_address, Actor_id: actor.User_id, Details: fmt.Sprintf("key_id=%s restaurant_id=%s", apiKey.Key_id, apiKey.Restaurant_id)})
+
+	return apiKey, key, nil
+}
+
+// apiKeyListing is what API keys can be filtered and sorted by
+var apiKeyListing = listing.Spec{
+	ID:          "key_id",
+	DefaultSort: "-created_at",
+	Fields: map[string]listing.Field{
+		"name":          {Kind: listing.String, Ops: listing.Equality, Sortable: true},
+		"restaurant_id": {Kind: listing.String, Ops: listing.Equality},
+		"revoked":       {Kind: listing.Bool, Ops: []listing.Op{listing.Eq}},
+		"created_at":    {Kind: listing.Time, Ops: listing.Range, Sortable: true},
+	},
+}
+
+func (s *Service) GetApiKeys(ctx context.Context, actor Actor, req types.ListRequest) (listing.Page[models.ApiKey], error) {
+	query, err := listing.Parse(apiKeyListing, req)
+	if err != nil {
+		return listing.Page[models.ApiKey]{}, err
+	}
+	return s.repos.ApiKeys.List(ctx, query)
+}
+
+// RevokeApiKey stops a key from authenticating. The key is kept so its history stays visible.
+func (s *Service) RevokeApiKey(ctx context.Context, actor Actor, keyID string) (models.ApiKey, error) {
+	revokedAt, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+	apiKey, err := s.repos.ApiKeys.Revoke(ctx, keyID, revokedAt)
+	if err != nil {
+		if err == repositories.ErrNotFound {
+			return models.ApiKey{}, apperrors.NotFound("api key not found")
+		}
+		return models.ApiKey{}, err
+	}
+
+	s.RecordAuditEvent(ctx, models.AuditEvent{Event: AuditApiKeyRevoked, Ip_address: actor.Ip_address, Actor_id: actor.User_id, Details: "key_id=" + apiKey.Key_id})
+
+	return apiKey, nil
+}
+
+// AuthenticateApiKey looks up an API key that has not been revoked and records that it was used
+func (s *Service) AuthenticateApiKey(ctx context.Context, key string) (models.ApiKey, error) {
+	if !strings.HasPrefix(key, helpers.ApiKeyPrefix) {
+		return models.ApiKey{}, errInvalidApiKey
+	}
+
+	apiKey, err := s.repos.ApiKeys.FindActiveByHash(ctx, helpers.HashToken(key))
+	if err != nil {
+		if err == repositories.ErrNotFound {
+			return models.ApiKey{}, errInvalidApiKey
+		}
+		return models.ApiKey{}, err
+	}
+
+	now, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+	if apiKey.LastUsedAt == nil || now.Sub(*apiKey.LastUsedAt) >= apiKeyLastUsedPrecision {
+		if err := s.repos.ApiKeys.TouchLastUsed(ctx, apiKey.Key_id, now); err != nil {
+			return models.ApiKey{}, err
+		}
+	}
+
+	return apiKey, nil
+}
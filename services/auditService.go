@@ -0,0 +1,30 @@
+package services
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/ShahSau/culinary-bliss/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	AuditAccountLocked   = "account_locked"
+	AuditIpLocked        = "ip_locked"
+	AuditAccountUnlocked = "account_unlocked"
+	AuditApiKeyCreated   = "api_key_created"
+	AuditApiKeyRevoked   = "api_key_revoked"
+)
+
+// RecordAuditEvent stores a security relevant event. Failing to store it is logged but never fails the request.
+func (s *Service) RecordAuditEvent(ctx context.Context, event models.AuditEvent) {
+	event.ID = primitive.NewObjectID()
+	event.CreatedAt, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+
+	log.Printf("audit: %s user_id=%q email=%q ip=%q actor_id=%q %s", event.Event, event.User_id, event.Email, event.Ip_address, event.Actor_id, event.Details)
+
+	if err := s.repos.AuditEvents.Create(ctx, event); err != nil {
+		log.Println("Error recording audit event:", err)
+	}
+}
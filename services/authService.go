@@ -1,80 +1,140 @@
 package services
 
 import (
+	"context"
 	"errors"
 	"log"
 	"time"
 
-	"github.com/ShahSau/culinary-bliss/database"
+	"github.com/ShahSau/culinary-bliss/apperrors"
 	"github.com/ShahSau/culinary-bliss/helpers"
 	"github.com/ShahSau/culinary-bliss/models"
+	"github.com/ShahSau/culinary-bliss/repositories"
 	"github.com/ShahSau/culinary-bliss/types"
-	"github.com/gin-gonic/gin"
-	"go.mongodb.org/mongo-driver/bson"
+	"github.com/ShahSau/culinary-bliss/validation"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo"
 	"golang.org/x/crypto/bcrypt"
 )
 
-var userCollection *mongo.Collection = database.GetCollection(database.DB, "users")
+// dummyPasswordHash is compared against when the email is unknown so both failures take as long.
+// It uses the same cost as HashPassword.
+const dummyPasswordHash = "$2a$14$KbD/ri5i/YkkU0g3C.zLzeie1cWilYdEXVM2VEt7Az1p0xUEY08o2"
 
-func LoginUser(user types.Loginuser, c *gin.Context) (models.User, string, string, error) {
-	var foundUser models.User
-	err := userCollection.FindOne(c.Request.Context(), bson.D{{Key: "email", Value: user.Email}}).Decode(&foundUser)
-	if err != nil {
-		return foundUser, "", "", errors.New("user not found")
+var errInvalidCredentials = apperrors.Unauthorized("invalid email or password")
+
+func (s *Service) LoginUser(ctx context.Context, actor Actor, req types.Loginuser) (models.User, string, string, error) {
+	if err := validation.Struct(req); err != nil {
+		return models.User{}, "", "", err
+	}
+	if err := s.checkLoginAllowed(ctx, req.Email, actor.Ip_address); err != nil {
+		return models.User{}, "", "", err
+	}
+
+	foundUser, err := s.repos.Users.FindByEmail(ctx, req.Email)
+	if err != nil && err != repositories.ErrNotFound {
+		return models.User{}, "", "", err
+	}
+
+	hashedPassword := foundUser.Password
+	if err == repositories.ErrNotFound {
+		hashedPassword = dummyPasswordHash
 	}
 
-	passwordIsValid, msg := ComparePassword(foundUser.Password, user.Password)
-	if !passwordIsValid {
-		return foundUser, "", "", errors.New(msg)
+	passwordIsValid, _ := ComparePassword(hashedPassword, req.Password)
+	if err == repositories.ErrNotFound || !passwordIsValid {
+		if err := s.recordLoginFailure(ctx, actor, req.Email); err != nil {
+			return models.User{}, "", "", err
+		}
+		return models.User{}, "", "", errInvalidCredentials
 	}
 
-	token, refreshToken, _ := helpers.GenerateAllTokens(foundUser.User_id, foundUser.Email, foundUser.First_name, foundUser.Last_name)
-	helpers.UpdateAllTokens(foundUser.User_id, token, refreshToken)
+	if !helpers.IsUserActive(foundUser.Status) {
+		return models.User{}, "", "", apperrors.Forbidden("email address not verified")
+	}
+
+	if err := s.mfaChallenge(ctx, foundUser); err != nil {
+		return models.User{}, "", "", err
+	}
+
+	// with a second factor pending the failures are only forgotten once CompleteMfaLogin succeeds
+	if err := s.resetLoginFailures(ctx, req.Email); err != nil {
+		return models.User{}, "", "", err
+	}
+
+	token, refreshToken, err := s.IssueTokens(ctx, actor, foundUser)
+	if err != nil {
+		return foundUser, "", "", err
+	}
 
 	return foundUser, token, refreshToken, nil
 }
 
-func RegisterUser(user models.User, c *gin.Context) (models.User, error) {
-	count, err := userCollection.CountDocuments(nil, bson.D{{Key: "email", Value: user.Email}})
+func (s *Service) RegisterUser(ctx context.Context, actor Actor, req types.RegisterUser) (models.User, error) {
+	if err := validation.Struct(req); err != nil {
+		return models.User{}, err
+	}
+	exists, err := s.repos.Users.ExistsByEmail(ctx, req.Email)
 	if err != nil {
-		return user, err
+		return models.User{}, err
 	}
-	if count > 0 {
-		return user, errors.New("email already exists")
+	if exists {
+		return models.User{}, apperrors.Conflict("email already exists")
 	}
 
-	count, err = userCollection.CountDocuments(c.Request.Context(), bson.D{{Key: "phone", Value: user.Phone}})
+	exists, err = s.repos.Users.ExistsByPhone(ctx, req.Phone)
 	if err != nil {
-		return user, err
+		return models.User{}, err
 	}
-	if count > 0 {
-		return user, errors.New("phone number already exists")
+	if exists {
+		return models.User{}, apperrors.Conflict("phone number already exists")
 	}
 
-	password := HashPassword(user.Password)
-	user.Password = password
+	var user models.User
+	user.First_name = req.FirstName
+	user.Last_name = req.LastName
+	user.Email = req.Email
+	user.Phone = req.Phone
+	user.Password = HashPassword(req.Password)
 	user.CreatedAt, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
 	user.UpdatedAt, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
 	user.ID = primitive.NewObjectID()
 	user.User_id = user.ID.Hex()
-	user.Role = "User"
+	user.Role = helpers.RoleCustomer
+	user.Status = helpers.UserStatusPending
 
-	token, refreshToken, _ := helpers.GenerateAllTokens(user.User_id, user.Email, user.First_name, user.Last_name)
-	user.Token = token
-	user.RefreshToken = refreshToken
-
-	_, err = userCollection.InsertOne(nil, user)
+	// the checks above give the friendlier message, the unique indexes catch signups racing each other
+	err = s.repos.Users.Create(ctx, user)
+	if err == repositories.ErrDuplicate {
+		return models.User{}, apperrors.Conflict("email or phone number already exists")
+	}
 	if err != nil {
-		return user, err
+		return models.User{}, err
+	}
+
+	// the account exists either way, an admin can resend the link if this fails
+	if err := s.SendVerificationEmail(ctx, user); err != nil {
+		log.Println("Error sending verification email:", err)
 	}
 
 	return user, nil
 }
 
-func LogoutUser(userID string) error {
-	helpers.UpdateAllTokens(userID, "", "")
+func (s *Service) LogoutUser(ctx context.Context, actor Actor) error {
+	if actor.User_id == "" {
+		return apperrors.Validation("API keys have no session to logout from, revoke the key instead")
+	}
+
+	err := s.RevokeToken(ctx, helpers.TokenRevocationKey(actor.Token_id), actor.User_id, actor.Token_expires_at)
+	if err != nil {
+		return err
+	}
+
+	if actor.Family_id != "" {
+		if err := s.RevokeTokenFamily(ctx, actor.Family_id); err != nil && !errors.Is(err, apperrors.ErrNotFound) {
+			return err
+		}
+	}
+
 	return nil
 }
 
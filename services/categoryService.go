@@ -1,116 +1,100 @@
 package services
 
 import (
-	"errors"
+	"context"
 	"time"
 
-	"github.com/ShahSau/culinary-bliss/database"
-	"github.com/ShahSau/culinary-bliss/helpers"
+	"github.com/ShahSau/culinary-bliss/apperrors"
+	"github.com/ShahSau/culinary-bliss/listing"
 	"github.com/ShahSau/culinary-bliss/models"
-	"github.com/gin-gonic/gin"
-	"go.mongodb.org/mongo-driver/bson"
+	"github.com/ShahSau/culinary-bliss/repositories"
+	"github.com/ShahSau/culinary-bliss/types"
+	"github.com/ShahSau/culinary-bliss/validation"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo"
 )
 
-var categoryCollection *mongo.Collection = database.GetCollection(database.DB, "categories")
-
-func GetCategories(c *gin.Context) ([]models.Category, error) {
-	var categories []models.Category
-	cursor, err := categoryCollection.Find(c.Request.Context(), bson.D{})
-	if err != nil {
-		return nil, err
-	}
-	defer cursor.Close(c.Request.Context())
-
-	for cursor.Next(c.Request.Context()) {
-		var category models.Category
-		if err := cursor.Decode(&category); err != nil {
-			return nil, err
-		}
-		categories = append(categories, category)
-	}
-
-	return categories, nil
+// categoryListing is what categories can be filtered and sorted by
+var categoryListing = listing.Spec{
+	ID:          "category_id",
+	DefaultSort: "title",
+	Fields: map[string]listing.Field{
+		"title":      {Kind: listing.String, Ops: listing.Equality, Sortable: true},
+		"created_at": {Kind: listing.Time, Ops: listing.Range, Sortable: true},
+	},
 }
 
-func GetCategoryByID(id string, c *gin.Context) (models.Category, error) {
-	var category models.Category
-	objectID, err := primitive.ObjectIDFromHex(id)
+func (s *Service) GetCategories(ctx context.Context, actor Actor, req types.ListRequest) (listing.Page[models.Category], error) {
+	query, err := listing.Parse(categoryListing, req)
 	if err != nil {
-		return category, errors.New("invalid category ID")
+		return listing.Page[models.Category]{}, err
 	}
+	return s.repos.Categories.List(ctx, query)
+}
 
-	err = categoryCollection.FindOne(c.Request.Context(), bson.M{"_id": objectID}).Decode(&category)
+func (s *Service) GetCategoryByID(ctx context.Context, actor Actor, id string) (models.Category, error) {
+	category, err := s.repos.Categories.FindByID(ctx, id)
 	if err != nil {
-		return category, errors.New("category not found")
+		if err == repositories.ErrNotFound {
+			return models.Category{}, apperrors.NotFound("category not found")
+		}
+		return models.Category{}, err
 	}
 
 	return category, nil
 }
 
-func CreateCategory(category models.Category, c *gin.Context) (models.Category, error) {
-
-	userEmail, _ := c.Get("first_name")
-	var isAdmin = helpers.IsAdmin(userEmail.(string))
-
-	if !isAdmin {
-		return category, errors.New("unauthorized")
+func (s *Service) CreateCategory(ctx context.Context, actor Actor, req types.Category) (models.Category, error) {
+	if err := validation.Struct(req); err != nil {
+		return models.Category{}, err
 	}
+
 	var newCategory models.Category
-	newCategory.Title = category.Title
-	newCategory.Image = category.Image
+	newCategory.Title = req.Title
+	newCategory.Image = req.Image
 	newCategory.ID = primitive.NewObjectID()
 	newCategory.Category_id = newCategory.ID.Hex()
 	newCategory.CreatedAt, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
 	newCategory.UpdatedAt, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
 
-	_, err := categoryCollection.InsertOne(c.Request.Context(), newCategory)
+	err := s.repos.Categories.Create(ctx, newCategory)
 	if err != nil {
-		return category, err
+		return models.Category{}, err
 	}
 
-	return category, nil
+	return newCategory, nil
 }
 
-func UpdateCategory(id string, updatedCategory models.Category, c *gin.Context) (models.Category, error) {
-	categoryID, err := primitive.ObjectIDFromHex(id)
+func (s *Service) UpdateCategory(ctx context.Context, actor Actor, id string, req types.CategoryUpdate) (models.Category, error) {
+	if err := validation.Struct(req); err != nil {
+		return models.Category{}, err
+	}
+	category, err := s.GetCategoryByID(ctx, actor, id)
 	if err != nil {
-		return updatedCategory, errors.New("invalid category ID")
+		return models.Category{}, err
 	}
-	var category models.Category
 
-	category.Title = updatedCategory.Title
-	category.Image = updatedCategory.Image
+	if req.Title != nil {
+		category.Title = *req.Title
+	}
+	if req.Image != nil {
+		category.Image = *req.Image
+	}
 	category.UpdatedAt, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
-	category.CreatedAt = updatedCategory.CreatedAt
-	category.ID = updatedCategory.ID
-	category.Category_id = updatedCategory.Category_id
-	update := bson.M{"$set": category}
 
-	_, err = categoryCollection.UpdateOne(c.Request.Context(), bson.M{"_id": categoryID}, update)
+	err = s.repos.Categories.Update(ctx, category)
 	if err != nil {
-		return category, err
+		return models.Category{}, err
 	}
 
 	return category, nil
 }
 
-func DeleteCategory(id string, c *gin.Context) error {
-	categoryID, err := primitive.ObjectIDFromHex(id)
-	if err != nil {
-		return errors.New("invalid category ID")
-	}
-
-	userEmail, _ := c.Get("first_name")
-	var isAdmin = helpers.IsAdmin(userEmail.(string))
-
-	if !isAdmin {
-		return errors.New("unauthorized")
-	}
-
-	_, err = categoryCollection.DeleteOne(c.Request.Context(), bson.M{"_id": categoryID})
+func (s *Service) DeleteCategory(ctx context.Context, actor Actor, id string) error {
+	err := s.repos.Categories.Delete(ctx, id)
 	if err != nil {
+		if err == repositories.ErrNotFound {
+			return apperrors.NotFound("category not found")
+		}
 		return err
 	}
 
@@ -0,0 +1,136 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ShahSau/culinary-bliss/apperrors"
+	"github.com/ShahSau/culinary-bliss/helpers"
+	"github.com/ShahSau/culinary-bliss/mailer"
+	"github.com/ShahSau/culinary-bliss/models"
+	"github.com/ShahSau/culinary-bliss/repositories"
+	"github.com/ShahSau/culinary-bliss/types"
+	"github.com/ShahSau/culinary-bliss/validation"
+)
+
+// emailVerificationTTL is how long an emailed verification link can be used
+const emailVerificationTTL = time.Hour * 48
+
+func (s *Service) SendVerificationEmail(ctx context.Context, user models.User) error {
+	token, err := issueOneTimeToken(ctx, s.repos.EmailVerifications, user.User_id, emailVerificationTTL)
+	if err != nil {
+		return err
+	}
+
+	return s.mail.Send(mailer.Message{
+		To:      user.Email,
+		Subject: "Verify your Culinary Bliss email address",
+		Body: fmt.Sprintf("Hi %s,\n\nWelcome to Culinary Bliss! Please confirm your email address by opening the link below within %d hours:\n\n%s\n\n"+
+			"If you did not create an account you can ignore this email.",
+			user.First_name, int(emailVerificationTTL.Hours()), s.pageURL("/verify-email?token="+token)),
+	})
+}
+
+// SendEmailChangeVerification sends a link to the new address, the account only moves to it once the link is followed
+func (s *Service) SendEmailChangeVerification(ctx context.Context, user models.User, email string) error {
+	token, err := issueOneTimeTokenFor(ctx, s.repos.EmailVerifications, models.OneTimeToken{User_id: user.User_id, Email: email}, emailVerificationTTL)
+	if err != nil {
+		return err
+	}
+
+	return s.mail.Send(mailer.Message{
+		To:      email,
+		Subject: "Confirm your new Culinary Bliss email address",
+		Body: fmt.Sprintf("Hi %s,\n\nPlease confirm that your Culinary Bliss account should use this email address by opening the link below within %d hours:\n\n%s\n\n"+
+			"Until then your account keeps using its current address. If you did not ask for this you can ignore this email.",
+			user.First_name, int(emailVerificationTTL.Hours()), s.pageURL("/verify-email?token="+token)),
+	})
+}
+
+// VerifyEmail consumes a verification token and activates the account it was sent to, or moves the account to the new address it confirms
+func (s *Service) VerifyEmail(ctx context.Context, actor Actor, req types.VerifyEmail) (models.User, error) {
+	if err := validation.Struct(req); err != nil {
+		return models.User{}, err
+	}
+	verification, err := consumeOneTimeToken(ctx, s.repos.EmailVerifications, req.Token)
+	if err != nil {
+		return models.User{}, err
+	}
+
+	if verification.Email != "" {
+		return s.changeEmail(ctx, verification.User_id, verification.Email)
+	}
+	return s.activateUser(ctx, verification.User_id)
+}
+
+// changeEmail moves an account to an address that was just verified, unless another account took the address meanwhile
+func (s *Service) changeEmail(ctx context.Context, userID string, email string) (models.User, error) {
+	user, err := s.findUserByID(ctx, userID)
+	if err != nil {
+		return models.User{}, err
+	}
+	exists, err := s.repos.Users.ExistsByEmail(ctx, email)
+	if err != nil {
+		return models.User{}, err
+	}
+	if exists {
+		return models.User{}, apperrors.Conflict("email already exists")
+	}
+
+	now, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+	user.Email = email
+	user.VerifiedAt = &now
+	user.UpdatedAt = now
+
+	err = s.repos.Users.Update(ctx, user)
+	if err == repositories.ErrDuplicate {
+		return models.User{}, apperrors.Conflict("email already exists")
+	}
+	if err != nil {
+		return models.User{}, err
+	}
+	return user, nil
+}
+
+// ResendVerificationEmail sends a fresh verification link, invalidating the previous one
+func (s *Service) ResendVerificationEmail(ctx context.Context, actor Actor, userID string) error {
+	user, err := s.findUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if helpers.IsUserActive(user.Status) {
+		return apperrors.Validation("user is already verified")
+	}
+
+	return s.SendVerificationEmail(ctx, user)
+}
+
+// VerifyUser activates an account without the user following the emailed link
+func (s *Service) VerifyUser(ctx context.Context, actor Actor, userID string) (models.User, error) {
+	user, err := s.activateUser(ctx, userID)
+	if err != nil {
+		return models.User{}, err
+	}
+
+	err = s.repos.EmailVerifications.InvalidateUnused(ctx, userID, user.UpdatedAt)
+	if err != nil {
+		return models.User{}, err
+	}
+
+	return user, nil
+}
+
+func (s *Service) activateUser(ctx context.Context, userID string) (models.User, error) {
+	now, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+	user, err := s.repos.Users.Activate(ctx, userID, helpers.UserStatusActive, now)
+	if err != nil {
+		if err == repositories.ErrNotFound {
+			return models.User{}, apperrors.NotFound("user not found")
+		}
+		return models.User{}, err
+	}
+
+	return user, nil
+}
@@ -1,206 +1,148 @@
 package services
 
 import (
-	"errors"
-	"math"
-	"strconv"
+	"context"
 	"time"
 
-	"github.com/ShahSau/culinary-bliss/database"
-	"github.com/ShahSau/culinary-bliss/helpers"
+	"github.com/ShahSau/culinary-bliss/apperrors"
+	"github.com/ShahSau/culinary-bliss/listing"
 	"github.com/ShahSau/culinary-bliss/models"
-	"github.com/gin-gonic/gin"
-	"go.mongodb.org/mongo-driver/bson"
+	"github.com/ShahSau/culinary-bliss/repositories"
+	"github.com/ShahSau/culinary-bliss/types"
+	"github.com/ShahSau/culinary-bliss/validation"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo"
 )
 
-var foodCollection *mongo.Collection = database.GetCollection(database.DB, "food")
-
-func GetFoods(c *gin.Context) (models.Response, error) {
-	recordPerPage, err := strconv.Atoi(c.Query("recordPerPage"))
-	if err != nil || recordPerPage < 1 {
-		recordPerPage = 10
-	}
-
-	page, err := strconv.Atoi(c.Query("page"))
-	if err != nil || page < 1 {
-		page = 1
-	}
-
-	startIndex := (page - 1) * recordPerPage
-
-	matchStage := bson.D{{Key: "$match", Value: bson.D{}}}
-	projectStage := bson.D{{Key: "$project", Value: bson.D{
-		{Key: "id", Value: 1},
-		{Key: "name", Value: 1},
-		{Key: "image", Value: 1},
-		{Key: "description", Value: 1},
-		{Key: "price", Value: 1},
-		{Key: "menu_id", Value: 1},
-	}}}
-	skipStage := bson.D{{Key: "$skip", Value: startIndex}}
-	limitStage := bson.D{{Key: "$limit", Value: recordPerPage}}
-
-	cursor, err := foodCollection.Aggregate(c.Request.Context(), mongo.Pipeline{
-		matchStage, projectStage, skipStage, limitStage,
-	})
-	if err != nil {
-		return models.Response{}, err
-	}
-	defer cursor.Close(c.Request.Context())
-
-	var foods []models.Food
-	for cursor.Next(c.Request.Context()) {
-		var food models.Food
-		if err := cursor.Decode(&food); err != nil {
-			return models.Response{}, err
-		}
-		foods = append(foods, food)
-	}
-
-	response := models.Response{
-		AllFoods:      foods,
-		Page:          page,
-		RecordPerPage: recordPerPage,
-		StartIndex:    startIndex,
-	}
-
-	return response, nil
+// foodListing is what foods can be filtered and sorted by
+var foodListing = listing.Spec{
+	ID:          "food_id",
+	DefaultSort: "-created_at",
+	Fields: map[string]listing.Field{
+		"name":       {Kind: listing.String, Ops: listing.Equality, Sortable: true},
+		"price":      {Kind: listing.Money, Ops: listing.Range, Sortable: true},
+		"menu_id":    {Kind: listing.String, Ops: listing.Equality},
+		"created_at": {Kind: listing.Time, Ops: listing.Range, Sortable: true},
+	},
 }
 
-func GetFoodByID(id string, c *gin.Context) (models.Food, error) {
-	var food models.Food
-	foodID, err := primitive.ObjectIDFromHex(id)
+func (s *Service) GetFoods(ctx context.Context, actor Actor, req types.ListRequest) (listing.Page[models.Food], error) {
+	query, err := listing.Parse(foodListing, req)
 	if err != nil {
-		return food, errors.New("invalid food ID")
+		return listing.Page[models.Food]{}, err
 	}
+	return s.repos.Foods.List(ctx, query)
+}
 
-	err = foodCollection.FindOne(c.Request.Context(), bson.M{"_id": foodID}).Decode(&food)
+func (s *Service) GetFoodByID(ctx context.Context, actor Actor, id string) (models.Food, error) {
+	food, err := s.repos.Foods.FindByID(ctx, id)
 	if err != nil {
-		return food, errors.New("food not found")
+		if err == repositories.ErrNotFound {
+			return models.Food{}, apperrors.NotFound("food not found")
+		}
+		return models.Food{}, err
 	}
 
 	return food, nil
 }
 
-func CreateFood(reqfood models.Food, c *gin.Context) (models.Food, error) {
-	var menu models.Menu
-	var food models.Food
-	userEmail, _ := c.Get("first_name")
-	var isAdmin = helpers.IsAdmin(userEmail.(string))
-
-	if !isAdmin {
-		return reqfood, errors.New("unauthorized")
+func (s *Service) CreateFood(ctx context.Context, actor Actor, req types.Food) (models.Food, error) {
+	if err := validation.Struct(req); err != nil {
+		return models.Food{}, err
 	}
+	var food models.Food
 
 	// Check if the menu exists
-	err := database.GetCollection(database.DB, "menu").FindOne(c.Request.Context(), primitive.M{"menu_id": reqfood.Menu_id}).Decode(&menu)
-
+	_, err := s.GetMenuByID(ctx, actor, req.Menu_id)
 	if err != nil {
-		return reqfood, errors.New("menu not found")
+		return models.Food{}, err
 	}
 
 	food.CreatedAt, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
 	food.UpdatedAt, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
-	food.Name = reqfood.Name
-	food.Description = reqfood.Description
-	food.Price = toFixed(reqfood.Price, 2)
-	food.Image = reqfood.Image
-	food.Menu_id = reqfood.Menu_id
+	food.Name = req.Name
+	food.Description = req.Description
+	food.Price = req.Price
+	food.Image = req.Image
+	food.Menu_id = req.Menu_id
+	food.Modifiers = modifiersFrom(req.Modifiers)
 	food.ID = primitive.NewObjectID()
 	food.Food_id = food.ID.Hex()
 
-	_, err = foodCollection.InsertOne(c.Request.Context(), food)
+	err = s.repos.Foods.Create(ctx, food)
 	if err != nil {
-		return food, err
+		return models.Food{}, err
 	}
 
 	return food, nil
 }
 
-func UpdateFood(id string, reqfood models.Food, c *gin.Context) (models.Food, error) {
-	var menu models.Menu
-	foodID, err := primitive.ObjectIDFromHex(id)
+func (s *Service) UpdateFood(ctx context.Context, actor Actor, id string, req types.FoodUpdate) (models.Food, error) {
+	if err := validation.Struct(req); err != nil {
+		return models.Food{}, err
+	}
+	food, err := s.GetFoodByID(ctx, actor, id)
 	if err != nil {
-		return reqfood, errors.New("invalid food ID")
+		return models.Food{}, err
 	}
 
-	userEmail, _ := c.Get("first_name")
-	var isAdmin = helpers.IsAdmin(userEmail.(string))
-
-	if !isAdmin {
-		return reqfood, errors.New("unauthorized")
+	if req.Name != nil {
+		food.Name = *req.Name
 	}
 
-	var updateObj primitive.D
-
-	if reqfood.Name != "" {
-		updateObj = append(updateObj, primitive.E{Key: "name", Value: reqfood.Name})
+	if req.Description != nil {
+		food.Description = *req.Description
 	}
 
-	if reqfood.Description != "" {
-		updateObj = append(updateObj, primitive.E{Key: "description", Value: reqfood.Description})
+	if req.Price != nil {
+		food.Price = *req.Price
 	}
 
-	if reqfood.Price != 0 {
-		updateObj = append(updateObj, primitive.E{Key: "price", Value: reqfood.Price})
+	if req.Image != nil {
+		food.Image = *req.Image
 	}
 
-	if reqfood.Image != "" {
-		updateObj = append(updateObj, primitive.E{Key: "image", Value: reqfood.Image})
+	if req.Modifiers != nil {
+		food.Modifiers = modifiersFrom(*req.Modifiers)
 	}
 
-	if reqfood.Menu_id != "" {
-		err := database.GetCollection(database.DB, "menu").FindOne(c.Request.Context(), primitive.M{"menu_id": reqfood.Menu_id}).Decode(&menu)
-
+	if req.Menu_id != nil {
+		_, err := s.GetMenuByID(ctx, actor, *req.Menu_id)
 		if err != nil {
-			return reqfood, errors.New("menu not found")
+			return models.Food{}, err
 		}
 
-		updateObj = append(updateObj, primitive.E{Key: "menu_id", Value: reqfood.Menu_id})
+		food.Menu_id = *req.Menu_id
 	}
 
-	reqfood.UpdatedAt, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
-
-	updateObj = append(updateObj, primitive.E{Key: "updated_at", Value: reqfood.UpdatedAt})
-
-	_, err = foodCollection.UpdateOne(c.Request.Context(), bson.M{"food_id": foodID}, bson.D{{Key: "$set", Value: updateObj}})
+	food.UpdatedAt, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
 
+	err = s.repos.Foods.Update(ctx, food)
 	if err != nil {
-		return reqfood, err
+		return models.Food{}, err
 	}
 
-	return reqfood, nil
+	return food, nil
 }
 
-func DeleteFood(id string, c *gin.Context) (models.Food, error) {
-	var food models.Food
-	foodID, err := primitive.ObjectIDFromHex(id)
+func (s *Service) DeleteFood(ctx context.Context, actor Actor, id string) (models.Food, error) {
+	food, err := s.GetFoodByID(ctx, actor, id)
 	if err != nil {
-		return food, errors.New("invalid food ID")
+		return models.Food{}, err
 	}
 
-	userEmail, _ := c.Get("first_name")
-	var isAdmin = helpers.IsAdmin(userEmail.(string))
-
-	if !isAdmin {
-		return food, errors.New("unauthorized")
-	}
-
-	result, err := foodCollection.DeleteOne(c.Request.Context(), bson.M{"_id": foodID})
+	err = s.repos.Foods.Delete(ctx, id)
 	if err != nil {
-		return food, err
-	}
-
-	if result.DeletedCount == 0 {
-		return food, errors.New("food not found")
+		return models.Food{}, err
 	}
 
 	return food, nil
 }
 
-func toFixed(num float64, precision int) float64 {
-	output := math.Pow(10, float64(precision))
-	return float64(int(num*output)) / output
+// modifiersFrom is never nil so a food without modifiers lists an empty list
+func modifiersFrom(req []types.Modifier) []models.Modifier {
+	modifiers := make([]models.Modifier, 0, len(req))
+	for _, modifier := range req {
+		modifiers = append(modifiers, models.Modifier{Name: modifier.Name, Price: modifier.Price})
+	}
+	return modifiers
 }
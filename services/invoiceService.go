@@ -1,83 +1,71 @@
 package services
 
 import (
-	"errors"
+	"context"
 	"time"
 
-	"github.com/ShahSau/culinary-bliss/database"
-	"github.com/ShahSau/culinary-bliss/helpers"
+	"github.com/ShahSau/culinary-bliss/apperrors"
+	"github.com/ShahSau/culinary-bliss/listing"
 	"github.com/ShahSau/culinary-bliss/models"
-	"github.com/gin-gonic/gin"
-	"go.mongodb.org/mongo-driver/bson"
+	"github.com/ShahSau/culinary-bliss/repositories"
+	"github.com/ShahSau/culinary-bliss/types"
+	"github.com/ShahSau/culinary-bliss/validation"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo"
 )
 
-var invoiceCollection *mongo.Collection = database.GetCollection(database.DB, "invoice")
-
-func GetInvoices(c *gin.Context) ([]models.InvoiceViewFormat, error) {
-	invoices, err := invoiceCollection.Find(c.Request.Context(), bson.M{}, nil)
+// invoiceListing is what invoices can be filtered and sorted by
+var invoiceListing = listing.Spec{
+	ID:          "invoice_id",
+	DefaultSort: "-created_at",
+	Fields: map[string]listing.Field{
+		"order_id":         {Kind: listing.String, Ops: listing.Equality},
+		"payment_status":   {Kind: listing.String, Ops: listing.Equality},
+		"payment_method":   {Kind: listing.String, Ops: listing.Equality},
+		"total_amount":     {Kind: listing.Money, Ops: listing.Range, Sortable: true},
+		"payment_due_date": {Kind: listing.Time, Ops: listing.Range, Sortable: true},
+		"created_at":       {Kind: listing.Time, Ops: listing.Range, Sortable: true},
+	},
+}
 
+func (s *Service) GetInvoices(ctx context.Context, actor Actor, req types.ListRequest) (listing.Page[models.InvoiceViewFormat], error) {
+	query, err := listing.Parse(invoiceListing, req)
 	if err != nil {
-		return nil, err
+		return listing.Page[models.InvoiceViewFormat]{}, err
 	}
-
-	userEmail, _ := c.Get("first_name")
-	var isAdmin = helpers.IsAdmin(userEmail.(string))
-
-	if !isAdmin {
-		return nil, errors.New("you are not authorized to view this resource")
+	invoices, err := s.repos.Invoices.List(ctx, query)
+	if err != nil {
+		return listing.Page[models.InvoiceViewFormat]{}, err
 	}
 
-	defer invoices.Close(c.Request.Context())
-
-	var results []models.InvoiceViewFormat
-
-	for invoices.Next(c.Request.Context()) {
-		var invoice models.InvoiceViewFormat
-		err := invoices.Decode(&invoice)
-		if err != nil {
-			return nil, err
-		}
-		results = append(results, invoice)
+	results := listing.Page[models.InvoiceViewFormat]{Items: []models.InvoiceViewFormat{}, Total: invoices.Total, Limit: invoices.Limit, NextCursor: invoices.NextCursor}
+	for _, invoice := range invoices.Items {
+		results.Items = append(results.Items, invoiceView(invoice))
 	}
 
 	return results, nil
 }
 
-func GetInvoiceByID(c *gin.Context, invoiceID string) (models.InvoiceViewFormat, error) {
-	var invoice models.InvoiceViewFormat
-
-	id, err := primitive.ObjectIDFromHex(invoiceID)
+func (s *Service) GetInvoiceByID(ctx context.Context, actor Actor, invoiceID string) (models.InvoiceViewFormat, error) {
+	invoice, err := s.findInvoice(ctx, invoiceID)
 	if err != nil {
 		return models.InvoiceViewFormat{}, err
 	}
 
-	err = invoiceCollection.FindOne(c.Request.Context(), bson.M{"_id": id}).Decode(&invoice)
-	if err != nil {
-		if err == mongo.ErrNoDocuments {
-			return models.InvoiceViewFormat{}, errors.New("invoice not found")
-		}
-		return invoice, err
-	}
-
-	return invoice, nil
+	return invoiceView(invoice), nil
 }
 
-func CreateInvoice(c *gin.Context, reqInvoice models.Invoice) (models.Invoice, error) {
-	var orderCollection *mongo.Collection = database.GetCollection(database.DB, "orders")
-
-	var order models.Order
-
-	err := orderCollection.FindOne(c.Request.Context(), bson.M{"order_id": reqInvoice.Order_id}).Decode(&order)
-
+func (s *Service) CreateInvoice(ctx context.Context, actor Actor, req types.Invoice) (models.Invoice, error) {
+	if err := validation.Struct(req); err != nil {
+		return models.Invoice{}, err
+	}
+	order, err := s.GetOrderById(ctx, actor, req.Order_id)
 	if err != nil {
 		return models.Invoice{}, err
 	}
 
 	var invoice models.Invoice
-	invoice.Order_id = reqInvoice.Order_id
-	invoice.Payment_method = reqInvoice.Payment_method
+	invoice.Order_id = req.Order_id
+	invoice.Payment_method = req.Payment_method
 	invoice.Payment_status = "PENDING"
 	invoice.Payment_due_date, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
 	invoice.CreatedAt, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
@@ -86,44 +74,38 @@ func CreateInvoice(c *gin.Context, reqInvoice models.Invoice) (models.Invoice, e
 	invoice.Invoice_id = invoice.ID.Hex()
 	invoice.Total_amount = order.Total_amount
 
-	_, err = invoiceCollection.InsertOne(c.Request.Context(), invoice)
-
+	err = s.repos.Invoices.Create(ctx, invoice)
 	if err != nil {
 		return models.Invoice{}, err
 	}
 	return invoice, nil
 }
 
-func UpdateInvoice(c *gin.Context, invoiceID string, reqInvoice models.Invoice) (models.Invoice, error) {
-	var invoice models.Invoice
-
-	id, err := primitive.ObjectIDFromHex(invoiceID)
-	if err != nil {
+func (s *Service) UpdateInvoice(ctx context.Context, actor Actor, invoiceID string, req types.InvoiceUpdate) (models.Invoice, error) {
+	if err := validation.Struct(req); err != nil {
 		return models.Invoice{}, err
 	}
-
-	err = invoiceCollection.FindOne(c.Request.Context(), bson.M{"_id": id}).Decode(&invoice)
+	invoice, err := s.findInvoice(ctx, invoiceID)
 	if err != nil {
-		if err == mongo.ErrNoDocuments {
-			return models.Invoice{}, errors.New("invoice not found")
-		}
-		return invoice, err
+		return models.Invoice{}, err
 	}
 
-	var orderCollection *mongo.Collection = database.GetCollection(database.DB, "orders")
-
-	var order models.Order
-
-	err = orderCollection.FindOne(c.Request.Context(), bson.M{"order_id": invoice.Order_id}).Decode(&order)
-
+	orderID := invoice.Order_id
+	if req.Order_id != nil {
+		orderID = *req.Order_id
+	}
+	order, err := s.GetOrderById(ctx, actor, orderID)
 	if err != nil {
-		return models.Invoice{}, errors.New(err.Error() + " Order not found")
+		return models.Invoice{}, err
 	}
 
 	var updateObj models.Invoice
 
-	updateObj.Order_id = reqInvoice.Order_id
-	updateObj.Payment_method = reqInvoice.Payment_method
+	updateObj.Order_id = orderID
+	updateObj.Payment_method = invoice.Payment_method
+	if req.Payment_method != nil {
+		updateObj.Payment_method = *req.Payment_method
+	}
 	updateObj.Payment_status = "PENDING"
 	updateObj.Payment_due_date, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
 	updateObj.CreatedAt, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
@@ -132,33 +114,44 @@ func UpdateInvoice(c *gin.Context, invoiceID string, reqInvoice models.Invoice)
 	updateObj.Invoice_id = invoice.Invoice_id
 	updateObj.Total_amount = order.Total_amount
 
-	_, err = invoiceCollection.UpdateOne(c.Request.Context(), bson.M{"invoice_id": invoiceID}, bson.D{{Key: "$set", Value: updateObj}})
-
+	err = s.repos.Invoices.Update(ctx, updateObj)
 	if err != nil {
 		return models.Invoice{}, err
 	}
 	return updateObj, nil
 }
 
-func DeleteInvoice(c *gin.Context, invoiceID string) error {
-	id, err := primitive.ObjectIDFromHex(invoiceID)
+func (s *Service) DeleteInvoice(ctx context.Context, actor Actor, invoiceID string) error {
+	err := s.repos.Invoices.Delete(ctx, invoiceID)
 	if err != nil {
+		if err == repositories.ErrNotFound {
+			return apperrors.NotFound("invoice not found")
+		}
 		return err
 	}
 
-	userEmail, _ := c.Get("first_name")
-	var isAdmin = helpers.IsAdmin(userEmail.(string))
+	return nil
+}
 
-	if !isAdmin {
-		return errors.New("you are not authorized to delete this resource")
-	}
-	_, err = invoiceCollection.DeleteOne(c.Request.Context(), bson.M{"_id": id})
+func (s *Service) findInvoice(ctx context.Context, invoiceID string) (models.Invoice, error) {
+	invoice, err := s.repos.Invoices.FindByID(ctx, invoiceID)
 	if err != nil {
-		if err == mongo.ErrNoDocuments {
-			return errors.New("invoice not found")
+		if err == repositories.ErrNotFound {
+			return models.Invoice{}, apperrors.NotFound("invoice not found")
 		}
-		return err
+		return models.Invoice{}, err
 	}
+	return invoice, nil
+}
 
-	return nil
+// invoiceView is the shape invoices are returned in
+func invoiceView(invoice models.Invoice) models.InvoiceViewFormat {
+	return models.InvoiceViewFormat{
+		Invoice_id:        invoice.Invoice_id,
+		Payment_method:    invoice.Payment_method,
+		Order_id:          invoice.Order_id,
+		Payment_status:    invoice.Payment_status,
+		Payment_due:       invoice.Total_amount,
+		Paymenet_due_date: invoice.Payment_due_date,
+	}
 }
@@ -0,0 +1,156 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ShahSau/culinary-bliss/apperrors"
+	"github.com/ShahSau/culinary-bliss/models"
+)
+
+const (
+	// failures before every further attempt has to wait, doubling each time up to maxLoginDelay
+	loginDelayAfter = 3
+	maxLoginDelay   = 30 * time.Second
+
+	maxAccountFailures = 5
+	maxIPFailures      = 20
+
+	// the first lockout lasts lockoutDuration, every following one twice as long up to maxLockoutDuration
+	lockoutDuration    = 15 * time.Minute
+	maxLockoutDuration = 24 * time.Hour
+
+	// counters are forgotten once there has been no failure for this long
+	loginFailureWindow = time.Hour
+)
+
+// LoginThrottledError is returned when an account or IP address has to wait before trying to login again
+type LoginThrottledError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LoginThrottledError) Error() string {
+	return "too many failed login attempts, please try again later"
+}
+
+func (e *LoginThrottledError) Unwrap() error {
+	return apperrors.ErrTooManyRequests
+}
+
+func accountAttemptKey(email string) string {
+	return "account:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+func ipAttemptKey(ip string) string {
+	return "ip:" + ip
+}
+
+// checkLoginAllowed returns a LoginThrottledError if the account or IP address is locked or has to wait before the next attempt
+func (s *Service) checkLoginAllowed(ctx context.Context, email string, ip string) error {
+	attempts, err := s.repos.LoginAttempts.FindByKeys(ctx, []string{accountAttemptKey(email), ipAttemptKey(ip)})
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	var retryAfter time.Duration
+	for _, attempt := range attempts {
+		var wait time.Duration
+		if attempt.LockedUntil.After(now) {
+			wait = attempt.LockedUntil.Sub(now)
+		} else if attempt.Failures >= loginDelayAfter {
+			wait = attempt.LastFailureAt.Add(loginDelay(attempt.Failures)).Sub(now)
+		}
+		if wait > retryAfter {
+			retryAfter = wait
+		}
+	}
+
+	if retryAfter > 0 {
+		return &LoginThrottledError{RetryAfter: retryAfter.Round(time.Second) + time.Second}
+	}
+
+	return nil
+}
+
+// recordLoginFailure counts a failed attempt against the account and the IP address and locks them once they reach their limit
+func (s *Service) recordLoginFailure(ctx context.Context, actor Actor, email string) error {
+	ip := actor.Ip_address
+
+	locked, err := s.countLoginFailure(ctx, accountAttemptKey(email), maxAccountFailures)
+	if err != nil {
+		return err
+	}
+	if locked > 0 {
+		s.RecordAuditEvent(ctx, models.AuditEvent{Event: AuditAccountLocked, Email: email, Ip_address: ip, Details: fmt.Sprintf("locked for %s", locked)})
+	}
+
+	locked, err = s.countLoginFailure(ctx, ipAttemptKey(ip), maxIPFailures)
+	if err != nil {
+		return err
+	}
+	if locked > 0 {
+		s.RecordAuditEvent(ctx, models.AuditEvent{Event: AuditIpLocked, Email: email, Ip_address: ip, Details: fmt.Sprintf("locked for %s", locked)})
+	}
+
+	return nil
+}
+
+// countLoginFailure increments the failure counter of the key and returns how long it got locked for, if at all
+func (s *Service) countLoginFailure(ctx context.Context, key string, maxFailures int) (time.Duration, error) {
+	now, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+	attempt, err := s.repos.LoginAttempts.RecordFailure(ctx, key, now, now.Add(loginFailureWindow))
+	if err != nil {
+		return 0, err
+	}
+
+	if attempt.Failures < maxFailures {
+		return 0, nil
+	}
+
+	duration := lockoutDuration << attempt.Lockouts
+	if duration > maxLockoutDuration || duration <= 0 {
+		duration = maxLockoutDuration
+	}
+	lockedUntil := now.Add(duration)
+
+	// a fresh set of attempts once the lock runs out, but the lockout count is kept so the next one lasts longer
+	err = s.repos.LoginAttempts.Lock(ctx, key, lockedUntil, lockedUntil.Add(loginFailureWindow))
+	if err != nil {
+		return 0, err
+	}
+
+	return duration, nil
+}
+
+// resetLoginFailures forgets the failed attempts of an account after a successful login or an unlock.
+// Its lockouts still count until the window runs out, so the next lockout lasts longer.
+func (s *Service) resetLoginFailures(ctx context.Context, email string) error {
+	return s.repos.LoginAttempts.ClearFailures(ctx, accountAttemptKey(email))
+}
+
+// UnlockUser lifts the lockout of a user's account
+func (s *Service) UnlockUser(ctx context.Context, actor Actor, userID string) error {
+	user, err := s.findUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.resetLoginFailures(ctx, user.Email); err != nil {
+		return err
+	}
+
+	s.RecordAuditEvent(ctx, models.AuditEvent{Event: AuditAccountUnlocked, User_id: user.User_id, Email: user.Email, Ip_address: actor.Ip_address, Actor_id: actor.User_id})
+
+	return nil
+}
+
+func loginDelay(failures int) time.Duration {
+	delay := time.Second << (failures - loginDelayAfter)
+	if delay > maxLoginDelay || delay <= 0 {
+		return maxLoginDelay
+	}
+	return delay
+}
@@ -0,0 +1,6 @@
+package services
+
+// pageURL returns the URL of a front end page linked from emails
+func (s *Service) pageURL(path string) string {
+	return s.appURL + path
+}
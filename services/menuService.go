@@ -1,168 +1,115 @@
 package services
 
 import (
-	"errors"
-	"log"
-	"strconv"
+	"context"
 	"time"
 
-	"github.com/ShahSau/culinary-bliss/database"
-	"github.com/ShahSau/culinary-bliss/helpers"
+	"github.com/ShahSau/culinary-bliss/apperrors"
+	"github.com/ShahSau/culinary-bliss/listing"
 	"github.com/ShahSau/culinary-bliss/models"
-	"github.com/gin-gonic/gin"
-	"go.mongodb.org/mongo-driver/bson"
+	"github.com/ShahSau/culinary-bliss/repositories"
+	"github.com/ShahSau/culinary-bliss/types"
+	"github.com/ShahSau/culinary-bliss/validation"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo"
 )
 
-var menuCollection *mongo.Collection = database.GetCollection(database.DB, "menu")
-
-func GetMenus(c *gin.Context) (models.ResponseMenu, error) {
-	recordPerPage, err := strconv.Atoi(c.Query("recordPerPage"))
-	if err != nil || recordPerPage < 1 {
-		recordPerPage = 10
-	}
-
-	page, errp := strconv.Atoi(c.Query("page"))
-	if errp != nil || page < 1 {
-		page = 1
-	}
-
-	startIndex := (page - 1) * recordPerPage
-	startIndex, err = strconv.Atoi(c.Query("startIndex"))
-
-	matchStage := bson.D{{Key: "$match", Value: bson.D{{}}}}
-	projectStage := bson.D{
-		{
-			Key: "$project", Value: bson.D{
-				{Key: "id", Value: 1},
-				{Key: "name", Value: 1},
-				{Key: "description", Value: 1},
-				{Key: "start_date", Value: 1},
-				{Key: "end_date", Value: 1},
-				{Key: "menu_id", Value: 1},
-			},
-		},
-	}
-
-	record := bson.D{{Key: "$skip", Value: recordPerPage * (page - 1)}}
-	limit := bson.D{{Key: "$limit", Value: recordPerPage}}
-
-	result, errAgg := menuCollection.Aggregate(c.Request.Context(), mongo.Pipeline{matchStage, projectStage, record, limit})
-
-	if errAgg != nil {
-		return models.ResponseMenu{}, errAgg
-	}
-
-	var allMenus []bson.M
-	if err = result.All(c.Request.Context(), &allMenus); err != nil {
-		log.Fatal(err)
-	}
-
-	var menus []models.Menu
-	for _, menu := range allMenus {
-		var m models.Menu
-		bsonBytes, _ := bson.Marshal(menu)
-		bson.Unmarshal(bsonBytes, &m)
-		menus = append(menus, m)
-	}
-
-	response := models.ResponseMenu{
-		AllMenus:      menus,
-		Page:          page,
-		RecordPerPage: recordPerPage,
-		StartIndex:    startIndex,
-	}
-	return response, nil
+// menuListing is what menus can be filtered and sorted by
+var menuListing = listing.Spec{
+	ID:          "menu_id",
+	DefaultSort: "-created_at",
+	Fields: map[string]listing.Field{
+		"name":       {Kind: listing.String, Ops: listing.Equality, Sortable: true},
+		"start_date": {Kind: listing.Time, Ops: listing.Range, Sortable: true},
+		"end_date":   {Kind: listing.Time, Ops: listing.Range, Sortable: true},
+		"created_at": {Kind: listing.Time, Ops: listing.Range, Sortable: true},
+	},
 }
 
-func GetMenuByID(id string, c *gin.Context) (models.Menu, error) {
-	var menu models.Menu
-	menuID, err := primitive.ObjectIDFromHex(id)
+func (s *Service) GetMenus(ctx context.Context, actor Actor, req types.ListRequest) (listing.Page[models.Menu], error) {
+	query, err := listing.Parse(menuListing, req)
 	if err != nil {
-		return menu, errors.New("invalid menu ID")
+		return listing.Page[models.Menu]{}, err
 	}
+	return s.repos.Menus.List(ctx, query)
+}
 
-	err = menuCollection.FindOne(c.Request.Context(), bson.M{"_id": menuID}).Decode(&menu)
+func (s *Service) GetMenuByID(ctx context.Context, actor Actor, id string) (models.Menu, error) {
+	menu, err := s.repos.Menus.FindByID(ctx, id)
 	if err != nil {
-		return menu, errors.New("menu not found")
+		if err == repositories.ErrNotFound {
+			return models.Menu{}, apperrors.NotFound("menu not found")
+		}
+		return models.Menu{}, err
 	}
 
 	return menu, nil
 }
 
-func CreateMenu(menu models.Menu, c *gin.Context) (models.Menu, error) {
-
-	userEmail, _ := c.Get("first_name")
-	var isAdmin = helpers.IsAdmin(userEmail.(string))
-
-	if !isAdmin {
-		return models.Menu{}, errors.New("user is not admin")
+func (s *Service) CreateMenu(ctx context.Context, actor Actor, req types.Menu) (models.Menu, error) {
+	if err := validation.Struct(req); err != nil {
+		return models.Menu{}, err
 	}
 
-	var reqMenu models.Menu
+	var menu models.Menu
 
-	reqMenu.Name = menu.Name
-	reqMenu.Description = menu.Description
-	reqMenu.Start_Date, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
-	reqMenu.End_Date, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
-	reqMenu.ID = primitive.NewObjectID()
-	reqMenu.CreatedAt, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
-	reqMenu.UpdatedAt, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
-	reqMenu.Menu_id = reqMenu.ID.Hex()
+	menu.Name = req.Name
+	menu.Description = req.Description
+	menu.Start_Date = req.Start_date
+	menu.End_Date = req.End_date
+	menu.ID = primitive.NewObjectID()
+	menu.CreatedAt, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+	menu.UpdatedAt, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+	menu.Menu_id = menu.ID.Hex()
 
-	_, err := menuCollection.InsertOne(c.Request.Context(), reqMenu)
+	err := s.repos.Menus.Create(ctx, menu)
 	if err != nil {
 		return models.Menu{}, err
 	}
 
-	return reqMenu, nil
+	return menu, nil
 }
 
-func UpdateMenu(id string, menu models.Menu, c *gin.Context) (models.Menu, error) {
-
-	var reqMenu models.Menu
-	menuID, err := primitive.ObjectIDFromHex(id)
+func (s *Service) UpdateMenu(ctx context.Context, actor Actor, id string, req types.MenuUpdate) (models.Menu, error) {
+	if err := validation.Struct(req); err != nil {
+		return models.Menu{}, err
+	}
+	menu, err := s.GetMenuByID(ctx, actor, id)
 	if err != nil {
-		return reqMenu, errors.New("invalid menu ID")
+		return models.Menu{}, err
 	}
 
-	userEmail, _ := c.Get("first_name")
-	var isAdmin = helpers.IsAdmin(userEmail.(string))
-
-	if !isAdmin {
-		return reqMenu, errors.New("user is not admin")
+	if req.Name != nil {
+		menu.Name = *req.Name
 	}
-
-	reqMenu.Name = menu.Name
-	reqMenu.Description = menu.Description
-	reqMenu.Start_Date, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
-	reqMenu.End_Date, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
-	reqMenu.UpdatedAt, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
-
-	_, err = menuCollection.UpdateOne(c.Request.Context(), bson.M{"_id": menuID}, bson.M{"$set": reqMenu})
-	if err != nil {
-		return reqMenu, err
+	if req.Description != nil {
+		menu.Description = *req.Description
 	}
+	if req.Start_date != nil {
+		menu.Start_Date = *req.Start_date
+	}
+	if req.End_date != nil {
+		menu.End_Date = *req.End_date
+	}
+	// only one of the dates may have been sent, so the order is checked on the result
+	if !menu.End_Date.After(menu.Start_Date) {
+		return models.Menu{}, apperrors.Invalid(apperrors.FieldError{Field: "end_date", Message: "must be after start_date"})
+	}
+	menu.UpdatedAt, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
 
-	return reqMenu, nil
-}
-
-func DeleteMenu(id string, c *gin.Context) error {
-	menuID, err := primitive.ObjectIDFromHex(id)
+	err = s.repos.Menus.Update(ctx, menu)
 	if err != nil {
-		return errors.New("invalid menu ID")
+		return models.Menu{}, err
 	}
 
-	userEmail, _ := c.Get("first_name")
-	var isAdmin = helpers.IsAdmin(userEmail.(string))
-
-	if !isAdmin {
-		return errors.New("user is not admin")
-	}
+	return menu, nil
+}
 
-	_, err = menuCollection.DeleteOne(c.Request.Context(), bson.M{"_id": menuID})
+func (s *Service) DeleteMenu(ctx context.Context, actor Actor, id string) error {
+	err := s.repos.Menus.Delete(ctx, id)
 	if err != nil {
+		if err == repositories.ErrNotFound {
+			return apperrors.NotFound("menu not found")
+		}
 		return err
 	}
 
@@ -0,0 +1,285 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/ShahSau/culinary-bliss/apperrors"
+	"github.com/ShahSau/culinary-bliss/helpers"
+	"github.com/ShahSau/culinary-bliss/models"
+	"github.com/ShahSau/culinary-bliss/repositories"
+	"github.com/ShahSau/culinary-bliss/tokens"
+	"github.com/ShahSau/culinary-bliss/types"
+	"github.com/ShahSau/culinary-bliss/validation"
+)
+
+const recoveryCodeCount = 10
+
+var errInvalidMfaCode = apperrors.Unauthorized("invalid two-factor code")
+
+var errInvalidMfaToken = apperrors.Unauthorized("invalid or expired two-factor token")
+
+// MfaRequiredError is returned by LoginUser when the password was right but a second factor is still needed
+type MfaRequiredError struct {
+	Token              string
+	EnrollmentRequired bool
+}
+
+func (e *MfaRequiredError) Error() string {
+	return "two-factor authentication required"
+}
+
+// mfaChallenge returns a MfaRequiredError if the user has to pass a second factor before getting tokens
+func (s *Service) mfaChallenge(ctx context.Context, user models.User) error {
+	required, err := s.MfaRequiredForRole(ctx, user.Role)
+	if err != nil {
+		return err
+	}
+
+	if !user.Mfa_enabled && !required {
+		return nil
+	}
+
+	token, err := helpers.GenerateMfaToken(user.Email, user.User_id)
+	if err != nil {
+		return err
+	}
+
+	return &MfaRequiredError{Token: token, EnrollmentRequired: !user.Mfa_enabled}
+}
+
+// StartMfaLoginEnrollment lets a user whose role requires two-factor authentication enroll during login
+func (s *Service) StartMfaLoginEnrollment(ctx context.Context, actor Actor, req types.MfaToken) (string, string, error) {
+	if err := validation.Struct(req); err != nil {
+		return "", "", err
+	}
+	user, _, err := s.userFromMfaToken(ctx, req.MfaToken)
+	if err != nil {
+		return "", "", err
+	}
+
+	return s.beginMfaEnrollment(ctx, user)
+}
+
+// CompleteMfaLogin checks the second factor of a login and issues the access and refresh tokens.
+// When the login also finished an enrollment the new recovery codes are returned.
+func (s *Service) CompleteMfaLogin(ctx context.Context, actor Actor, req types.MfaLogin) (models.User, string, string, []string, error) {
+	if err := validation.Struct(req); err != nil {
+		return models.User{}, "", "", nil, err
+	}
+	user, claims, err := s.userFromMfaToken(ctx, req.MfaToken)
+	if err != nil {
+		return models.User{}, "", "", nil, err
+	}
+
+	if err := s.checkLoginAllowed(ctx, user.Email, actor.Ip_address); err != nil {
+		return models.User{}, "", "", nil, err
+	}
+
+	var recoveryCodes []string
+	switch {
+	case user.Mfa_enabled && req.RecoveryCode != "":
+		err = s.useRecoveryCode(ctx, user, req.RecoveryCode)
+	case user.Mfa_enabled:
+		err = s.verifyMfaCode(ctx, user, user.Mfa_secret, req.Code)
+	case user.Mfa_pending_secret == "":
+		return models.User{}, "", "", nil, apperrors.Validation("two-factor enrollment has not been started")
+	default:
+		err = s.verifyMfaCode(ctx, user, user.Mfa_pending_secret, req.Code)
+		if err == nil {
+			recoveryCodes, err = s.enableMfa(ctx, user)
+		}
+	}
+
+	if err == errInvalidMfaCode {
+		if err := s.recordLoginFailure(ctx, actor, user.Email); err != nil {
+			return models.User{}, "", "", nil, err
+		}
+	}
+	if err != nil {
+		return models.User{}, "", "", nil, err
+	}
+
+	if err := s.resetLoginFailures(ctx, user.Email); err != nil {
+		return models.User{}, "", "", nil, err
+	}
+
+	// the challenge token is single use
+	if err := s.RevokeToken(ctx, helpers.TokenRevocationKey(claims.ID), user.User_id, claims.ExpiresAt.Time); err != nil {
+		return models.User{}, "", "", nil, err
+	}
+
+	token, refreshToken, err := s.IssueTokens(ctx, actor, user)
+	if err != nil {
+		return models.User{}, "", "", nil, err
+	}
+
+	return user, token, refreshToken, recoveryCodes, nil
+}
+
+// EnrollMfa starts two-factor enrollment for a logged in user and returns the secret and its otpauth URI
+func (s *Service) EnrollMfa(ctx context.Context, actor Actor) (string, string, error) {
+	user, err := s.findUserByID(ctx, actor.User_id)
+	if err != nil {
+		return "", "", err
+	}
+
+	return s.beginMfaEnrollment(ctx, user)
+}
+
+// ConfirmMfa turns two-factor authentication on once the user proves their app generates valid codes
+func (s *Service) ConfirmMfa(ctx context.Context, actor Actor, req types.MfaCode) ([]string, error) {
+	if err := validation.Struct(req); err != nil {
+		return nil, err
+	}
+	user, err := s.findUserByID(ctx, actor.User_id)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.Mfa_enabled {
+		return nil, apperrors.Validation("two-factor authentication is already enabled")
+	}
+	if user.Mfa_pending_secret == "" {
+		return nil, apperrors.Validation("two-factor enrollment has not been started")
+	}
+
+	if err := s.verifyMfaCode(ctx, user, user.Mfa_pending_secret, req.Code); err != nil {
+		return nil, err
+	}
+
+	return s.enableMfa(ctx, user)
+}
+
+// DisableMfa turns two-factor authentication off, unless the user's role requires it
+func (s *Service) DisableMfa(ctx context.Context, actor Actor, req types.MfaCode) error {
+	if err := validation.Struct(req); err != nil {
+		return err
+	}
+	user, err := s.findUserByID(ctx, actor.User_id)
+	if err != nil {
+		return err
+	}
+
+	if !user.Mfa_enabled {
+		return apperrors.Validation("two-factor authentication is not enabled")
+	}
+
+	required, err := s.MfaRequiredForRole(ctx, user.Role)
+	if err != nil {
+		return err
+	}
+	if required {
+		return apperrors.Forbidden("two-factor authentication is required for your role")
+	}
+
+	if err := s.verifyMfaCode(ctx, user, user.Mfa_secret, req.Code); err != nil {
+		return err
+	}
+
+	updatedAt, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+	return s.repos.Users.DisableMfa(ctx, user.User_id, updatedAt)
+}
+
+func (s *Service) userFromMfaToken(ctx context.Context, mfaToken string) (models.User, *tokens.Claims, error) {
+	claims, err := tokens.Parse(mfaToken, tokens.MfaToken)
+	if err != nil {
+		return models.User{}, nil, errInvalidMfaToken
+	}
+
+	revoked, err := s.IsTokenRevoked(ctx, claims)
+	if err != nil {
+		return models.User{}, nil, err
+	}
+	if revoked {
+		return models.User{}, nil, errInvalidMfaToken
+	}
+
+	user, err := s.findUserByID(ctx, claims.User_id)
+	if err != nil {
+		return models.User{}, nil, err
+	}
+
+	return user, claims, nil
+}
+
+func (s *Service) beginMfaEnrollment(ctx context.Context, user models.User) (string, string, error) {
+	if user.Mfa_enabled {
+		return "", "", apperrors.Validation("two-factor authentication is already enabled")
+	}
+
+	secret, err := helpers.GenerateTOTPSecret()
+	if err != nil {
+		return "", "", err
+	}
+
+	updatedAt, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+	err = s.repos.Users.SetMfaPendingSecret(ctx, user.User_id, secret, updatedAt)
+	if err != nil {
+		return "", "", err
+	}
+
+	return secret, helpers.TOTPURI(user.Email, secret), nil
+}
+
+// verifyMfaCode checks a TOTP code and records its time step so the same code cannot be replayed
+func (s *Service) verifyMfaCode(ctx context.Context, user models.User, secret string, code string) error {
+	step, ok := helpers.ValidateTOTP(secret, code, user.Mfa_last_step, time.Now())
+	if !ok {
+		return errInvalidMfaCode
+	}
+
+	advanced, err := s.repos.Users.AdvanceMfaStep(ctx, user.User_id, step)
+	if err != nil {
+		return err
+	}
+	if !advanced {
+		return errInvalidMfaCode
+	}
+
+	return nil
+}
+
+func (s *Service) useRecoveryCode(ctx context.Context, user models.User, code string) error {
+	used, err := s.repos.Users.UseRecoveryCode(ctx, user.User_id, helpers.HashToken(helpers.NormalizeRecoveryCode(code)))
+	if err != nil {
+		return err
+	}
+	if !used {
+		return errInvalidMfaCode
+	}
+
+	return nil
+}
+
+func (s *Service) enableMfa(ctx context.Context, user models.User) ([]string, error) {
+	codes, err := helpers.GenerateRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		return nil, err
+	}
+
+	var hashes []string
+	for _, code := range codes {
+		hashes = append(hashes, helpers.HashToken(code))
+	}
+
+	updatedAt, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+	err = s.repos.Users.EnableMfa(ctx, user.User_id, user.Mfa_pending_secret, hashes, updatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+func (s *Service) findUserByID(ctx context.Context, userID string) (models.User, error) {
+	user, err := s.repos.Users.FindByID(ctx, userID)
+	if err != nil {
+		if err == repositories.ErrNotFound {
+			return models.User{}, apperrors.NotFound("user not found")
+		}
+		return models.User{}, err
+	}
+
+	return user, nil
+}
@@ -0,0 +1,63 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/ShahSau/culinary-bliss/apperrors"
+	"github.com/ShahSau/culinary-bliss/helpers"
+	"github.com/ShahSau/culinary-bliss/models"
+	"github.com/ShahSau/culinary-bliss/repositories"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var errInvalidOneTimeToken = apperrors.Validation("invalid or expired token")
+
+// issueOneTimeToken stores a new token for the user and invalidates any the user was sent before
+func issueOneTimeToken(ctx context.Context, repository repositories.OneTimeTokenRepository, userID string, ttl time.Duration) (string, error) {
+	return issueOneTimeTokenFor(ctx, repository, models.OneTimeToken{User_id: userID}, ttl)
+}
+
+// issueOneTimeTokenFor is issueOneTimeToken for a token that confirms more than who the user is, such as a new email address
+func issueOneTimeTokenFor(ctx context.Context, repository repositories.OneTimeTokenRepository, pending models.OneTimeToken, ttl time.Duration) (string, error) {
+	token, err := helpers.GenerateRandomToken(32)
+	if err != nil {
+		return "", err
+	}
+
+	now, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+
+	err = repository.InvalidateUnused(ctx, pending.User_id, now)
+	if err != nil {
+		return "", err
+	}
+
+	err = repository.Create(ctx, models.OneTimeToken{
+		ID:         primitive.NewObjectID(),
+		User_id:    pending.User_id,
+		Token_hash: helpers.HashToken(token),
+		Email:      pending.Email,
+		ExpiresAt:  now.Add(ttl),
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// consumeOneTimeToken marks an unused, unexpired token as used and returns it
+func consumeOneTimeToken(ctx context.Context, repository repositories.OneTimeTokenRepository, token string) (models.OneTimeToken, error) {
+	now, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+	oneTimeToken, err := repository.Consume(ctx, helpers.HashToken(token), now)
+	if err != nil {
+		if err == repositories.ErrNotFound {
+			return models.OneTimeToken{}, errInvalidOneTimeToken
+		}
+		return models.OneTimeToken{}, err
+	}
+
+	return oneTimeToken, nil
+}
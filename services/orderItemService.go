@@ -1,79 +1,109 @@
 package services
 
 import (
+	"context"
 	"errors"
-	"net/http"
+	"fmt"
 	"time"
 
-	"github.com/ShahSau/culinary-bliss/database"
+	"github.com/ShahSau/culinary-bliss/apperrors"
 	"github.com/ShahSau/culinary-bliss/helpers"
+	"github.com/ShahSau/culinary-bliss/listing"
 	"github.com/ShahSau/culinary-bliss/models"
-	"github.com/gin-gonic/gin"
-	"go.mongodb.org/mongo-driver/bson"
+	"github.com/ShahSau/culinary-bliss/money"
+	"github.com/ShahSau/culinary-bliss/pricing"
+	"github.com/ShahSau/culinary-bliss/repositories"
+	"github.com/ShahSau/culinary-bliss/types"
+	"github.com/ShahSau/culinary-bliss/validation"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo"
 )
 
-type OrderItemPack struct {
-	Table_id    string
-	Order_items []models.OrderItem
+// orderItemListing is what order items can be filtered and sorted by
+var orderItemListing = listing.Spec{
+	ID:          "order_item_id",
+	DefaultSort: "-created_at",
+	Fields: map[string]listing.Field{
+		"order_id":     {Kind: listing.String, Ops: listing.Equality},
+		"food_id":      {Kind: listing.String, Ops: listing.Equality},
+		"quantity":     {Kind: listing.Number, Ops: listing.Range},
+		"portion":      {Kind: listing.String, Ops: listing.Equality},
+		"total_amount": {Kind: listing.Money, Ops: listing.Range, Sortable: true},
+		"created_at":   {Kind: listing.Time, Ops: listing.Range, Sortable: true},
+	},
 }
 
-var orderItemCollection *mongo.Collection = database.GetCollection(database.DB, "order_items")
-
-func GetOrderItems(c *gin.Context) ([]models.OrderItem, error) {
-	orders, err := orderItemCollection.Find(c.Request.Context(), bson.M{}, nil)
-
+// GetOrderItems lists order items. Items are not stored with a restaurant, so API keys list the items of one order of their restaurant.
+func (s *Service) GetOrderItems(ctx context.Context, actor Actor, req types.ListRequest) (listing.Page[models.OrderItem], error) {
+	query, err := listing.Parse(orderItemListing, req)
 	if err != nil {
-		return nil, err
-	}
-
-	userEmail, _ := c.Get("first_name")
-	var isAdmin = helpers.IsAdmin(userEmail.(string))
-
-	if !isAdmin {
-		return nil, errors.New("you are not authorized to view this resource")
+		return listing.Page[models.OrderItem]{}, err
 	}
-
-	defer orders.Close(c.Request.Context())
-
-	var allOrdersItems []models.OrderItem
-
-	for orders.Next(c.Request.Context()) {
-		var orderItem models.OrderItem
-		if err = orders.Decode(&orderItem); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": true, "message": err.Error()})
+	if actor.Restaurant_id != "" {
+		orderID := req.Query["order_id"]
+		if len(orderID) != 1 {
+			return listing.Page[models.OrderItem]{}, apperrors.Invalid(apperrors.FieldError{Field: "order_id", Message: "is required for an API key"})
+		}
+		if _, err := s.GetOrderById(ctx, actor, orderID[0]); err != nil {
+			return listing.Page[models.OrderItem]{}, err
 		}
-		allOrdersItems = append(allOrdersItems, orderItem)
 	}
-
-	return allOrdersItems, nil
+	return s.repos.OrderItems.List(ctx, query)
 }
 
-func GetOrderItemByID(id string, c *gin.Context) (models.OrderItem, error) {
-	var orderItem models.OrderItem
-	objectID, err := primitive.ObjectIDFromHex(id)
+// GetOrderItemByID finds an order item, the items of orders of other restaurants are not found for an API key
+func (s *Service) GetOrderItemByID(ctx context.Context, actor Actor, id string) (models.OrderItem, error) {
+	orderItem, err := s.repos.OrderItems.FindByID(ctx, id)
 	if err != nil {
-		return orderItem, errors.New("invalid order item ID")
+		if err == repositories.ErrNotFound {
+			return models.OrderItem{}, apperrors.NotFound("order item not found")
+		}
+		return models.OrderItem{}, err
 	}
-
-	err = orderItemCollection.FindOne(c.Request.Context(), bson.M{"_id": objectID}).Decode(&orderItem)
-	if err != nil {
-		return orderItem, errors.New("order item not found")
+	if actor.Restaurant_id != "" {
+		if _, err := s.GetOrderById(ctx, actor, orderItem.Order_id); err != nil {
+			if errors.Is(err, apperrors.ErrNotFound) {
+				return models.OrderItem{}, apperrors.NotFound("order item not found")
+			}
+			return models.OrderItem{}, err
+		}
 	}
 
 	return orderItem, nil
 }
 
-func CreateOrderItem(orderItem models.OrderItem, c *gin.Context) (models.OrderItem, error) {
+// CreateOrderItem prices an item from its food and adds it to the total of its order
+func (s *Service) CreateOrderItem(ctx context.Context, actor Actor, req types.OrderItem) (models.OrderItem, error) {
+	if err := validation.Struct(req); err != nil {
+		return models.OrderItem{}, err
+	}
+
+	var orderItem models.OrderItem
+	err := s.repos.Transactions.InTransaction(ctx, func(ctx context.Context) error {
+		if _, err := s.editableOrder(ctx, actor, req.Order_id); err != nil {
+			return err
+		}
 
-	orderItem.CreatedAt, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
-	orderItem.UpdatedAt, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
-	orderItem.ID = primitive.NewObjectID()
-	orderItem.Order_item_id = orderItem.ID.Hex()
+		var problems []apperrors.FieldError
+		var err error
+		orderItem, problems, err = s.priceLine(ctx, "", types.OrderLine{Food_id: req.Food_id, Quantity: req.Quantity, Portion: req.Portion, Modifiers: req.Modifiers, Notes: req.Notes, Total_amount: req.Total_amount})
+		if err != nil {
+			return err
+		}
+		if len(problems) > 0 {
+			return apperrors.Invalid(problems...)
+		}
 
-	_, err := orderItemCollection.InsertOne(c.Request.Context(), orderItem)
+		orderItem.Order_id = req.Order_id
+		orderItem.CreatedAt, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		orderItem.UpdatedAt, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		orderItem.ID = primitive.NewObjectID()
+		orderItem.Order_item_id = orderItem.ID.Hex()
 
+		if err := s.repos.OrderItems.Create(ctx, orderItem); err != nil {
+			return err
+		}
+		return s.adjustOrderTotal(ctx, actor, orderItem.Order_id, orderItem.Total_amount, money.Money{})
+	})
 	if err != nil {
 		return models.OrderItem{}, err
 	}
@@ -81,27 +111,96 @@ func CreateOrderItem(orderItem models.OrderItem, c *gin.Context) (models.OrderIt
 	return orderItem, nil
 }
 
-func UpdateOrderItem(id string, updatedOrderItem models.OrderItem, c *gin.Context) (models.OrderItem, error) {
-	orderItemID, err := primitive.ObjectIDFromHex(id)
-
-	if err != nil {
-		return updatedOrderItem, errors.New("invalid order item ID")
+// UpdateOrderItem prices the item again from the current price of its food and moves the difference to the order totals
+func (s *Service) UpdateOrderItem(ctx context.Context, actor Actor, id string, req types.OrderItemUpdate) (models.OrderItem, error) {
+	if err := validation.Struct(req); err != nil {
+		return models.OrderItem{}, err
 	}
 
-	err = orderItemCollection.FindOne(c.Request.Context(), bson.M{"order_item_id": updatedOrderItem}).Decode(&updatedOrderItem)
+	var orderItem models.OrderItem
+	err := s.repos.Transactions.InTransaction(ctx, func(ctx context.Context) error {
+		previous, err := s.GetOrderItemByID(ctx, actor, id)
+		if err != nil {
+			return err
+		}
+		if _, err := s.editableOrder(ctx, actor, previous.Order_id); err != nil {
+			return err
+		}
+
+		line := types.OrderLine{Food_id: previous.Food_id, Quantity: previous.Quantity, Portion: previous.Portion, Notes: previous.Notes, Total_amount: req.Total_amount}
+		for _, modifier := range previous.Modifiers {
+			line.Modifiers = append(line.Modifiers, modifier.Name)
+		}
+		if req.Quantity != nil {
+			line.Quantity = *req.Quantity
+		}
+		if req.Portion != nil {
+			line.Portion = *req.Portion
+		}
+		if req.Modifiers != nil {
+			line.Modifiers = *req.Modifiers
+		}
+		if req.Notes != nil {
+			line.Notes = *req.Notes
+		}
+
+		var problems []apperrors.FieldError
+		orderItem, problems, err = s.priceLine(ctx, "", line)
+		if err != nil {
+			return err
+		}
+		if len(problems) > 0 {
+			return apperrors.Invalid(problems...)
+		}
 
+		orderItem.ID = previous.ID
+		orderItem.Order_item_id = previous.Order_item_id
+		orderItem.Order_id = previous.Order_id
+		if req.Order_id != nil {
+			if _, err := s.editableOrder(ctx, actor, *req.Order_id); err != nil {
+				return err
+			}
+			orderItem.Order_id = *req.Order_id
+		}
+		orderItem.CreatedAt = previous.CreatedAt
+		orderItem.UpdatedAt, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+
+		if err := s.repos.OrderItems.Update(ctx, orderItem); err != nil {
+			return err
+		}
+		if orderItem.Order_id == previous.Order_id {
+			return s.adjustOrderTotal(ctx, actor, orderItem.Order_id, orderItem.Total_amount, previous.Total_amount)
+		}
+		if err := s.adjustOrderTotal(ctx, actor, previous.Order_id, money.Money{}, previous.Total_amount); err != nil {
+			return err
+		}
+		return s.adjustOrderTotal(ctx, actor, orderItem.Order_id, orderItem.Total_amount, money.Money{})
+	})
 	if err != nil {
-		return models.OrderItem{}, errors.New("order item not found")
+		return models.OrderItem{}, err
 	}
+
+	return orderItem, nil
+}
+
+// DeleteOrderItem removes an item and takes it off the total of its order
+func (s *Service) DeleteOrderItem(ctx context.Context, actor Actor, id string) (models.OrderItem, error) {
 	var orderItem models.OrderItem
-	orderItem.UpdatedAt, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
-	orderItem.Quantity = updatedOrderItem.Quantity
-	orderItem.Order_item_id = updatedOrderItem.Order_item_id
-	orderItem.ID = updatedOrderItem.ID
-	orderItem.CreatedAt = updatedOrderItem.CreatedAt
-	orderItem.Order_id = updatedOrderItem.Order_id
-	orderItem.Total_amount = updatedOrderItem.Total_amount
-	_, err = orderItemCollection.UpdateOne(c.Request.Context(), bson.M{"_id": orderItemID}, bson.M{"$set": orderItem})
+	err := s.repos.Transactions.InTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		orderItem, err = s.GetOrderItemByID(ctx, actor, id)
+		if err != nil {
+			return err
+		}
+		if _, err := s.editableOrder(ctx, actor, orderItem.Order_id); err != nil {
+			return err
+		}
+
+		if err := s.repos.OrderItems.Delete(ctx, id); err != nil {
+			return err
+		}
+		return s.adjustOrderTotal(ctx, actor, orderItem.Order_id, money.Money{}, orderItem.Total_amount)
+	})
 	if err != nil {
 		return models.OrderItem{}, err
 	}
@@ -109,23 +208,63 @@ func UpdateOrderItem(id string, updatedOrderItem models.OrderItem, c *gin.Contex
 	return orderItem, nil
 }
 
-func DeleteOrderItem(id string, c *gin.Context) (models.OrderItem, error) {
-	orderItemID, err := primitive.ObjectIDFromHex(id)
+// editableOrder returns the order if its items can still be changed and a conflict if the order has moved on too far
+func (s *Service) editableOrder(ctx context.Context, actor Actor, orderID string) (models.Order, error) {
+	order, err := s.GetOrderById(ctx, actor, orderID)
 	if err != nil {
-		return models.OrderItem{}, errors.New("invalid order item ID")
+		return models.Order{}, err
 	}
+	if !helpers.IsOrderEditable(order.Order_status) {
+		return models.Order{}, apperrors.Conflict(fmt.Sprintf("the items of an order that is %s cannot be changed", order.Order_status))
+	}
+	return order, nil
+}
 
-	var orderItem models.OrderItem
-	err = orderItemCollection.FindOne(c.Request.Context(), bson.M{"_id": orderItemID}).Decode(&orderItem)
-
+// priceLine works out the order item for a line from the current price of its food.
+// Problems with the line are reported on the fields under prefix, e.g. order_items[2].
+func (s *Service) priceLine(ctx context.Context, prefix string, line types.OrderLine) (models.OrderItem, []apperrors.FieldError, error) {
+	food, err := s.repos.Foods.FindByID(ctx, line.Food_id)
+	if err == repositories.ErrNotFound {
+		return models.OrderItem{}, []apperrors.FieldError{{Field: prefix + "food_id", Message: "food not found"}}, nil
+	}
 	if err != nil {
-		return models.OrderItem{}, errors.New("order item not found")
+		return models.OrderItem{}, nil, err
 	}
 
-	_, err = orderItemCollection.DeleteOne(c.Request.Context(), bson.M{"_id": orderItemID})
+	portion := line.Portion
+	if portion == "" {
+		portion = pricing.DefaultPortion
+	}
+	priced, err := pricing.Price(food, line.Quantity, portion, line.Modifiers)
+	if errors.Is(err, pricing.ErrUnknownPortion) {
+		return models.OrderItem{}, []apperrors.FieldError{{Field: prefix + "portion", Message: err.Error()}}, nil
+	}
+	if errors.Is(err, pricing.ErrUnknownModifier) {
+		return models.OrderItem{}, []apperrors.FieldError{{Field: prefix + "modifiers", Message: err.Error()}}, nil
+	}
+	if errors.Is(err, money.ErrCurrencyMismatch) {
+		return models.OrderItem{}, []apperrors.FieldError{{Field: prefix + "food_id", Message: "is priced in another currency than its modifiers"}}, nil
+	}
 	if err != nil {
-		return models.OrderItem{}, err
+		return models.OrderItem{}, nil, err
 	}
 
-	return orderItem, nil
+	item := models.OrderItem{
+		Food_id:      food.Food_id,
+		Quantity:     line.Quantity,
+		Portion:      portion,
+		Modifiers:    priced.Modifiers,
+		Notes:        line.Notes,
+		Unit_price:   priced.Unit_price,
+		Total_amount: priced.Total_amount,
+	}
+	if line.Total_amount != nil && !line.Total_amount.Equal(item.Total_amount) {
+		return item, []apperrors.FieldError{totalMismatch(prefix+"total_amount", item.Total_amount)}, nil
+	}
+	return item, nil, nil
+}
+
+// totalMismatch reports a total sent by a client that is not the one worked out on the server
+func totalMismatch(field string, computed money.Money) apperrors.FieldError {
+	return apperrors.FieldError{Field: field, Message: fmt.Sprintf("does not match the computed total of %s", computed)}
 }
@@ -2,178 +2,312 @@ package services
 
 import (
 	"context"
-	"errors"
-	"log"
-	"strconv"
+	"fmt"
 	"time"
 
-	"github.com/ShahSau/culinary-bliss/database"
+	"github.com/ShahSau/culinary-bliss/apperrors"
 	"github.com/ShahSau/culinary-bliss/helpers"
+	"github.com/ShahSau/culinary-bliss/listing"
 	"github.com/ShahSau/culinary-bliss/models"
-	"github.com/gin-gonic/gin"
-	"go.mongodb.org/mongo-driver/bson"
+	"github.com/ShahSau/culinary-bliss/money"
+	"github.com/ShahSau/culinary-bliss/pricing"
+	"github.com/ShahSau/culinary-bliss/repositories"
+	"github.com/ShahSau/culinary-bliss/types"
+	"github.com/ShahSau/culinary-bliss/validation"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo"
 )
 
-var orderCollection *mongo.Collection = database.GetCollection(database.DB, "orders")
-var tableCollection *mongo.Collection = database.GetCollection(database.DB, "tables")
-
-func GetOrders(c *gin.Context) (models.ResponseOrder, error) {
-	userEmail, _ := c.Get("first_name")
-	var isAdmin = helpers.IsAdmin(userEmail.(string))
+// orderListing is what orders can be filtered and sorted by
+var orderListing = listing.Spec{
+	ID:          "order_id",
+	DefaultSort: "-order_date",
+	Fields: map[string]listing.Field{
+		"order_status":  {Kind: listing.String, Ops: listing.Equality},
+		"table_id":      {Kind: listing.String, Ops: listing.Equality},
+		"restaurant_id": {Kind: listing.String, Ops: listing.Equality},
+		"total_amount":  {Kind: listing.Money, Ops: listing.Range, Sortable: true},
+		"order_date":    {Kind: listing.Time, Ops: listing.Range, Sortable: true},
+		"created_at":    {Kind: listing.Time, Ops: listing.Range, Sortable: true},
+	},
+}
 
-	if !isAdmin {
-		return models.ResponseOrder{}, errors.New("you are not authorized to view this resource")
-	}
-	recordPerPage, err := strconv.Atoi(c.Query("recordPerPage"))
-	if err != nil || recordPerPage < 1 {
-		recordPerPage = 10
+func (s *Service) GetOrders(ctx context.Context, actor Actor, req types.ListRequest) (listing.Page[models.Order], error) {
+	query, err := listing.Parse(orderListing, req)
+	if err != nil {
+		return listing.Page[models.Order]{}, err
 	}
+	return s.repos.Orders.List(ctx, actor.restaurantFilter(query))
+}
 
-	page, errp := strconv.Atoi(c.Query("page"))
-	if errp != nil || page < 1 {
-		page = 1
+// GetOrderById finds an order, the orders of other restaurants are not found for an API key
+func (s *Service) GetOrderById(ctx context.Context, actor Actor, orderId string) (models.Order, error) {
+	order, err := s.repos.Orders.FindByID(ctx, orderId)
+	if err != nil {
+		if err == repositories.ErrNotFound {
+			return models.Order{}, apperrors.NotFound("order not found")
+		}
+		return models.Order{}, err
 	}
-
-	startIndex := (page - 1) * recordPerPage
-	startIndex, err = strconv.Atoi(c.Query("startIndex"))
-
-	matchStage := bson.D{{Key: "$match", Value: bson.D{{}}}}
-	projectStage := bson.D{
-		{
-			Key: "$project", Value: bson.D{
-				{Key: "id", Value: 1},
-				{Key: "table_id", Value: 1},
-				{Key: "order_status", Value: 1},
-				{Key: "order_date", Value: 1},
-				{Key: "total_amount", Value: 1},
-			},
-		},
+	if !actor.inRestaurant(order.Restaurant_id) {
+		return models.Order{}, apperrors.NotFound("order not found")
 	}
-	record := bson.D{{Key: "$skip", Value: recordPerPage * (page - 1)}}
-	limit := bson.D{{Key: "$limit", Value: recordPerPage}}
 
-	result, errAgg := orderCollection.Aggregate(c.Request.Context(), mongo.Pipeline{matchStage, projectStage, record, limit})
-
-	if errAgg != nil {
-		return models.ResponseOrder{}, errAgg
-	}
+	return order, nil
+}
 
-	var allOrders []bson.M
-	if err = result.All(c.Request.Context(), &allOrders); err != nil {
-		log.Fatal(err)
+// GetOrderDetails returns the order with its table, its items joined to their food and what is due for them
+func (s *Service) GetOrderDetails(ctx context.Context, actor Actor, orderId string) (models.OrderDetails, error) {
+	details, err := s.repos.Orders.Details(ctx, orderId)
+	if err != nil {
+		if err == repositories.ErrNotFound {
+			return models.OrderDetails{}, apperrors.NotFound("order not found")
+		}
+		return models.OrderDetails{}, err
 	}
-
-	var orders []models.Order
-	for _, order := range allOrders {
-		var mappedOrder models.Order
-		bsonBytes, _ := bson.Marshal(order)
-		bson.Unmarshal(bsonBytes, &mappedOrder)
-		orders = append(orders, mappedOrder)
+	if !actor.inRestaurant(details.Order.Restaurant_id) {
+		return models.OrderDetails{}, apperrors.NotFound("order not found")
 	}
 
-	response := models.ResponseOrder{
-		AllOrders:     orders,
-		Page:          page,
-		RecordPerPage: recordPerPage,
-		StartIndex:    startIndex,
-	}
-	return response, nil
+	return details, nil
 }
 
-func GetOrderById(c *gin.Context, orderId string) (models.Order, error) {
+func (s *Service) CreateOrder(ctx context.Context, actor Actor, req types.Order) (models.Order, error) {
+	if err := validation.Struct(req); err != nil {
+		return models.Order{}, err
+	}
+	// the order has no items yet, so nothing but 0 is its total
+	if req.Total_amount != nil && !req.Total_amount.IsZero() {
+		return models.Order{}, apperrors.Invalid(totalMismatch("total_amount", money.New(0, "")))
+	}
 	var order models.Order
+	// in a transaction so the order is not stored without its table being occupied
+	err := s.repos.Transactions.InTransaction(ctx, func(ctx context.Context) error {
+		table, err := s.GetTable(ctx, actor, req.Table_id)
+		if err != nil {
+			return err
+		}
+
+		now, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		order = models.Order{
+			ID:            primitive.NewObjectID(),
+			Table_id:      table.Table_id,
+			Restaurant_id: table.Restaurant_id,
+			Total_amount:  money.New(0, ""),
+			Order_status:  helpers.OrderStatusPlaced,
+			Order_date:    now,
+			CreatedAt:     now,
+			UpdatedAt:     now,
+		}
+		order.Order_id = order.ID.Hex()
+		order.Status_history = []models.OrderStatusChange{statusChange(actor, order.Order_status, now)}
 
-	err := orderCollection.FindOne(c.Request.Context(), bson.M{"order_id": orderId}).Decode(&order)
+		if err := s.repos.Orders.Create(ctx, order); err != nil {
+			return err
+		}
+		return s.occupyTable(ctx, table, now)
+	})
 	if err != nil {
 		return models.Order{}, err
 	}
-
 	return order, nil
 }
 
-func CreateOrder(c *gin.Context, orderReq models.Order) (models.Order, error) {
-	var table models.Table
-
-	if err := c.ShouldBindJSON(&orderReq); err != nil {
+// UpdateOrder moves an order to another table. Its total follows its items and its status only changes through TransitionOrder.
+// An open order occupies the table it moves to and frees the one it leaves unless another order is still open on it.
+func (s *Service) UpdateOrder(ctx context.Context, actor Actor, orderId string, req types.OrderUpdate) (models.Order, error) {
+	if err := validation.Struct(req); err != nil {
 		return models.Order{}, err
 	}
 
-	if orderReq.Table_id == "" {
-		err := tableCollection.FindOne(c.Request.Context(), bson.M{"table_id": orderReq.Table_id}).Decode(&table)
+	var order models.Order
+	// in a transaction so a transition made meanwhile is not overwritten with the status read here
+	err := s.repos.Transactions.InTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		order, err = s.GetOrderById(ctx, actor, orderId)
 		if err != nil {
-			return models.Order{}, err
+			return err
 		}
-	}
-	orderReq.CreatedAt, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
-	orderReq.UpdatedAt, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
-	orderReq.Order_date, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
-	orderReq.ID = primitive.NewObjectID()
-	orderReq.Order_id = orderReq.ID.Hex()
 
-	_, err := orderCollection.InsertOne(c.Request.Context(), orderReq)
+		now, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		previousTableID := order.Table_id
+		var table models.Table
+		if req.Table_id != nil {
+			table, err = s.GetTable(ctx, actor, *req.Table_id)
+			if err != nil {
+				return err
+			}
+			order.Table_id = table.Table_id
+			order.Restaurant_id = table.Restaurant_id
+		}
+		if req.Total_amount != nil && !req.Total_amount.Equal(order.Total_amount) {
+			return apperrors.Invalid(totalMismatch("total_amount", order.Total_amount))
+		}
+		order.UpdatedAt = now
+
+		if err := s.repos.Orders.Update(ctx, order); err != nil {
+			return err
+		}
+		if order.Table_id == previousTableID || !helpers.IsOrderOpen(order.Order_status) {
+			return nil
+		}
+		if err := s.occupyTable(ctx, table, now); err != nil {
+			return err
+		}
+		return s.releaseTable(ctx, actor, previousTableID, now)
+	})
 	if err != nil {
 		return models.Order{}, err
 	}
-	return orderReq, nil
+
+	return order, nil
 }
 
-func UpdateOrder(c *gin.Context, reqOrder models.Order, orderId string) (models.Order, error) {
-	if err := c.ShouldBindJSON(&reqOrder); err != nil {
-		return models.Order{}, err
+// adjustOrderTotal adds to the total of an order what its items added and takes off what they no longer cost
+func (s *Service) adjustOrderTotal(ctx context.Context, actor Actor, orderId string, added money.Money, removed money.Money) error {
+	order, err := s.GetOrderById(ctx, actor, orderId)
+	if err != nil {
+		return err
 	}
 
-	userEmail, _ := c.Get("first_name")
-	var isAdmin = helpers.IsAdmin(userEmail.(string))
-
-	if !isAdmin {
-		return models.Order{}, errors.New("user is not admin")
+	if order.Total_amount, err = order.Total_amount.Add(added); err != nil {
+		return err
+	}
+	if order.Total_amount, err = order.Total_amount.Sub(removed); err != nil {
+		return err
 	}
+	order.UpdatedAt, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+	return s.repos.Orders.Update(ctx, order)
+}
 
-	reqOrder.UpdatedAt, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
-	_, err := orderCollection.UpdateOne(c.Request.Context(), bson.M{"order_id": orderId}, bson.D{{Key: "$set", Value: reqOrder}})
+// DeleteOrder removes an order together with its items, deleting an open order frees its table like closing it does
+func (s *Service) DeleteOrder(ctx context.Context, actor Actor, orderId string) (models.Order, error) {
+	var order models.Order
+	err := s.repos.Transactions.InTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		order, err = s.GetOrderById(ctx, actor, orderId)
+		if err != nil {
+			return err
+		}
 
+		if err := s.repos.OrderItems.DeleteByOrder(ctx, order.Order_id); err != nil {
+			return err
+		}
+		if err := s.repos.Orders.Delete(ctx, order.Order_id); err != nil {
+			return err
+		}
+		if !helpers.IsOrderOpen(order.Order_status) {
+			return nil
+		}
+		now, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		return s.releaseTable(ctx, actor, order.Table_id, now)
+	})
 	if err != nil {
 		return models.Order{}, err
 	}
 
-	return reqOrder, nil
+	return order, nil
 }
 
-func DeleteOrder(c *gin.Context, orderId string) (models.Order, error) {
-	var order models.Order
-
-	userEmail, _ := c.Get("first_name")
-	var isAdmin = helpers.IsAdmin(userEmail.(string))
+// tableOccupied is the status of a table with an open order, tableFree the status it goes back to once its orders are closed
+const (
+	tableOccupied = "OCCUPIED"
+	tableFree     = "FREE"
+)
 
-	if !isAdmin {
-		return models.Order{}, errors.New("user is not admin")
+// PlaceOrder stores an order and its items and marks the table occupied in one transaction.
+// Every food has to be on a menu that is being served, and every item is priced from the current price of its food;
+// a total sent along is only checked.
+func (s *Service) PlaceOrder(ctx context.Context, actor Actor, pack types.OrderItemPack) (types.PlacedOrder, error) {
+	if err := validation.Struct(pack); err != nil {
+		return types.PlacedOrder{}, err
 	}
 
-	err := orderCollection.FindOne(c.Request.Context(), bson.M{"order_id": orderId}).Decode(&order)
-	if err != nil {
-		return models.Order{}, err
-	}
+	var placed types.PlacedOrder
+	err := s.repos.Transactions.InTransaction(ctx, func(ctx context.Context) error {
+		// the function runs again when the transaction is retried, so it starts from the pack every time
+		table, err := s.GetTable(ctx, actor, pack.Table_id)
+		if err != nil {
+			return err
+		}
+
+		now, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		order := models.Order{
+			ID:            primitive.NewObjectID(),
+			Table_id:      table.Table_id,
+			Restaurant_id: table.Restaurant_id,
+			Order_status:  helpers.OrderStatusPlaced,
+			Order_date:    now,
+			CreatedAt:     now,
+			UpdatedAt:     now,
+		}
+		order.Order_id = order.ID.Hex()
+		order.Status_history = []models.OrderStatusChange{statusChange(actor, order.Order_status, now)}
+
+		var problems []apperrors.FieldError
+		items := make([]models.OrderItem, len(pack.Order_items))
+		for i, line := range pack.Order_items {
+			prefix := fmt.Sprintf("order_items[%d].", i)
+			item, lineProblems, err := s.priceLine(ctx, prefix, line)
+			if err != nil {
+				return err
+			}
+			if len(lineProblems) == 0 {
+				if lineProblems, err = s.offMenu(ctx, prefix, line.Food_id, time.Now()); err != nil {
+					return err
+				}
+			}
+			problems = append(problems, lineProblems...)
+
+			item.ID = primitive.NewObjectID()
+			item.Order_item_id = item.ID.Hex()
+			item.Order_id = order.Order_id
+			item.CreatedAt = now
+			item.UpdatedAt = now
+			items[i] = item
+		}
+		if order.Total_amount, err = pricing.Total(items); err != nil {
+			return err
+		}
+		if len(problems) == 0 && pack.Total_amount != nil && !pack.Total_amount.Equal(order.Total_amount) {
+			problems = append(problems, totalMismatch("total_amount", order.Total_amount))
+		}
+		if len(problems) > 0 {
+			return apperrors.Invalid(problems...)
+		}
 
-	_, err = orderCollection.DeleteOne(c.Request.Context(), bson.M{"order_id": orderId})
+		if err := s.repos.Orders.Create(ctx, order); err != nil {
+			return err
+		}
+		for _, item := range items {
+			if err := s.repos.OrderItems.Create(ctx, item); err != nil {
+				return err
+			}
+		}
+		if err := s.occupyTable(ctx, table, now); err != nil {
+			return err
+		}
+
+		placed = types.PlacedOrder{Order: order, Order_items: items}
+		return nil
+	})
 	if err != nil {
-		return models.Order{}, err
+		return types.PlacedOrder{}, err
 	}
-
-	return order, nil
+	return placed, nil
 }
 
-func OrderItemOrderCreator(order models.Order) string {
-
-	var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
-	order.CreatedAt, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
-	order.UpdatedAt, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
-	order.ID = primitive.NewObjectID()
-	order.Order_id = order.ID.Hex()
-
-	orderCollection.InsertOne(ctx, order)
-	defer cancel()
-
-	return order.Order_id
+// offMenu reports a food that is not on a menu being served at the time
+func (s *Service) offMenu(ctx context.Context, prefix string, foodID string, at time.Time) ([]apperrors.FieldError, error) {
+	food, err := s.repos.Foods.FindByID(ctx, foodID)
+	if err != nil {
+		return nil, err
+	}
+	menu, err := s.repos.Menus.FindByID(ctx, food.Menu_id)
+	if err != nil && err != repositories.ErrNotFound {
+		return nil, err
+	}
+	if err == repositories.ErrNotFound || at.Before(menu.Start_Date) || !at.Before(menu.End_Date) {
+		return []apperrors.FieldError{{Field: prefix + "food_id", Message: "is not on a menu being served"}}, nil
+	}
+	return nil, nil
 }
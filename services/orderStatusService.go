@@ -0,0 +1,123 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/ShahSau/culinary-bliss/apperrors"
+	"github.com/ShahSau/culinary-bliss/helpers"
+	"github.com/ShahSau/culinary-bliss/listing"
+	"github.com/ShahSau/culinary-bliss/models"
+	"github.com/ShahSau/culinary-bliss/repositories"
+	"github.com/ShahSau/culinary-bliss/types"
+)
+
+// TransitionOrder moves an order with one of the helpers.OrderAction values and records when and by whom.
+// Moves that are not in helpers.OrderTransitions are a conflict, moves the actor's role may not make are forbidden.
+// Paying or cancelling an order frees its table unless another order is still open on it.
+func (s *Service) TransitionOrder(ctx context.Context, actor Actor, orderId string, action string) (models.Order, error) {
+	var order models.Order
+	err := s.repos.Transactions.InTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		order, err = s.GetOrderById(ctx, actor, orderId)
+		if err != nil {
+			return err
+		}
+
+		transition, err := orderTransition(order.Order_status, action)
+		if err != nil {
+			return err
+		}
+		// API keys have no role, so they cannot move orders
+		if actor.Api_key_id != "" || !helpers.HasRole(actor.Role, transition.Roles...) {
+			return apperrors.Forbidden(fmt.Sprintf("you are not allowed to %s an order that is %s", action, order.Order_status))
+		}
+
+		now, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		order.Order_status = transition.To
+		order.Status_history = append(order.Status_history, statusChange(actor, transition.To, now))
+		order.UpdatedAt = now
+		if err := s.repos.Orders.Update(ctx, order); err != nil {
+			return err
+		}
+
+		if helpers.IsOrderOpen(transition.From) && !helpers.IsOrderOpen(transition.To) {
+			return s.releaseTable(ctx, actor, order.Table_id, now)
+		}
+		return nil
+	})
+	if err != nil {
+		return models.Order{}, err
+	}
+	return order, nil
+}
+
+// occupyTable marks the table of an open order occupied
+func (s *Service) occupyTable(ctx context.Context, table models.Table, at time.Time) error {
+	if table.Table_status == tableOccupied {
+		return nil
+	}
+	table.Table_status = tableOccupied
+	table.UpdatedAt = at
+	return s.repos.Tables.Update(ctx, table)
+}
+
+// releaseTable frees an occupied table once none of its orders is open any more
+func (s *Service) releaseTable(ctx context.Context, actor Actor, tableID string, at time.Time) error {
+	table, err := s.repos.Tables.FindByID(ctx, tableID)
+	if err == repositories.ErrNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if table.Table_status != tableOccupied {
+		return nil
+	}
+
+	query, err := listing.Parse(orderListing, types.ListRequest{Query: url.Values{
+		"table_id":         {tableID},
+		"order_status[in]": {strings.Join(helpers.OrderOpenStatuses, ",")},
+		"limit":            {"1"},
+	}})
+	if err != nil {
+		return err
+	}
+	open, err := s.repos.Orders.List(ctx, query)
+	if err != nil {
+		return err
+	}
+	if open.Total > 0 {
+		return nil
+	}
+
+	table.Table_status = tableFree
+	table.UpdatedAt = at
+	return s.repos.Tables.Update(ctx, table)
+}
+
+// orderTransition finds the move the action makes from status
+func orderTransition(status string, action string) (helpers.OrderTransition, error) {
+	var from []string
+	for _, transition := range helpers.OrderTransitions {
+		if transition.Action != action {
+			continue
+		}
+		if transition.From == status {
+			return transition, nil
+		}
+		from = append(from, transition.From)
+	}
+	if len(from) == 0 {
+		return helpers.OrderTransition{}, apperrors.NotFound(fmt.Sprintf("orders have no %q action", action))
+	}
+	return helpers.OrderTransition{}, apperrors.Conflict(fmt.Sprintf("cannot %s an order that is %s, it has to be %s", action, status, strings.Join(from, " or ")))
+}
+
+// statusChange records that the actor moved an order to status
+func statusChange(actor Actor, status string, at time.Time) models.OrderStatusChange {
+	return models.OrderStatusChange{Status: status, At: at, User_id: actor.User_id, Api_key_id: actor.Api_key_id}
+}
@@ -0,0 +1,74 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/ShahSau/culinary-bliss/mailer"
+	"github.com/ShahSau/culinary-bliss/repositories"
+	"github.com/ShahSau/culinary-bliss/types"
+	"github.com/ShahSau/culinary-bliss/validation"
+)
+
+// passwordResetTTL is how long an emailed reset link can be used
+const passwordResetTTL = time.Hour
+
+// ForgotPassword emails a single use reset link to the user. It does not report whether the email belongs to an account.
+func (s *Service) ForgotPassword(ctx context.Context, actor Actor, req types.ForgotPassword) error {
+	if err := validation.Struct(req); err != nil {
+		return err
+	}
+	user, err := s.repos.Users.FindByEmail(ctx, req.Email)
+	if err != nil {
+		if err == repositories.ErrNotFound {
+			return nil
+		}
+		return err
+	}
+
+	token, err := issueOneTimeToken(ctx, s.repos.PasswordResets, user.User_id, passwordResetTTL)
+	if err != nil {
+		return err
+	}
+
+	err = s.mail.Send(mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your Culinary Bliss password",
+		Body: fmt.Sprintf("Hi %s,\n\nSomeone asked to reset the password of your Culinary Bliss account. "+
+			"Use the link below within %d minutes to choose a new one:\n\n%s\n\n"+
+			"If you did not ask for this you can ignore this email.",
+			user.First_name, int(passwordResetTTL.Minutes()), s.pageURL("/reset-password?token="+token)),
+	})
+	if err != nil {
+		log.Println("Error sending password reset email:", err)
+		return errors.New("could not send password reset email")
+	}
+
+	return nil
+}
+
+// ResetPasswordWithToken consumes a reset token and sets the new password. Every session of the user is logged out.
+func (s *Service) ResetPasswordWithToken(ctx context.Context, actor Actor, req types.TokenPasswordReset) error {
+	if err := validation.Struct(req); err != nil {
+		return err
+	}
+	reset, err := consumeOneTimeToken(ctx, s.repos.PasswordResets, req.Token)
+	if err != nil {
+		return err
+	}
+
+	updatedAt, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+	err = s.repos.Users.SetPassword(ctx, reset.User_id, HashPassword(req.NewPassword), updatedAt)
+	if err != nil {
+		if err == repositories.ErrNotFound {
+			return errInvalidOneTimeToken
+		}
+		return err
+	}
+
+	_, err = s.RevokeUserTokenFamilies(ctx, actor, reset.User_id)
+	return err
+}
@@ -1,113 +1,77 @@
 package services
 
 import (
-	"errors"
-	"strconv"
+	"context"
 	"time"
 
-	"github.com/ShahSau/culinary-bliss/database"
-	"github.com/ShahSau/culinary-bliss/helpers"
+	"github.com/ShahSau/culinary-bliss/apperrors"
+	"github.com/ShahSau/culinary-bliss/listing"
 	"github.com/ShahSau/culinary-bliss/models"
-	"github.com/gin-gonic/gin"
-	"go.mongodb.org/mongo-driver/bson"
+	"github.com/ShahSau/culinary-bliss/repositories"
+	"github.com/ShahSau/culinary-bliss/types"
+	"github.com/ShahSau/culinary-bliss/validation"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo"
 )
 
-var restaurantCollection *mongo.Collection = database.GetCollection(database.DB, "restaurants")
-
-func GetRestaurants(c *gin.Context) (models.ResponseRestaurant, error) {
-	recordPerPage, err := strconv.Atoi(c.Query("recordPerPage"))
-	if err != nil || recordPerPage < 1 {
-		recordPerPage = 10
-	}
-
-	page, err := strconv.Atoi(c.Query("page"))
-	if err != nil || page < 1 {
-		page = 1
-	}
-
-	startIndex := (page - 1) * recordPerPage
-
-	matchStage := bson.D{{Key: "$match", Value: bson.D{}}}
-	projectStage := bson.D{{Key: "$project", Value: bson.D{
-		{Key: "id", Value: 1},
-		{Key: "name", Value: 1},
-		{Key: "image", Value: 1},
-		{Key: "description", Value: 1},
-	}}}
-	skipStage := bson.D{{Key: "$skip", Value: startIndex}}
-	limitStage := bson.D{{Key: "$limit", Value: recordPerPage}}
+// restaurantListing is what restaurants can be filtered and sorted by
+var restaurantListing = listing.Spec{
+	ID:          "restaurant_id",
+	DefaultSort: "-created_at",
+	Fields: map[string]listing.Field{
+		"title":       {Kind: listing.String, Ops: listing.Equality, Sortable: true},
+		"pickup":      {Kind: listing.Bool, Ops: []listing.Op{listing.Eq}},
+		"delivery":    {Kind: listing.Bool, Ops: []listing.Op{listing.Eq}},
+		"rating":      {Kind: listing.Number, Ops: listing.Range, Sortable: true},
+		"ratingCount": {Kind: listing.Number, Ops: listing.Range, Sortable: true},
+		"created_at":  {Kind: listing.Time, Ops: listing.Range, Sortable: true},
+	},
+}
 
-	cursor, err := restaurantCollection.Aggregate(c.Request.Context(), mongo.Pipeline{
-		matchStage, projectStage, skipStage, limitStage,
-	})
+func (s *Service) GetRestaurants(ctx context.Context, actor Actor, req types.ListRequest) (listing.Page[models.Restaurant], error) {
+	query, err := listing.Parse(restaurantListing, req)
 	if err != nil {
-		return models.ResponseRestaurant{}, err
+		return listing.Page[models.Restaurant]{}, err
 	}
-	defer cursor.Close(c.Request.Context())
-
-	var restaurants []models.Restaurant
-	for cursor.Next(c.Request.Context()) {
-		var restaurant models.Restaurant
-		if err := cursor.Decode(&restaurant); err != nil {
-			return models.ResponseRestaurant{}, err
-		}
-		restaurants = append(restaurants, restaurant)
-	}
-
-	response := models.ResponseRestaurant{
-		AllRestaurants: restaurants,
-		Page:           page,
-		RecordPerPage:  recordPerPage,
-		StartIndex:     startIndex,
-	}
-
-	return response, nil
+	return s.repos.Restaurants.List(ctx, query)
 }
 
-func GetRestaurantByID(c *gin.Context, id string) (models.Restaurant, error) {
-	objectID, err := primitive.ObjectIDFromHex(id)
-	if err != nil {
-		return models.Restaurant{}, err
-	}
-	var restaurant models.Restaurant
-	err = restaurantCollection.FindOne(c.Request.Context(), bson.M{"_id": objectID}).Decode(&restaurant)
+func (s *Service) GetRestaurantByID(ctx context.Context, actor Actor, id string) (models.Restaurant, error) {
+	restaurant, err := s.repos.Restaurants.FindByID(ctx, id)
 	if err != nil {
+		if err == repositories.ErrNotFound {
+			return models.Restaurant{}, apperrors.NotFound("restaurant not found")
+		}
 		return models.Restaurant{}, err
 	}
 
 	return restaurant, nil
 }
 
-func CreateRestaurant(c *gin.Context, restaurantReq models.Restaurant) (models.Restaurant, error) {
+func (s *Service) CreateRestaurant(ctx context.Context, actor Actor, req types.Restaurant) (models.Restaurant, error) {
+	if err := validation.Struct(req); err != nil {
+		return models.Restaurant{}, err
+	}
 	var restaurant models.Restaurant
 
 	restaurant.ID = primitive.NewObjectID()
 	restaurant.Restaurant_id = restaurant.ID.Hex()
-	restaurant.Title = restaurantReq.Title
-	restaurant.Image = restaurantReq.Image
-	restaurant.Time = restaurantReq.Time
-	restaurant.Pickup = restaurantReq.Pickup
-	restaurant.Delivery = restaurantReq.Delivery
-	restaurant.Rating = restaurantReq.Rating
-	restaurant.RatingCount = restaurantReq.RatingCount
+	restaurant.Title = req.Title
+	restaurant.Image = req.Image
+	restaurant.Time = req.Time
+	restaurant.Pickup = req.Pickup
+	restaurant.Delivery = req.Delivery
+	restaurant.Rating = req.Rating
+	restaurant.RatingCount = req.RatingCount
 	restaurant.CreatedAt, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
 	restaurant.UpdatedAt, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
-	var menus []models.Menu
 
-	for _, item := range restaurantReq.Menu {
-		var menu models.Menu
-		err := menuCollection.FindOne(c.Request.Context(), bson.M{"menu_id": item}).Decode(&menu)
-		if err != nil {
-			return models.Restaurant{}, err
-		}
-		menus = append(menus, menu)
+	menus, err := s.restaurantMenus(ctx, actor, req.Menu)
+	if err != nil {
+		return models.Restaurant{}, err
 	}
 	restaurant.Menu = menus
 
-	_, err := restaurantCollection.InsertOne(c.Request.Context(), restaurant)
-
+	err = s.repos.Restaurants.Create(ctx, restaurant)
 	if err != nil {
 		return models.Restaurant{}, err
 	}
@@ -115,52 +79,47 @@ func CreateRestaurant(c *gin.Context, restaurantReq models.Restaurant) (models.R
 	return restaurant, nil
 }
 
-func UpdateRestaurant(c *gin.Context, id string, restaurantReq models.Restaurant) (models.Restaurant, error) {
-	objectID, err := primitive.ObjectIDFromHex(id)
-	if err != nil {
+func (s *Service) UpdateRestaurant(ctx context.Context, actor Actor, id string, req types.RestaurantUpdate) (models.Restaurant, error) {
+	if err := validation.Struct(req); err != nil {
 		return models.Restaurant{}, err
 	}
-
-	userEmail, _ := c.Get("first_name")
-	var isAdmin = helpers.IsAdmin(userEmail.(string))
-
-	if !isAdmin {
-		return models.Restaurant{}, errors.New("unauthorized")
-	}
-
-	var restaurant models.Restaurant
-	var menuCollection *mongo.Collection = database.GetCollection(database.DB, "menus")
-	err = restaurantCollection.FindOne(c.Request.Context(), bson.M{"_id": objectID}).Decode(&restaurant)
+	restaurant, err := s.GetRestaurantByID(ctx, actor, id)
 	if err != nil {
 		return models.Restaurant{}, err
 	}
 
-	restaurant.Title = restaurantReq.Title
-	restaurant.Image = restaurantReq.Image
-	restaurant.Time = restaurantReq.Time
-	restaurant.Pickup = restaurantReq.Pickup
-	restaurant.Delivery = restaurantReq.Delivery
-	restaurant.Rating = restaurantReq.Rating
-	restaurant.RatingCount = restaurantReq.RatingCount
+	if req.Title != nil {
+		restaurant.Title = *req.Title
+	}
+	if req.Image != nil {
+		restaurant.Image = *req.Image
+	}
+	if req.Time != nil {
+		restaurant.Time = *req.Time
+	}
+	if req.Pickup != nil {
+		restaurant.Pickup = *req.Pickup
+	}
+	if req.Delivery != nil {
+		restaurant.Delivery = *req.Delivery
+	}
+	if req.Rating != nil {
+		restaurant.Rating = *req.Rating
+	}
+	if req.RatingCount != nil {
+		restaurant.RatingCount = *req.RatingCount
+	}
 	restaurant.UpdatedAt, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
 
-	var menus []models.Menu
-
-	for _, item := range restaurantReq.Menu {
-		var menu models.Menu
-		err := menuCollection.FindOne(c.Request.Context(), bson.M{"menu_id": item}).Decode(&menu)
+	if req.Menu != nil {
+		menus, err := s.restaurantMenus(ctx, actor, *req.Menu)
 		if err != nil {
 			return models.Restaurant{}, err
 		}
-		menus = append(menus, menu)
-	}
-	restaurant.Menu = menus
-
-	update := bson.M{
-		"$set": restaurant,
+		restaurant.Menu = menus
 	}
 
-	err = restaurantCollection.FindOneAndUpdate(c.Request.Context(), bson.M{"_id": objectID}, update).Decode(&restaurant)
+	err = s.repos.Restaurants.Update(ctx, restaurant)
 	if err != nil {
 		return models.Restaurant{}, err
 	}
@@ -168,29 +127,60 @@ func UpdateRestaurant(c *gin.Context, id string, restaurantReq models.Restaurant
 	return restaurant, nil
 }
 
-func DeleteRestaurant(c *gin.Context, id string) (models.Restaurant, error) {
-	objectID, err := primitive.ObjectIDFromHex(id)
+func (s *Service) DeleteRestaurant(ctx context.Context, actor Actor, id string) (models.Restaurant, error) {
+	restaurant, err := s.GetRestaurantByID(ctx, actor, id)
 	if err != nil {
 		return models.Restaurant{}, err
 	}
 
-	userEmail, _ := c.Get("first_name")
-	var isAdmin = helpers.IsAdmin(userEmail.(string))
+	err = s.repos.Restaurants.Delete(ctx, id)
+	if err != nil {
+		return models.Restaurant{}, err
+	}
 
-	if !isAdmin {
-		return models.Restaurant{}, errors.New("unauthorized")
+	return restaurant, nil
+}
+
+// MenusByRestaurant returns the menus a restaurant serves
+func (s *Service) MenusByRestaurant(ctx context.Context, actor Actor, id string) ([]models.Menu, error) {
+	restaurant, err := s.GetRestaurantByID(ctx, actor, id)
+	if err != nil {
+		return nil, err
 	}
 
-	var restaurant models.Restaurant
-	err = restaurantCollection.FindOne(c.Request.Context(), bson.M{"_id": objectID}).Decode(&restaurant)
+	return restaurant.Menu, nil
+}
+
+// AddRating folds a new rating into the restaurant's average
+func (s *Service) AddRating(ctx context.Context, actor Actor, id string, req types.Rating) (models.Restaurant, error) {
+	if err := validation.Struct(req); err != nil {
+		return models.Restaurant{}, err
+	}
+	restaurant, err := s.GetRestaurantByID(ctx, actor, id)
 	if err != nil {
 		return models.Restaurant{}, err
 	}
 
-	_, err = restaurantCollection.DeleteOne(c.Request.Context(), bson.M{"_id": objectID})
+	restaurant.Rating = (restaurant.Rating*float64(restaurant.RatingCount) + req.Rating) / float64(restaurant.RatingCount+1)
+	restaurant.RatingCount = restaurant.RatingCount + 1
+
+	err = s.repos.Restaurants.Update(ctx, restaurant)
 	if err != nil {
 		return models.Restaurant{}, err
 	}
 
 	return restaurant, nil
 }
+
+// restaurantMenus looks up the stored menus a restaurant request refers to
+func (s *Service) restaurantMenus(ctx context.Context, actor Actor, menuIDs []string) ([]models.Menu, error) {
+	var menus []models.Menu
+	for _, menuID := range menuIDs {
+		menu, err := s.GetMenuByID(ctx, actor, menuID)
+		if err != nil {
+			return nil, err
+		}
+		menus = append(menus, menu)
+	}
+	return menus, nil
+}
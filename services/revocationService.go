@@ -0,0 +1,121 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ShahSau/culinary-bliss/helpers"
+	"github.com/ShahSau/culinary-bliss/models"
+	"github.com/ShahSau/culinary-bliss/tokens"
+)
+
+// revocationCacheTTL is how long a "not revoked" answer is trusted before the denylist is asked again.
+// Revocations made on this instance are visible immediately, revocations made on other instances after at most this long.
+const revocationCacheTTL = 30 * time.Second
+
+// revocationCacheSweepInterval is how often expired entries are dropped from the cache
+const revocationCacheSweepInterval = time.Minute
+
+type revocationEntry struct {
+	revoked bool
+	until   time.Time
+}
+
+type revocationCache struct {
+	sync.RWMutex
+	entries map[string]revocationEntry
+}
+
+func newRevocationCache() *revocationCache {
+	return &revocationCache{entries: map[string]revocationEntry{}}
+}
+
+// RevokeToken adds a key to the denylist until expiresAt
+func (s *Service) RevokeToken(ctx context.Context, key string, userID string, expiresAt time.Time) error {
+	createdAt, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+	err := s.repos.RevokedTokens.Add(ctx, models.RevokedToken{Key: key, User_id: userID, ExpiresAt: expiresAt, CreatedAt: createdAt})
+	if err != nil {
+		return err
+	}
+
+	s.revocations.Lock()
+	s.revocations.entries[key] = revocationEntry{revoked: true, until: expiresAt}
+	s.revocations.Unlock()
+
+	return nil
+}
+
+// IsTokenRevoked reports whether the token itself or the family it was issued from is on the denylist
+func (s *Service) IsTokenRevoked(ctx context.Context, claims *tokens.Claims) (bool, error) {
+	keys := []string{helpers.TokenRevocationKey(claims.ID)}
+	if claims.Family_id != "" {
+		keys = append(keys, helpers.FamilyRevocationKey(claims.Family_id))
+	}
+
+	var unknown []string
+	now := time.Now()
+
+	s.revocations.RLock()
+	for _, key := range keys {
+		entry, ok := s.revocations.entries[key]
+		if !ok || now.After(entry.until) {
+			unknown = append(unknown, key)
+			continue
+		}
+		if entry.revoked {
+			s.revocations.RUnlock()
+			return true, nil
+		}
+	}
+	s.revocations.RUnlock()
+
+	if len(unknown) == 0 {
+		return false, nil
+	}
+
+	revoked, err := s.repos.RevokedTokens.FindByKeys(ctx, unknown)
+	if err != nil {
+		return false, err
+	}
+
+	s.revocations.Lock()
+	defer s.revocations.Unlock()
+
+	for _, key := range unknown {
+		s.revocations.entries[key] = revocationEntry{revoked: false, until: now.Add(revocationCacheTTL)}
+	}
+	for _, entry := range revoked {
+		s.revocations.entries[entry.Key] = revocationEntry{revoked: true, until: entry.ExpiresAt}
+	}
+
+	return len(revoked) > 0, nil
+}
+
+// StartRevocationCacheSweep drops expired entries from the revocation cache so it does not grow without bound.
+// It returns when ctx is done.
+func (s *Service) StartRevocationCacheSweep(ctx context.Context) {
+	ticker := time.NewTicker(revocationCacheSweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			s.revocations.sweep(now)
+		}
+	}
+}
+
+// sweep removes the entries that expired before now
+func (c *revocationCache) sweep(now time.Time) {
+	c.Lock()
+	defer c.Unlock()
+
+	for key, entry := range c.entries {
+		if now.After(entry.until) {
+			delete(c.entries, key)
+		}
+	}
+}
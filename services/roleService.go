@@ -0,0 +1,182 @@
+package services
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ShahSau/culinary-bliss/apperrors"
+	"github.com/ShahSau/culinary-bliss/helpers"
+	"github.com/ShahSau/culinary-bliss/listing"
+	"github.com/ShahSau/culinary-bliss/models"
+	"github.com/ShahSau/culinary-bliss/repositories"
+	"github.com/ShahSau/culinary-bliss/types"
+	"github.com/ShahSau/culinary-bliss/validation"
+)
+
+var errRoleNotFound = apperrors.NotFound("role not found")
+
+func (s *Service) GetRoles(ctx context.Context, actor Actor) ([]models.Role, error) {
+	var roles []models.Role
+	for _, name := range helpers.Roles {
+		role, err := s.findRole(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		roles = append(roles, role)
+	}
+
+	return roles, nil
+}
+
+func (s *Service) GetRole(ctx context.Context, actor Actor, name string) (models.Role, error) {
+	if !helpers.IsValidRole(name) {
+		return models.Role{}, errRoleNotFound
+	}
+
+	return s.findRole(ctx, name)
+}
+
+func (s *Service) UpdateRolePermissions(ctx context.Context, actor Actor, name string, req types.RolePermissions) (models.Role, error) {
+	if err := validation.Struct(req); err != nil {
+		return models.Role{}, err
+	}
+	if !helpers.IsValidRole(name) {
+		return models.Role{}, errRoleNotFound
+	}
+
+	permissions := req.Permissions
+
+	for _, permission := range permissions {
+		if !helpers.IsValidPermission(permission) {
+			return models.Role{}, apperrors.Validationf("unknown permission %q", permission)
+		}
+	}
+
+	if name == helpers.RoleAdmin && !helpers.HasPermission(permissions, helpers.PermManageRoles) {
+		return models.Role{}, apperrors.Validation("the Admin role cannot give up the " + helpers.PermManageRoles + " permission")
+	}
+
+	role, err := s.findRole(ctx, name)
+	if err != nil {
+		return models.Role{}, err
+	}
+
+	updatedAt, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+	role.Permissions = permissions
+	if req.MfaRequired != nil {
+		role.Mfa_required = *req.MfaRequired
+	}
+	if role.CreatedAt.IsZero() {
+		role.CreatedAt = updatedAt
+	}
+	role.UpdatedAt = updatedAt
+
+	role, err = s.repos.Roles.Save(ctx, role)
+	if err != nil {
+		return models.Role{}, err
+	}
+
+	// tokens carry the permissions of the role, so the ones already issued must not outlive the change
+	if err := s.revokeRoleTokenFamilies(ctx, actor, name); err != nil {
+		return models.Role{}, err
+	}
+
+	return role, nil
+}
+
+// revokeRoleTokenFamilies ends the sessions of every user with the role, users stored with the legacy role of Customers included
+func (s *Service) revokeRoleTokenFamilies(ctx context.Context, actor Actor, name string) error {
+	roles := []string{name}
+	if name == helpers.RoleCustomer {
+		roles = append(roles, "User", "")
+	}
+	values := url.Values{"role[in]": {strings.Join(roles, ",")}, "sort": {"user_id"}, "limit": {strconv.Itoa(listing.MaxLimit)}}
+
+	for {
+		query, err := listing.Parse(userListing, types.ListRequest{Query: values})
+		if err != nil {
+			return err
+		}
+		page, err := s.repos.Users.List(ctx, query)
+		if err != nil {
+			return err
+		}
+		for _, user := range page.Items {
+			if _, err := s.RevokeUserTokenFamilies(ctx, actor, user.User_id); err != nil {
+				return err
+			}
+		}
+		if page.NextCursor == "" {
+			return nil
+		}
+		values.Set("cursor", page.NextCursor)
+	}
+}
+
+func (s *Service) UpdateUserRole(ctx context.Context, actor Actor, userID string, req types.UserRole) (models.User, error) {
+	if err := validation.Struct(req); err != nil {
+		return models.User{}, err
+	}
+	role := req.Role
+	if !helpers.IsValidRole(role) {
+		return models.User{}, errRoleNotFound
+	}
+
+	previous, err := s.findUserByID(ctx, userID)
+	if err != nil {
+		return models.User{}, err
+	}
+
+	updatedAt, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+	user, err := s.repos.Users.SetRole(ctx, userID, role, updatedAt)
+	if err != nil {
+		if err == repositories.ErrNotFound {
+			return models.User{}, apperrors.NotFound("user not found")
+		}
+		return models.User{}, err
+	}
+
+	// tokens carry the role and its permissions, so the ones already issued must not outlive the change
+	if helpers.NormalizeRole(previous.Role) != role {
+		if _, err := s.RevokeUserTokenFamilies(ctx, actor, userID); err != nil {
+			return models.User{}, err
+		}
+	}
+
+	return user, nil
+}
+
+// PermissionsForRole returns the permissions granted to a role, falling back to the default matrix when none are stored
+func (s *Service) PermissionsForRole(ctx context.Context, name string) ([]string, error) {
+	role, err := s.findRole(ctx, helpers.NormalizeRole(name))
+	if err != nil {
+		return nil, err
+	}
+
+	return role.Permissions, nil
+}
+
+// MfaRequiredForRole reports whether users with the role have to use two-factor authentication
+func (s *Service) MfaRequiredForRole(ctx context.Context, name string) (bool, error) {
+	role, err := s.findRole(ctx, helpers.NormalizeRole(name))
+	if err != nil {
+		return false, err
+	}
+
+	return role.Mfa_required, nil
+}
+
+func (s *Service) findRole(ctx context.Context, name string) (models.Role, error) {
+	role, err := s.repos.Roles.FindByName(ctx, name)
+	if err == repositories.ErrNotFound {
+		return models.Role{Name: name, Permissions: helpers.DefaultRolePermissions[name], Mfa_required: helpers.DefaultMfaRequired[name]}, nil
+	}
+	if err != nil {
+		return models.Role{}, err
+	}
+
+	return role, nil
+}
@@ -0,0 +1,25 @@
+package services
+
+import (
+	"github.com/ShahSau/culinary-bliss/mailer"
+	"github.com/ShahSau/culinary-bliss/repositories"
+)
+
+// Service runs the business logic against the repositories and mailer it was given
+type Service struct {
+	repos       *repositories.Repositories
+	mail        mailer.Mailer
+	appURL      string
+	revocations *revocationCache
+}
+
+// New returns a Service. main passes Mongo repositories, tests can pass repositories.NewMemory() and a mailer.FileMailer.
+// Emails link to pages under appURL.
+func New(repos *repositories.Repositories, mail mailer.Mailer, appURL string) *Service {
+	return &Service{
+		repos:       repos,
+		mail:        mail,
+		appURL:      appURL,
+		revocations: newRevocationCache(),
+	}
+}
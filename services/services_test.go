@@ -0,0 +1,1009 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ShahSau/culinary-bliss/apperrors"
+	"github.com/ShahSau/culinary-bliss/helpers"
+	"github.com/ShahSau/culinary-bliss/mailer"
+	"github.com/ShahSau/culinary-bliss/models"
+	"github.com/ShahSau/culinary-bliss/money"
+	"github.com/ShahSau/culinary-bliss/repositories"
+	"github.com/ShahSau/culinary-bliss/tokens"
+	"github.com/ShahSau/culinary-bliss/types"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func newTestService() *Service {
+	return New(repositories.NewMemory(), &mailer.LogMailer{}, "http://localhost:3000")
+}
+
+// newMenu is a menu that is active from now on for a day
+func newMenu(name string) types.Menu {
+	start := time.Now()
+	return types.Menu{Name: name, Description: name + " menu", Start_date: start, End_date: start.Add(24 * time.Hour)}
+}
+
+func TestCreateFoodRequiresExistingMenu(t *testing.T) {
+	s := newTestService()
+	ctx := context.Background()
+
+	_, err := s.CreateFood(ctx, Actor{}, types.Food{Name: "Soup", Description: "Hot", Price: money.MustParse("4.999"), Image: "soup.png", Menu_id: "missing"})
+	if err == nil || err.Error() != "menu not found" {
+		t.Fatalf("expected menu not found, got %v", err)
+	}
+
+	menu, err := s.CreateMenu(ctx, Actor{}, newMenu("Lunch"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	food, err := s.CreateFood(ctx, Actor{}, types.Food{Name: "Soup", Description: "Hot", Price: money.MustParse("4.999"), Image: "soup.png", Menu_id: menu.Menu_id})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if food.Price.String() != "5.00" {
+		t.Fatalf("expected the price to be rounded to the cent, got %v", food.Price)
+	}
+
+	found, err := s.GetFoodByID(ctx, Actor{}, food.Food_id)
+	if err != nil || found.Name != "Soup" {
+		t.Fatalf("expected the stored food, got %+v %v", found, err)
+	}
+}
+
+func TestUpdateFoodKeepsFieldsThatAreNotSent(t *testing.T) {
+	s := newTestService()
+	ctx := context.Background()
+
+	menu, _ := s.CreateMenu(ctx, Actor{}, newMenu("Dinner"))
+	food, _ := s.CreateFood(ctx, Actor{}, types.Food{Name: "Stew", Description: "Slow cooked", Price: money.MustParse("12"), Image: "stew.png", Menu_id: menu.Menu_id})
+
+	price := money.MustParse("14")
+	updated, err := s.UpdateFood(ctx, Actor{}, food.Food_id, types.FoodUpdate{Price: &price})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Name != "Stew" || updated.Description != "Slow cooked" || !updated.Price.Equal(price) {
+		t.Fatalf("unexpected update result %+v", updated)
+	}
+}
+
+func TestGetFoodsFiltersByMenu(t *testing.T) {
+	s := newTestService()
+	ctx := context.Background()
+
+	lunch, _ := s.CreateMenu(ctx, Actor{}, newMenu("Lunch"))
+	dinner, _ := s.CreateMenu(ctx, Actor{}, newMenu("Dinner"))
+	for _, name := range []string{"Soup", "Salad", "Sandwich"} {
+		s.CreateFood(ctx, Actor{}, types.Food{Name: name, Description: name, Price: money.MustParse("5"), Image: "food.png", Menu_id: lunch.Menu_id})
+	}
+	s.CreateFood(ctx, Actor{}, types.Food{Name: "Stew", Description: "Stew", Price: money.MustParse("12"), Image: "stew.png", Menu_id: dinner.Menu_id})
+
+	page, err := s.GetFoods(ctx, Actor{}, types.ListRequest{Query: url.Values{"menu_id": {lunch.Menu_id}, "limit": {"2"}, "sort": {"name"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page.Total != 3 || len(page.Items) != 2 || page.Items[0].Name != "Salad" || page.NextCursor == "" {
+		t.Fatalf("unexpected page %+v", page)
+	}
+
+	if _, err := s.GetFoods(ctx, Actor{}, types.ListRequest{Query: url.Values{"image": {"food.png"}}}); !errors.Is(err, apperrors.ErrValidation) {
+		t.Fatalf("expected an unknown filter to be rejected, got %v", err)
+	}
+}
+
+func TestAddRatingAveragesRatings(t *testing.T) {
+	s := newTestService()
+	ctx := context.Background()
+
+	menu, _ := s.CreateMenu(ctx, Actor{}, newMenu("Brunch"))
+	restaurant, err := s.CreateRestaurant(ctx, Actor{}, types.Restaurant{Title: "Bliss", Image: "bliss.png", Time: "9-17", Rating: 4, RatingCount: 1, Menu: []string{menu.Menu_id}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	restaurant, err = s.AddRating(ctx, Actor{}, restaurant.Restaurant_id, types.Rating{Rating: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restaurant.Rating != 4.5 || restaurant.RatingCount != 2 {
+		t.Fatalf("unexpected rating %v from %d ratings", restaurant.Rating, restaurant.RatingCount)
+	}
+
+	menus, err := s.MenusByRestaurant(ctx, Actor{}, restaurant.Restaurant_id)
+	if err != nil || len(menus) != 1 || menus[0].Name != "Brunch" {
+		t.Fatalf("expected the restaurant's menu, got %+v %v", menus, err)
+	}
+}
+
+func TestCreateOrderRequiresExistingTable(t *testing.T) {
+	s := newTestService()
+	ctx := context.Background()
+
+	_, err := s.CreateOrder(ctx, Actor{}, types.Order{Table_id: "missing"})
+	if err == nil || err.Error() != "table not found" {
+		t.Fatalf("expected table not found, got %v", err)
+	}
+
+	table, _ := s.CreateTable(ctx, Actor{}, types.Table{Number_of_guests: 2, Table_number: 7, Table_status: "FREE"})
+	order, err := s.CreateOrder(ctx, Actor{}, types.Order{Table_id: table.Table_id})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if order.Order_status != helpers.OrderStatusPlaced || len(order.Status_history) != 1 {
+		t.Fatalf("expected a placed order, got %+v", order)
+	}
+
+	if _, err := s.DeleteOrder(ctx, Actor{}, order.Order_id); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.GetOrderById(ctx, Actor{}, order.Order_id); !errors.Is(err, apperrors.ErrNotFound) {
+		t.Fatalf("expected order not found after delete, got %v", err)
+	}
+}
+
+func TestTransitionOrderFollowsTheLifecycle(t *testing.T) {
+	s := newTestService()
+	ctx := context.Background()
+
+	waiter := Actor{User_id: "waiter", Role: helpers.RoleWaiter}
+	kitchen := Actor{User_id: "kitchen", Role: helpers.RoleKitchen}
+	table, _ := s.CreateTable(ctx, Actor{}, types.Table{Number_of_guests: 2, Table_number: 7, Table_status: "FREE"})
+	order, _ := s.CreateOrder(ctx, waiter, types.Order{Table_id: table.Table_id})
+
+	if _, err := s.TransitionOrder(ctx, waiter, order.Order_id, helpers.OrderActionServe); !errors.Is(err, apperrors.ErrConflict) {
+		t.Fatalf("expected serving a placed order to conflict, got %v", err)
+	}
+	if _, err := s.TransitionOrder(ctx, kitchen, order.Order_id, helpers.OrderActionAccept); !errors.Is(err, apperrors.ErrForbidden) {
+		t.Fatalf("expected the kitchen not to accept orders, got %v", err)
+	}
+	if _, err := s.TransitionOrder(ctx, waiter, order.Order_id, "eat"); !errors.Is(err, apperrors.ErrNotFound) {
+		t.Fatalf("expected an unknown action to be not found, got %v", err)
+	}
+
+	steps := []struct {
+		actor  Actor
+		action string
+	}{
+		{waiter, helpers.OrderActionAccept},
+		{kitchen, helpers.OrderActionPrepare},
+		{kitchen, helpers.OrderActionReady},
+		{waiter, helpers.OrderActionServe},
+		{waiter, helpers.OrderActionPay},
+	}
+	for _, step := range steps {
+		var err error
+		if order, err = s.TransitionOrder(ctx, step.actor, order.Order_id, step.action); err != nil {
+			t.Fatalf("%s: %v", step.action, err)
+		}
+	}
+
+	var statuses []string
+	for _, change := range order.Status_history {
+		statuses = append(statuses, change.Status)
+	}
+	want := []string{"PLACED", "ACCEPTED", "PREPARING", "READY", "SERVED", "PAID"}
+	if order.Order_status != "PAID" || strings.Join(statuses, ",") != strings.Join(want, ",") || order.Status_history[5].User_id != "waiter" {
+		t.Fatalf("unexpected history %+v", order.Status_history)
+	}
+
+	if _, err := s.TransitionOrder(ctx, waiter, order.Order_id, helpers.OrderActionRefund); !errors.Is(err, apperrors.ErrForbidden) {
+		t.Fatalf("expected only managers to refund, got %v", err)
+	}
+	if _, err := s.TransitionOrder(ctx, Actor{Role: helpers.RoleManager}, order.Order_id, helpers.OrderActionRefund); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCancelOrderOnceTheKitchenStartedNeedsAManager(t *testing.T) {
+	s := newTestService()
+	ctx := context.Background()
+
+	waiter := Actor{Role: helpers.RoleWaiter}
+	table, _ := s.CreateTable(ctx, Actor{}, types.Table{Number_of_guests: 2, Table_number: 7, Table_status: "FREE"})
+	order, _ := s.CreateOrder(ctx, waiter, types.Order{Table_id: table.Table_id})
+	s.TransitionOrder(ctx, waiter, order.Order_id, helpers.OrderActionAccept)
+	s.TransitionOrder(ctx, Actor{Role: helpers.RoleKitchen}, order.Order_id, helpers.OrderActionPrepare)
+
+	if _, err := s.TransitionOrder(ctx, waiter, order.Order_id, helpers.OrderActionCancel); !errors.Is(err, apperrors.ErrForbidden) {
+		t.Fatalf("expected a waiter not to cancel a preparing order, got %v", err)
+	}
+	order, err := s.TransitionOrder(ctx, Actor{Role: helpers.RoleManager}, order.Order_id, helpers.OrderActionCancel)
+	if err != nil || order.Order_status != helpers.OrderStatusCancelled {
+		t.Fatalf("expected the manager to cancel, got %+v %v", order, err)
+	}
+	if _, err := s.TransitionOrder(ctx, Actor{Role: helpers.RoleManager}, order.Order_id, helpers.OrderActionCancel); !errors.Is(err, apperrors.ErrConflict) {
+		t.Fatalf("expected a cancelled order to stay cancelled, got %v", err)
+	}
+}
+
+func TestPlaceOrderPricesItemsAndOccupiesTable(t *testing.T) {
+	s := newTestService()
+	ctx := context.Background()
+
+	menu, _ := s.CreateMenu(ctx, Actor{}, newMenu("Lunch"))
+	soup, _ := s.CreateFood(ctx, Actor{}, types.Food{Name: "Soup", Description: "Hot", Price: money.MustParse("4.5"), Image: "soup.png", Menu_id: menu.Menu_id, Modifiers: []types.Modifier{{Name: "Bread", Price: money.MustParse("0.8")}}})
+	stew, _ := s.CreateFood(ctx, Actor{}, types.Food{Name: "Stew", Description: "Slow cooked", Price: money.MustParse("12"), Image: "stew.png", Menu_id: menu.Menu_id})
+	table, _ := s.CreateTable(ctx, Actor{}, types.Table{Number_of_guests: 2, Table_number: 7, Table_status: "FREE"})
+
+	// a small soup with bread is 4.5 x 0.75 + 0.8 = 4.175, rounded to 4.18 a bowl. A large stew is 12 x 1.5.
+	total := money.MustParse("26.36")
+	placed, err := s.PlaceOrder(ctx, Actor{}, types.OrderItemPack{Table_id: table.Table_id, Total_amount: &total, Order_items: []types.OrderLine{
+		{Food_id: soup.Food_id, Quantity: 2, Portion: "S", Modifiers: []string{"Bread"}},
+		{Food_id: stew.Food_id, Quantity: 1, Portion: "L", Notes: "No parsley"},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !placed.Order.Total_amount.Equal(total) || placed.Order.Order_status != "PLACED" || len(placed.Order_items) != 2 {
+		t.Fatalf("unexpected placed order %+v", placed)
+	}
+	if soupItem := placed.Order_items[0]; soupItem.Unit_price.String() != "4.18" || soupItem.Total_amount.String() != "8.36" || soupItem.Modifiers[0].Price.String() != "0.80" {
+		t.Fatalf("unexpected soup item %+v", soupItem)
+	}
+
+	stored, err := s.GetOrderItemByID(ctx, Actor{}, placed.Order_items[1].Order_item_id)
+	if err != nil || stored.Order_id != placed.Order.Order_id || stored.Notes != "No parsley" {
+		t.Fatalf("expected the item to be stored under the order with its notes, got %+v %v", stored, err)
+	}
+	if table, _ := s.GetTable(ctx, Actor{}, table.Table_id); table.Table_status != "OCCUPIED" {
+		t.Fatalf("expected the table to be occupied, got %q", table.Table_status)
+	}
+}
+
+func TestClosingTheLastOpenOrderFreesTheTable(t *testing.T) {
+	s := newTestService()
+	ctx := context.Background()
+
+	menu, _ := s.CreateMenu(ctx, Actor{}, newMenu("Lunch"))
+	soup, _ := s.CreateFood(ctx, Actor{}, types.Food{Name: "Soup", Description: "Hot", Price: money.MustParse("4.5"), Image: "soup.png", Menu_id: menu.Menu_id})
+	table, _ := s.CreateTable(ctx, Actor{}, types.Table{Number_of_guests: 2, Table_number: 7, Table_status: "FREE"})
+	place := func() models.Order {
+		placed, err := s.PlaceOrder(ctx, Actor{}, types.OrderItemPack{Table_id: table.Table_id, Order_items: []types.OrderLine{{Food_id: soup.Food_id, Quantity: 1}}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return placed.Order
+	}
+	tableStatus := func() string {
+		table, _ := s.GetTable(ctx, Actor{}, table.Table_id)
+		return table.Table_status
+	}
+
+	manager := Actor{Role: helpers.RoleManager}
+	first, second := place(), place()
+	if _, err := s.TransitionOrder(ctx, manager, first.Order_id, helpers.OrderActionCancel); err != nil {
+		t.Fatal(err)
+	}
+	if status := tableStatus(); status != "OCCUPIED" {
+		t.Fatalf("expected the table to stay occupied by the other order, got %q", status)
+	}
+
+	for _, action := range []string{helpers.OrderActionAccept, helpers.OrderActionPrepare, helpers.OrderActionReady, helpers.OrderActionServe, helpers.OrderActionPay} {
+		if _, err := s.TransitionOrder(ctx, manager, second.Order_id, action); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if status := tableStatus(); status != "FREE" {
+		t.Fatalf("expected the paid order to free the table, got %q", status)
+	}
+
+	third := place()
+	if _, err := s.TransitionOrder(ctx, manager, third.Order_id, helpers.OrderActionCancel); err != nil {
+		t.Fatal(err)
+	}
+	if status := tableStatus(); status != "FREE" {
+		t.Fatalf("expected the cancelled order to free the table, got %q", status)
+	}
+}
+
+func TestOrdersOccupyTheirTableUntilMovedOrDeleted(t *testing.T) {
+	s := newTestService()
+	ctx := context.Background()
+
+	menu, _ := s.CreateMenu(ctx, Actor{}, newMenu("Lunch"))
+	soup, _ := s.CreateFood(ctx, Actor{}, types.Food{Name: "Soup", Description: "Hot", Price: money.MustParse("4.5"), Image: "soup.png", Menu_id: menu.Menu_id})
+	window, _ := s.CreateTable(ctx, Actor{}, types.Table{Number_of_guests: 2, Table_number: 7, Table_status: "FREE"})
+	corner, _ := s.CreateTable(ctx, Actor{}, types.Table{Number_of_guests: 4, Table_number: 8, Table_status: "FREE"})
+	tableStatus := func(table models.Table) string {
+		table, _ = s.GetTable(ctx, Actor{}, table.Table_id)
+		return table.Table_status
+	}
+
+	order, err := s.CreateOrder(ctx, Actor{}, types.Order{Table_id: window.Table_id})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status := tableStatus(window); status != "OCCUPIED" {
+		t.Fatalf("expected the new order to occupy its table, got %q", status)
+	}
+
+	if _, err := s.UpdateOrder(ctx, Actor{}, order.Order_id, types.OrderUpdate{Table_id: &corner.Table_id}); err != nil {
+		t.Fatal(err)
+	}
+	if window, corner := tableStatus(window), tableStatus(corner); window != "FREE" || corner != "OCCUPIED" {
+		t.Fatalf("expected the order to take its table along, got %q and %q", window, corner)
+	}
+
+	item, err := s.CreateOrderItem(ctx, Actor{}, types.OrderItem{Order_id: order.Order_id, Food_id: soup.Food_id, Quantity: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.DeleteOrder(ctx, Actor{}, order.Order_id); err != nil {
+		t.Fatal(err)
+	}
+	if status := tableStatus(corner); status != "FREE" {
+		t.Fatalf("expected deleting the open order to free its table, got %q", status)
+	}
+	if _, err := s.GetOrderItemByID(ctx, Actor{}, item.Order_item_id); !errors.Is(err, apperrors.ErrNotFound) {
+		t.Fatalf("expected the items to be deleted with the order, got %v", err)
+	}
+}
+
+func TestPlaceOrderWritesNothingWhenAnItemIsWrong(t *testing.T) {
+	s := newTestService()
+	ctx := context.Background()
+
+	menu, _ := s.CreateMenu(ctx, Actor{}, newMenu("Lunch"))
+	soup, _ := s.CreateFood(ctx, Actor{}, types.Food{Name: "Soup", Description: "Hot", Price: money.MustParse("4.5"), Image: "soup.png", Menu_id: menu.Menu_id})
+	table, _ := s.CreateTable(ctx, Actor{}, types.Table{Number_of_guests: 2, Table_number: 7, Table_status: "FREE"})
+
+	_, err := s.PlaceOrder(ctx, Actor{}, types.OrderItemPack{Table_id: table.Table_id, Order_items: []types.OrderLine{
+		{Food_id: soup.Food_id, Quantity: 1},
+		{Food_id: "missing", Quantity: 1, Portion: "XL"},
+	}})
+	if got := fieldErrors(t, err); !reflect.DeepEqual(got, map[string]string{"order_items[1].portion": "must be one of S, M, L"}) {
+		t.Fatalf("expected the portion to be rejected first, got %v", got)
+	}
+
+	wrong := money.MustParse("4")
+	_, err = s.PlaceOrder(ctx, Actor{}, types.OrderItemPack{Table_id: table.Table_id, Order_items: []types.OrderLine{
+		{Food_id: soup.Food_id, Quantity: 1, Total_amount: &wrong},
+		{Food_id: soup.Food_id, Quantity: 1, Modifiers: []string{"Croutons"}},
+		{Food_id: "missing", Quantity: 1},
+	}})
+	want := map[string]string{
+		"order_items[0].total_amount": "does not match the computed total of 4.50",
+		"order_items[1].modifiers":    `unknown modifier "Croutons" for Soup`,
+		"order_items[2].food_id":      "food not found",
+	}
+	if got := fieldErrors(t, err); !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+
+	_, err = s.PlaceOrder(ctx, Actor{}, types.OrderItemPack{Table_id: table.Table_id, Total_amount: &wrong, Order_items: []types.OrderLine{{Food_id: soup.Food_id, Quantity: 1}}})
+	if got := fieldErrors(t, err); !reflect.DeepEqual(got, map[string]string{"total_amount": "does not match the computed total of 4.50"}) {
+		t.Fatalf("expected the order total to be checked, got %v", got)
+	}
+
+	orders, _ := s.GetOrders(ctx, Actor{}, types.ListRequest{})
+	items, _ := s.GetOrderItems(ctx, Actor{}, types.ListRequest{})
+	if orders.Total != 0 || items.Total != 0 {
+		t.Fatalf("expected nothing to be stored, got %d orders and %d items", orders.Total, items.Total)
+	}
+	if table, _ := s.GetTable(ctx, Actor{}, table.Table_id); table.Table_status != "FREE" {
+		t.Fatalf("expected the table to stay free, got %q", table.Table_status)
+	}
+}
+
+func TestPlaceOrderOnlyTakesFoodFromServedMenus(t *testing.T) {
+	s := newTestService()
+	ctx := context.Background()
+
+	lunch, _ := s.CreateMenu(ctx, Actor{}, newMenu("Lunch"))
+	tomorrow := time.Now().Add(24 * time.Hour)
+	brunch, _ := s.CreateMenu(ctx, Actor{}, types.Menu{Name: "Brunch", Description: "Brunch menu", Start_date: tomorrow, End_date: tomorrow.Add(4 * time.Hour)})
+	soup, _ := s.CreateFood(ctx, Actor{}, types.Food{Name: "Soup", Description: "Hot", Price: money.MustParse("4.5"), Image: "soup.png", Menu_id: lunch.Menu_id})
+	eggs, _ := s.CreateFood(ctx, Actor{}, types.Food{Name: "Eggs", Description: "Poached", Price: money.MustParse("7"), Image: "eggs.png", Menu_id: brunch.Menu_id})
+	table, _ := s.CreateTable(ctx, Actor{}, types.Table{Number_of_guests: 2, Table_number: 7, Table_status: "FREE"})
+
+	_, err := s.PlaceOrder(ctx, Actor{}, types.OrderItemPack{Table_id: table.Table_id, Order_items: []types.OrderLine{
+		{Food_id: soup.Food_id, Quantity: 1},
+		{Food_id: eggs.Food_id, Quantity: 2},
+	}})
+	if got := fieldErrors(t, err); !reflect.DeepEqual(got, map[string]string{"order_items[1].food_id": "is not on a menu being served"}) {
+		t.Fatalf("expected the brunch dish to be rejected, got %v", got)
+	}
+}
+
+func TestGetOrderDetailsJoinsTableItemsAndFood(t *testing.T) {
+	s := newTestService()
+	ctx := context.Background()
+
+	menu, _ := s.CreateMenu(ctx, Actor{}, newMenu("Lunch"))
+	soup, _ := s.CreateFood(ctx, Actor{}, types.Food{Name: "Soup", Description: "Hot", Price: money.MustParse("4.5"), Image: "soup.png", Menu_id: menu.Menu_id})
+	stew, _ := s.CreateFood(ctx, Actor{}, types.Food{Name: "Stew", Description: "Slow cooked", Price: money.MustParse("12"), Image: "stew.png", Menu_id: menu.Menu_id})
+	table, _ := s.CreateTable(ctx, Actor{}, types.Table{Number_of_guests: 2, Table_number: 7, Table_status: "FREE"})
+	placed, err := s.PlaceOrder(ctx, Actor{}, types.OrderItemPack{Table_id: table.Table_id, Order_items: []types.OrderLine{
+		{Food_id: stew.Food_id, Quantity: 1},
+		{Food_id: soup.Food_id, Quantity: 2, Notes: "No cream"},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	details, err := s.GetOrderDetails(ctx, Actor{}, placed.Order.Order_id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if details.Order.Order_id != placed.Order.Order_id || details.Table == nil || details.Table.Table_number != 7 {
+		t.Fatalf("unexpected order and table %+v", details)
+	}
+	if len(details.Order_items) != 2 || details.Payment_due.String() != "21.00" {
+		t.Fatalf("unexpected items %+v due %s", details.Order_items, details.Payment_due)
+	}
+	soupLine := details.Order_items[1]
+	if soupLine.Name != "Soup" || soupLine.Image != "soup.png" || soupLine.Quantity != 2 || soupLine.Unit_price.String() != "4.50" || soupLine.Total_amount.String() != "9.00" || soupLine.Notes != "No cream" {
+		t.Fatalf("unexpected soup line %+v", soupLine)
+	}
+
+	if _, err := s.GetOrderDetails(ctx, Actor{}, "missing"); !errors.Is(err, apperrors.ErrNotFound) {
+		t.Fatalf("expected a missing order to be not found, got %v", err)
+	}
+}
+
+func TestOrderItemsKeepTheOrderTotal(t *testing.T) {
+	s := newTestService()
+	ctx := context.Background()
+
+	menu, _ := s.CreateMenu(ctx, Actor{}, newMenu("Lunch"))
+	soup, _ := s.CreateFood(ctx, Actor{}, types.Food{Name: "Soup", Description: "Hot", Price: money.MustParse("4.5"), Image: "soup.png", Menu_id: menu.Menu_id})
+	table, _ := s.CreateTable(ctx, Actor{}, types.Table{Number_of_guests: 2, Table_number: 7, Table_status: "FREE"})
+	order, _ := s.CreateOrder(ctx, Actor{}, types.Order{Table_id: table.Table_id})
+
+	item, err := s.CreateOrderItem(ctx, Actor{}, types.OrderItem{Food_id: soup.Food_id, Order_id: order.Order_id, Quantity: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	three := 3
+	if _, err := s.UpdateOrderItem(ctx, Actor{}, item.Order_item_id, types.OrderItemUpdate{Quantity: &three}); err != nil {
+		t.Fatal(err)
+	}
+	s.CreateOrderItem(ctx, Actor{}, types.OrderItem{Food_id: soup.Food_id, Order_id: order.Order_id, Quantity: 1, Portion: "L"})
+	if order, _ = s.GetOrderById(ctx, Actor{}, order.Order_id); order.Total_amount.String() != "20.25" {
+		t.Fatalf("expected 3 x 4.50 + 6.75, got %v", order.Total_amount)
+	}
+
+	if _, err := s.DeleteOrderItem(ctx, Actor{}, item.Order_item_id); err != nil {
+		t.Fatal(err)
+	}
+	if order, _ = s.GetOrderById(ctx, Actor{}, order.Order_id); order.Total_amount.String() != "6.75" {
+		t.Fatalf("expected the deleted item to come off the total, got %v", order.Total_amount)
+	}
+
+	wrong := money.MustParse("10")
+	if _, err := s.UpdateOrder(ctx, Actor{}, order.Order_id, types.OrderUpdate{Total_amount: &wrong}); !errors.Is(err, apperrors.ErrValidation) {
+		t.Fatalf("expected a total that disagrees to be rejected, got %v", err)
+	}
+}
+
+func TestOrderItemsOnlyChangeWhileTheOrderIsEditable(t *testing.T) {
+	s := newTestService()
+	ctx := context.Background()
+
+	menu, _ := s.CreateMenu(ctx, Actor{}, newMenu("Lunch"))
+	soup, _ := s.CreateFood(ctx, Actor{}, types.Food{Name: "Soup", Description: "Hot", Price: money.MustParse("4.5"), Image: "soup.png", Menu_id: menu.Menu_id})
+	table, _ := s.CreateTable(ctx, Actor{}, types.Table{Number_of_guests: 2, Table_number: 7, Table_status: "FREE"})
+	order, _ := s.CreateOrder(ctx, Actor{}, types.Order{Table_id: table.Table_id})
+	item, err := s.CreateOrderItem(ctx, Actor{}, types.OrderItem{Food_id: soup.Food_id, Order_id: order.Order_id, Quantity: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	manager := Actor{Role: helpers.RoleManager}
+	s.TransitionOrder(ctx, manager, order.Order_id, helpers.OrderActionAccept)
+	two := 2
+	if _, err := s.UpdateOrderItem(ctx, Actor{}, item.Order_item_id, types.OrderItemUpdate{Quantity: &two}); err != nil {
+		t.Fatalf("expected an accepted order to be editable, got %v", err)
+	}
+
+	s.TransitionOrder(ctx, manager, order.Order_id, helpers.OrderActionPrepare)
+	if _, err := s.CreateOrderItem(ctx, Actor{}, types.OrderItem{Food_id: soup.Food_id, Order_id: order.Order_id, Quantity: 1}); !errors.Is(err, apperrors.ErrConflict) {
+		t.Fatalf("expected adding an item to be a conflict, got %v", err)
+	}
+	if _, err := s.UpdateOrderItem(ctx, Actor{}, item.Order_item_id, types.OrderItemUpdate{Quantity: &two}); !errors.Is(err, apperrors.ErrConflict) {
+		t.Fatalf("expected changing an item to be a conflict, got %v", err)
+	}
+	if _, err := s.DeleteOrderItem(ctx, Actor{}, item.Order_item_id); !errors.Is(err, apperrors.ErrConflict) {
+		t.Fatalf("expected removing an item to be a conflict, got %v", err)
+	}
+
+	// nor can an item move onto it from an order that is still editable
+	other, _ := s.CreateOrder(ctx, Actor{}, types.Order{Table_id: table.Table_id})
+	moved, _ := s.CreateOrderItem(ctx, Actor{}, types.OrderItem{Food_id: soup.Food_id, Order_id: other.Order_id, Quantity: 1})
+	if _, err := s.UpdateOrderItem(ctx, Actor{}, moved.Order_item_id, types.OrderItemUpdate{Order_id: &order.Order_id}); !errors.Is(err, apperrors.ErrConflict) {
+		t.Fatalf("expected moving an item onto the order to be a conflict, got %v", err)
+	}
+}
+
+// fieldErrors returns the field errors of err by field, or fails the test if it is not a validation error
+func fieldErrors(t *testing.T, err error) map[string]string {
+	t.Helper()
+	var domain *apperrors.Error
+	if !errors.As(err, &domain) || !errors.Is(err, apperrors.ErrValidation) {
+		t.Fatalf("expected a validation error, got %v", err)
+	}
+	found := map[string]string{}
+	for _, field := range domain.Fields {
+		found[field.Field] = field.Message
+	}
+	return found
+}
+
+func TestRegisterUserRejectsDuplicateEmail(t *testing.T) {
+	s := newTestService()
+	ctx := context.Background()
+
+	req := types.RegisterUser{FirstName: "Ada", LastName: "Lovelace", Email: "ada@example.com", Password: "secret", Phone: "+44 20 7946 0958"}
+	if _, err := s.RegisterUser(ctx, Actor{}, req); err != nil {
+		t.Fatal(err)
+	}
+
+	req.Phone = "+44 20 7946 0959"
+	if _, err := s.RegisterUser(ctx, Actor{}, req); !errors.Is(err, apperrors.ErrConflict) {
+		t.Fatalf("expected a conflict, got %v", err)
+	}
+}
+
+func TestUpdateMenuKeepsEndAfterStart(t *testing.T) {
+	s := newTestService()
+	ctx := context.Background()
+
+	menu, err := s.CreateMenu(ctx, Actor{}, newMenu("Lunch"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	end := menu.Start_Date.Add(-time.Hour)
+	if _, err := s.UpdateMenu(ctx, Actor{}, menu.Menu_id, types.MenuUpdate{End_date: &end}); !errors.Is(err, apperrors.ErrValidation) {
+		t.Fatalf("expected a validation error, got %v", err)
+	}
+}
+
+const testPassword = "correct horse"
+
+// testPasswordHash is hashed once, bcrypt at the cost HashPassword uses takes about a second
+var testPasswordHash = sync.OnceValue(func() string { return HashPassword(testPassword) })
+
+// setupSigningKeys signs tokens with a fresh key for the test
+func setupSigningKeys(t *testing.T) {
+	t.Helper()
+	tokens.Configure(tokens.Settings{KeyDir: t.TempDir(), SigningAlg: tokens.SigningAlgEdDSA, Issuer: "culinary-bliss", Audience: "culinary-bliss-api"})
+	if err := tokens.LoadSigningKeys(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// newTestUser stores an active user whose password is testPassword
+func newTestUser(t *testing.T, s *Service, email string, role string) models.User {
+	t.Helper()
+	now := time.Now()
+	user := models.User{
+		ID:         primitive.NewObjectID(),
+		User_id:    primitive.NewObjectID().Hex(),
+		First_name: "Ada",
+		Last_name:  "Lovelace",
+		Email:      email,
+		Phone:      "phone of " + email,
+		Password:   testPasswordHash(),
+		Role:       role,
+		Status:     helpers.UserStatusActive,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	if err := s.repos.Users.Create(context.Background(), user); err != nil {
+		t.Fatal(err)
+	}
+	return user
+}
+
+// loginAttempt returns the failures currently counted against the key
+func loginAttempt(t *testing.T, s *Service, key string) models.LoginAttempt {
+	t.Helper()
+	attempts, err := s.repos.LoginAttempts.FindByKeys(context.Background(), []string{key})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(attempts) == 0 {
+		return models.LoginAttempt{}
+	}
+	return attempts[0]
+}
+
+func TestLoginUserKeepsFailuresWhileTheSecondFactorIsPending(t *testing.T) {
+	setupSigningKeys(t)
+	s := newTestService()
+	ctx := context.Background()
+
+	user := newTestUser(t, s, "ada@example.com", "Customer")
+	if err := s.repos.Users.EnableMfa(ctx, user.User_id, "JBSWY3DPEHPK3PXP", nil, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < loginDelayAfter-1; i++ {
+		if err := s.recordLoginFailure(ctx, Actor{Ip_address: "10.0.0.1"}, user.Email); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	_, _, _, err := s.LoginUser(ctx, Actor{Ip_address: "10.0.0.1"}, types.Loginuser{Email: user.Email, Password: testPassword})
+	var mfaRequired *MfaRequiredError
+	if !errors.As(err, &mfaRequired) {
+		t.Fatalf("expected a second factor to be required, got %v", err)
+	}
+	if failures := loginAttempt(t, s, accountAttemptKey(user.Email)).Failures; failures != loginDelayAfter-1 {
+		t.Fatalf("expected the failures to be kept, got %d", failures)
+	}
+}
+
+func TestLoginDelayDoublesUpToTheMaximum(t *testing.T) {
+	tests := []struct {
+		failures int
+		delay    time.Duration
+	}{
+		{failures: loginDelayAfter, delay: time.Second},
+		{failures: loginDelayAfter + 1, delay: 2 * time.Second},
+		{failures: loginDelayAfter + 4, delay: 16 * time.Second},
+		{failures: loginDelayAfter + 5, delay: maxLoginDelay},
+		{failures: loginDelayAfter + 70, delay: maxLoginDelay},
+	}
+	for _, test := range tests {
+		if delay := loginDelay(test.failures); delay != test.delay {
+			t.Errorf("%d failures: expected %s, got %s", test.failures, test.delay, delay)
+		}
+	}
+}
+
+func TestCountLoginFailureLocksLongerEveryTime(t *testing.T) {
+	s := newTestService()
+	ctx := context.Background()
+
+	expected := []time.Duration{lockoutDuration, 2 * lockoutDuration, 4 * lockoutDuration}
+	for lockouts, duration := range expected {
+		for i := 1; i <= maxAccountFailures; i++ {
+			locked, err := s.countLoginFailure(ctx, "account:ada@example.com", maxAccountFailures)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if i < maxAccountFailures && locked != 0 {
+				t.Fatalf("locked after %d failures", i)
+			}
+			if i == maxAccountFailures && locked != duration {
+				t.Fatalf("lockout %d: expected %s, got %s", lockouts+1, duration, locked)
+			}
+		}
+
+		attempt := loginAttempt(t, s, accountAttemptKey("ada@example.com"))
+		if attempt.Failures != 0 || attempt.Lockouts != lockouts+1 || time.Until(attempt.LockedUntil) <= duration-time.Minute {
+			t.Fatalf("unexpected counter after lockout %d: %+v", lockouts+1, attempt)
+		}
+	}
+
+	if err := s.checkLoginAllowed(ctx, "ada@example.com", "10.0.0.1"); !errors.Is(err, apperrors.ErrTooManyRequests) {
+		t.Fatalf("expected the account to be locked, got %v", err)
+	}
+}
+
+func TestCountLoginFailureCapsTheLockout(t *testing.T) {
+	s := newTestService()
+	ctx := context.Background()
+
+	now := time.Now()
+	key := accountAttemptKey("ada@example.com")
+	for i := 0; i < 10; i++ {
+		if _, err := s.repos.LoginAttempts.RecordFailure(ctx, key, now, now.Add(loginFailureWindow)); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.repos.LoginAttempts.Lock(ctx, key, now, now.Add(loginFailureWindow)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	locked, err := s.countLoginFailure(ctx, key, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if locked != maxLockoutDuration {
+		t.Fatalf("expected %s, got %s", maxLockoutDuration, locked)
+	}
+}
+
+func TestRecordLoginFailureLimitsAccountsAndAddresses(t *testing.T) {
+	s := newTestService()
+	ctx := context.Background()
+	actor := Actor{Ip_address: "10.0.0.1"}
+
+	for i := 0; i < maxAccountFailures; i++ {
+		if err := s.recordLoginFailure(ctx, actor, "ada@example.com"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if attempt := loginAttempt(t, s, accountAttemptKey("ada@example.com")); attempt.Lockouts != 1 {
+		t.Fatalf("expected the account to be locked, got %+v", attempt)
+	}
+	// the address has only been slowed down
+	if attempt := loginAttempt(t, s, ipAttemptKey("10.0.0.1")); attempt.Failures != maxAccountFailures || attempt.Lockouts != 0 {
+		t.Fatalf("expected the address not to be locked yet, got %+v", attempt)
+	}
+
+	// spreading the failures over accounts still locks the address
+	for i := maxAccountFailures; i < maxIPFailures; i++ {
+		if err := s.recordLoginFailure(ctx, actor, fmt.Sprintf("user%d@example.com", i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if attempt := loginAttempt(t, s, ipAttemptKey("10.0.0.1")); attempt.Lockouts != 1 {
+		t.Fatalf("expected the address to be locked, got %+v", attempt)
+	}
+	if err := s.checkLoginAllowed(ctx, "grace@example.com", "10.0.0.1"); !errors.Is(err, apperrors.ErrTooManyRequests) {
+		t.Fatalf("expected the address to be locked, got %v", err)
+	}
+	if attempt := loginAttempt(t, s, accountAttemptKey("user19@example.com")); attempt.Failures != 1 {
+		t.Fatalf("expected a single failure on the other accounts, got %+v", attempt)
+	}
+	if err := s.checkLoginAllowed(ctx, "grace@example.com", "10.0.0.2"); err != nil {
+		t.Fatalf("expected another address to be allowed, got %v", err)
+	}
+}
+
+func TestResetLoginFailuresKeepsTheLockouts(t *testing.T) {
+	s := newTestService()
+	ctx := context.Background()
+
+	for i := 0; i < maxAccountFailures+1; i++ {
+		if err := s.recordLoginFailure(ctx, Actor{Ip_address: "10.0.0.1"}, "ada@example.com"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.resetLoginFailures(ctx, "ada@example.com"); err != nil {
+		t.Fatal(err)
+	}
+
+	attempt := loginAttempt(t, s, accountAttemptKey("ada@example.com"))
+	if attempt.Failures != 0 || !attempt.LockedUntil.IsZero() || attempt.Lockouts != 1 {
+		t.Fatalf("unexpected counter %+v", attempt)
+	}
+	if err := s.checkLoginAllowed(ctx, "ada@example.com", "10.0.0.2"); err != nil {
+		t.Fatalf("expected the account to be allowed, got %v", err)
+	}
+
+	// the next lockout lasts twice as long
+	for i := 1; i < maxAccountFailures; i++ {
+		if _, err := s.countLoginFailure(ctx, accountAttemptKey("ada@example.com"), maxAccountFailures); err != nil {
+			t.Fatal(err)
+		}
+	}
+	locked, err := s.countLoginFailure(ctx, accountAttemptKey("ada@example.com"), maxAccountFailures)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if locked != 2*lockoutDuration {
+		t.Fatalf("expected %s, got %s", 2*lockoutDuration, locked)
+	}
+}
+
+func TestUpdateUserRoleEndsTheSessions(t *testing.T) {
+	setupSigningKeys(t)
+	s := newTestService()
+	ctx := context.Background()
+
+	user := newTestUser(t, s, "ada@example.com", "Customer")
+	access, _, err := s.IssueTokens(ctx, Actor{}, user)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.UpdateUserRole(ctx, Actor{}, user.User_id, types.UserRole{Role: "Waiter"}); err != nil {
+		t.Fatal(err)
+	}
+
+	claims, err := tokens.Parse(access, tokens.AccessToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if revoked, err := s.IsTokenRevoked(ctx, claims); err != nil || !revoked {
+		t.Fatalf("expected the access token to be revoked, got %v %v", revoked, err)
+	}
+	if sessions, err := s.GetSessions(ctx, Actor{}, user.User_id); err != nil || len(sessions) != 0 {
+		t.Fatalf("expected no sessions, got %v %v", sessions, err)
+	}
+}
+
+func TestUpdateRolePermissionsEndsTheSessionsOfTheRole(t *testing.T) {
+	setupSigningKeys(t)
+	s := newTestService()
+	ctx := context.Background()
+
+	waiter := newTestUser(t, s, "ada@example.com", "Waiter")
+	kitchen := newTestUser(t, s, "grace@example.com", "Kitchen")
+	waiterAccess, _, err := s.IssueTokens(ctx, Actor{}, waiter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kitchenAccess, _, err := s.IssueTokens(ctx, Actor{}, kitchen)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.UpdateRolePermissions(ctx, Actor{}, "Waiter", types.RolePermissions{Permissions: []string{helpers.PermReadOrders}}); err != nil {
+		t.Fatal(err)
+	}
+
+	for access, want := range map[string]bool{waiterAccess: true, kitchenAccess: false} {
+		claims, err := tokens.Parse(access, tokens.AccessToken)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if revoked, err := s.IsTokenRevoked(ctx, claims); err != nil || revoked != want {
+			t.Fatalf("expected the token of a %s to be revoked: %v, got %v %v", claims.Role, want, revoked, err)
+		}
+	}
+}
+
+func TestDeleteUserEndsTheSessions(t *testing.T) {
+	setupSigningKeys(t)
+	s := newTestService()
+	ctx := context.Background()
+
+	user := newTestUser(t, s, "ada@example.com", "Customer")
+	if _, _, err := s.IssueTokens(ctx, Actor{}, user); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.DeleteUser(ctx, Actor{}, user.User_id); err != nil {
+		t.Fatal(err)
+	}
+
+	if families, err := s.repos.TokenFamilies.ListUnrevoked(ctx, user.User_id); err != nil || len(families) != 0 {
+		t.Fatalf("expected every session of the deleted user to be revoked, got %v %v", families, err)
+	}
+}
+
+func TestResetPasswordEndsTheOtherSessions(t *testing.T) {
+	setupSigningKeys(t)
+	s := newTestService()
+	ctx := context.Background()
+
+	user := newTestUser(t, s, "ada@example.com", "Customer")
+	current, _, err := s.IssueTokens(ctx, Actor{}, user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := s.IssueTokens(ctx, Actor{}, user); err != nil {
+		t.Fatal(err)
+	}
+	claims, err := tokens.Parse(current, tokens.AccessToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	actor := Actor{User_id: user.User_id, Family_id: claims.Family_id}
+	if _, err := s.ResetPassword(ctx, actor, types.PasswordReset{Email: user.Email, OldPassword: testPassword, NewPassword: "a new passphrase"}); err != nil {
+		t.Fatal(err)
+	}
+
+	families, err := s.repos.TokenFamilies.ListUnrevoked(ctx, user.User_id)
+	if err != nil || len(families) != 1 || families[0].Family_id != claims.Family_id {
+		t.Fatalf("expected only the session that changed the password to be left, got %v %v", families, err)
+	}
+}
+
+func TestRevocationCacheSweepDropsExpiredEntries(t *testing.T) {
+	cache := newRevocationCache()
+	now := time.Now()
+	cache.entries["token:expired"] = revocationEntry{revoked: true, until: now.Add(-time.Second)}
+	cache.entries["token:live"] = revocationEntry{revoked: false, until: now.Add(time.Second)}
+
+	cache.sweep(now)
+
+	if _, ok := cache.entries["token:expired"]; ok {
+		t.Fatal("expected the expired entry to be dropped")
+	}
+	if _, ok := cache.entries["token:live"]; !ok {
+		t.Fatal("expected the live entry to be kept")
+	}
+}
+
+func TestMfaTokenIsSingleUse(t *testing.T) {
+	setupSigningKeys(t)
+	s := newTestService()
+	ctx := context.Background()
+
+	const secret = "JBSWY3DPEHPK3PXP"
+	user := newTestUser(t, s, "ada@example.com", "Customer")
+	if err := s.repos.Users.EnableMfa(ctx, user.User_id, secret, nil, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	_, _, _, err := s.LoginUser(ctx, Actor{}, types.Loginuser{Email: user.Email, Password: testPassword})
+	var mfaRequired *MfaRequiredError
+	if !errors.As(err, &mfaRequired) {
+		t.Fatalf("expected a second factor to be required, got %v", err)
+	}
+
+	code, err := helpers.TOTPCode(secret, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, access, refresh, _, err := s.CompleteMfaLogin(ctx, Actor{}, types.MfaLogin{MfaToken: mfaRequired.Token, Code: code})
+	if err != nil || access == "" || refresh == "" {
+		t.Fatalf("expected tokens, got %q %q %v", access, refresh, err)
+	}
+
+	// the next code would pass, the token it comes with must not
+	next, _ := helpers.TOTPCode(secret, time.Now().Add(30*time.Second))
+	if _, _, _, _, err := s.CompleteMfaLogin(ctx, Actor{}, types.MfaLogin{MfaToken: mfaRequired.Token, Code: next}); err != errInvalidMfaToken {
+		t.Fatalf("expected the used token to be refused, got %v", err)
+	}
+}
+
+func TestRefreshTokenReuseRevokesTheFamily(t *testing.T) {
+	setupSigningKeys(t)
+	s := newTestService()
+	ctx := context.Background()
+
+	user := newTestUser(t, s, "ada@example.com", "Customer")
+	_, first, err := s.IssueTokens(ctx, Actor{}, user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	access, second, err := s.RefreshTokens(ctx, Actor{}, types.RefreshToken{RefreshToken: first})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// someone replays the refresh token that was already exchanged
+	if _, _, err := s.RefreshTokens(ctx, Actor{}, types.RefreshToken{RefreshToken: first}); err != errRefreshTokenReuse {
+		t.Fatalf("expected the reuse to be detected, got %v", err)
+	}
+
+	if _, _, err := s.RefreshTokens(ctx, Actor{}, types.RefreshToken{RefreshToken: second}); !errors.Is(err, apperrors.ErrUnauthorized) {
+		t.Fatalf("expected the latest refresh token of the family to be revoked too, got %v", err)
+	}
+	claims, err := tokens.Parse(access, tokens.AccessToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if revoked, err := s.IsTokenRevoked(ctx, claims); err != nil || !revoked {
+		t.Fatalf("expected the access token of the family to be revoked, got %v %v", revoked, err)
+	}
+}
+
+func TestLockedAccountCanBeUnlocked(t *testing.T) {
+	setupSigningKeys(t)
+	s := newTestService()
+	ctx := context.Background()
+
+	user := newTestUser(t, s, "ada@example.com", "Customer")
+	login := types.Loginuser{Email: user.Email, Password: testPassword}
+	for i := 0; i < maxAccountFailures; i++ {
+		if err := s.recordLoginFailure(ctx, Actor{Ip_address: "10.0.0.1"}, user.Email); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// even the right password is refused while the account is locked, from any address
+	_, _, _, err := s.LoginUser(ctx, Actor{Ip_address: "10.0.0.2"}, login)
+	var throttled *LoginThrottledError
+	if !errors.As(err, &throttled) || throttled.RetryAfter < lockoutDuration {
+		t.Fatalf("expected the account to be locked for %s, got %v", lockoutDuration, err)
+	}
+
+	if err := s.UnlockUser(ctx, Actor{User_id: "admin"}, user.User_id); err != nil {
+		t.Fatal(err)
+	}
+	if _, access, _, err := s.LoginUser(ctx, Actor{Ip_address: "10.0.0.2"}, login); err != nil || access == "" {
+		t.Fatalf("expected the unlocked account to login, got %v", err)
+	}
+	if attempt := loginAttempt(t, s, accountAttemptKey(user.Email)); attempt.Lockouts != 1 || attempt.Failures != 0 {
+		t.Fatalf("expected the lockout to still count, got %+v", attempt)
+	}
+}
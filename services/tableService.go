@@ -1,86 +1,76 @@
 package services
 
 import (
+	"context"
 	"errors"
-	"net/http"
 	"time"
 
-	"github.com/ShahSau/culinary-bliss/helpers"
+	"github.com/ShahSau/culinary-bliss/apperrors"
+	"github.com/ShahSau/culinary-bliss/listing"
 	"github.com/ShahSau/culinary-bliss/models"
-	"github.com/gin-gonic/gin"
-	"go.mongodb.org/mongo-driver/bson"
+	"github.com/ShahSau/culinary-bliss/repositories"
+	"github.com/ShahSau/culinary-bliss/types"
+	"github.com/ShahSau/culinary-bliss/validation"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
-func GetTables(c *gin.Context) ([]models.Table, error) {
-	tables, err := tableCollection.Find(c.Request.Context(), bson.M{}, nil)
+// tableListing is what tables can be filtered and sorted by
+var tableListing = listing.Spec{
+	ID:          "table_id",
+	DefaultSort: "table_number",
+	Fields: map[string]listing.Field{
+		"table_number":     {Kind: listing.Number, Ops: listing.Range, Sortable: true},
+		"table_status":     {Kind: listing.String, Ops: listing.Equality},
+		"restaurant_id":    {Kind: listing.String, Ops: listing.Equality},
+		"number_of_guests": {Kind: listing.Number, Ops: listing.Range, Sortable: true},
+		"created_at":       {Kind: listing.Time, Ops: listing.Range, Sortable: true},
+	},
+}
 
+func (s *Service) GetTables(ctx context.Context, actor Actor, req types.ListRequest) (listing.Page[models.Table], error) {
+	query, err := listing.Parse(tableListing, req)
 	if err != nil {
-		return nil, err
-	}
-
-	defer tables.Close(c.Request.Context())
-
-	var results []models.Table
-
-	for tables.Next(c.Request.Context()) {
-		var table models.Table
-		if err = tables.Decode(&table); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": true, "message": err.Error()})
-		}
-		results = append(results, table)
+		return listing.Page[models.Table]{}, err
 	}
-
-	return results, nil
+	return s.repos.Tables.List(ctx, actor.restaurantFilter(query))
 }
 
-func GetTable(c *gin.Context, id string) (models.Table, error) {
-
-	table_id, err := primitive.ObjectIDFromHex(id)
+// GetTable finds a table, the tables of other restaurants are not found for an API key
+func (s *Service) GetTable(ctx context.Context, actor Actor, id string) (models.Table, error) {
+	table, err := s.repos.Tables.FindByID(ctx, id)
 	if err != nil {
+		if err == repositories.ErrNotFound {
+			return models.Table{}, apperrors.NotFound("table not found")
+		}
 		return models.Table{}, err
 	}
-
-	var table models.Table
-
-	defer c.Request.Body.Close()
-
-	err = tableCollection.FindOne(c.Request.Context(), bson.M{"_id": table_id}).Decode(&table)
-
-	if err != nil {
-		return table, err
+	if !actor.inRestaurant(table.Restaurant_id) {
+		return models.Table{}, apperrors.NotFound("table not found")
 	}
 
 	return table, nil
 }
 
-func CreateTable(c *gin.Context, tableReq models.Table) (models.Table, error) {
-	var table models.Table
-
-	if err := c.ShouldBindJSON(&table); err != nil {
+func (s *Service) CreateTable(ctx context.Context, actor Actor, req types.Table) (models.Table, error) {
+	if err := validation.Struct(req); err != nil {
 		return models.Table{}, err
 	}
-
-	userEmail, _ := c.Get("first_name")
-	var isAdmin = helpers.IsAdmin(userEmail.(string))
-
-	if !isAdmin {
-		return models.Table{}, errors.New("Unauthorized")
+	if err := s.tableRestaurant(ctx, actor, req.Restaurant_id); err != nil {
+		return models.Table{}, err
 	}
-
 	var newTable models.Table
 
-	newTable.Number_of_guests = tableReq.Number_of_guests
-	newTable.Table_number = tableReq.Table_number
-	newTable.Table_status = tableReq.Table_status
+	newTable.Restaurant_id = req.Restaurant_id
+	newTable.Number_of_guests = req.Number_of_guests
+	newTable.Table_number = req.Table_number
+	newTable.Table_status = req.Table_status
 	newTable.CreatedAt, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
 	newTable.UpdatedAt, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
 
 	newTable.ID = primitive.NewObjectID()
 	newTable.Table_id = newTable.ID.Hex()
 
-	_, err := tableCollection.InsertOne(c.Request.Context(), newTable)
-
+	err := s.repos.Tables.Create(ctx, newTable)
 	if err != nil {
 		return models.Table{}, err
 	}
@@ -88,67 +78,62 @@ func CreateTable(c *gin.Context, tableReq models.Table) (models.Table, error) {
 	return newTable, nil
 }
 
-func UpdateTable(c *gin.Context, id string, tableReq models.Table) (models.Table, error) {
-	objectID, err := primitive.ObjectIDFromHex(id)
-	if err != nil {
+func (s *Service) UpdateTable(ctx context.Context, actor Actor, id string, req types.TableUpdate) (models.Table, error) {
+	if err := validation.Struct(req); err != nil {
 		return models.Table{}, err
 	}
-
-	userEmail, _ := c.Get("first_name")
-	var isAdmin = helpers.IsAdmin(userEmail.(string))
-
-	if !isAdmin {
-		return models.Table{}, errors.New("unauthorized")
-	}
-
-	defer c.Request.Body.Close()
-
-	var updatedTable models.Table
-
-	err = tableCollection.FindOne(c.Request.Context(), bson.M{"table_id": objectID}).Decode(&updatedTable)
-
+	updatedTable, err := s.GetTable(ctx, actor, id)
 	if err != nil {
 		return models.Table{}, err
 	}
 
-	updatedTable.Number_of_guests = tableReq.Number_of_guests
-	updatedTable.Table_number = tableReq.Table_number
-	updatedTable.Table_status = tableReq.Table_status
+	if req.Number_of_guests != nil {
+		updatedTable.Number_of_guests = *req.Number_of_guests
+	}
+	if req.Table_number != nil {
+		updatedTable.Table_number = *req.Table_number
+	}
+	if req.Table_status != nil {
+		updatedTable.Table_status = *req.Table_status
+	}
+	if req.Restaurant_id != nil {
+		if err := s.tableRestaurant(ctx, actor, *req.Restaurant_id); err != nil {
+			return models.Table{}, err
+		}
+		updatedTable.Restaurant_id = *req.Restaurant_id
+	}
 	updatedTable.UpdatedAt, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
 
-	_, err = tableCollection.UpdateOne(c.Request.Context(), bson.M{"table_id": objectID}, bson.D{{Key: "$set", Value: updatedTable}})
+	err = s.repos.Tables.Update(ctx, updatedTable)
 	if err != nil {
 		return models.Table{}, err
 	}
 	return updatedTable, nil
 }
 
-func DeleteTable(c *gin.Context, id string) (models.Table, error) {
-	objectID, err := primitive.ObjectIDFromHex(id)
+func (s *Service) DeleteTable(ctx context.Context, actor Actor, id string) (models.Table, error) {
+	deletedTable, err := s.GetTable(ctx, actor, id)
 	if err != nil {
 		return models.Table{}, err
 	}
 
-	userEmail, _ := c.Get("first_name")
-	var isAdmin = helpers.IsAdmin(userEmail.(string))
-
-	if !isAdmin {
-		return models.Table{}, errors.New("unauthorized")
-	}
-
-	defer c.Request.Body.Close()
-
-	var deletedTable models.Table
-
-	err = tableCollection.FindOne(c.Request.Context(), bson.M{"table_id": objectID}).Decode(&deletedTable)
-
+	err = s.repos.Tables.Delete(ctx, id)
 	if err != nil {
 		return models.Table{}, err
 	}
+	return deletedTable, nil
+}
 
-	_, err = tableCollection.DeleteOne(c.Request.Context(), bson.M{"table_id": objectID})
-	if err != nil {
-		return models.Table{}, err
+// tableRestaurant checks the restaurant a table is put in exists, a table does not have to be in one
+func (s *Service) tableRestaurant(ctx context.Context, actor Actor, restaurantID string) error {
+	if restaurantID == "" {
+		return nil
 	}
-	return deletedTable, nil
+	if _, err := s.GetRestaurantByID(ctx, actor, restaurantID); err != nil {
+		if errors.Is(err, apperrors.ErrNotFound) {
+			return apperrors.Validation("restaurant not found")
+		}
+		return err
+	}
+	return nil
 }
@@ -0,0 +1,195 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/ShahSau/culinary-bliss/apperrors"
+	"github.com/ShahSau/culinary-bliss/helpers"
+	"github.com/ShahSau/culinary-bliss/models"
+	"github.com/ShahSau/culinary-bliss/repositories"
+	"github.com/ShahSau/culinary-bliss/tokens"
+	"github.com/ShahSau/culinary-bliss/types"
+	"github.com/ShahSau/culinary-bliss/validation"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	errInvalidRefreshToken = apperrors.Unauthorized("invalid refresh token")
+	errRefreshTokenReuse   = apperrors.Unauthorized("refresh token reuse detected, please login again")
+)
+
+// IssueTokens starts a new refresh token family for the user and returns the first access and refresh tokens of it
+func (s *Service) IssueTokens(ctx context.Context, actor Actor, user models.User) (string, string, error) {
+	now, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+	family := models.TokenFamily{
+		ID:         primitive.NewObjectID(),
+		User_id:    user.User_id,
+		User_agent: actor.User_agent,
+		Ip_address: actor.Ip_address,
+		ExpiresAt:  now.Add(tokens.RefreshTokenTTL),
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	family.Family_id = family.ID.Hex()
+
+	token, refreshToken, err := s.generateTokens(ctx, user, family.Family_id)
+	if err != nil {
+		return "", "", err
+	}
+	family.Refresh_token_hash = helpers.HashToken(refreshToken)
+
+	err = s.repos.TokenFamilies.Create(ctx, family)
+	if err != nil {
+		return "", "", err
+	}
+
+	return token, refreshToken, nil
+}
+
+// RefreshTokens exchanges a refresh token for a new access and refresh token pair.
+// Presenting a refresh token that was already exchanged revokes its whole family.
+func (s *Service) RefreshTokens(ctx context.Context, actor Actor, req types.RefreshToken) (string, string, error) {
+	if err := validation.Struct(req); err != nil {
+		return "", "", err
+	}
+	refreshToken := req.RefreshToken
+	claims, err := tokens.Parse(refreshToken, tokens.RefreshToken)
+	if err != nil || claims.Family_id == "" {
+		return "", "", errInvalidRefreshToken
+	}
+
+	family, err := s.repos.TokenFamilies.FindByID(ctx, claims.Family_id)
+	if err != nil {
+		return "", "", errInvalidRefreshToken
+	}
+
+	if family.Revoked {
+		return "", "", apperrors.Unauthorized("refresh token has been revoked")
+	}
+
+	if family.Refresh_token_hash != helpers.HashToken(refreshToken) {
+		if err := s.RevokeTokenFamily(ctx, family.Family_id); err != nil {
+			return "", "", err
+		}
+		return "", "", errRefreshTokenReuse
+	}
+
+	user, err := s.repos.Users.FindByID(ctx, family.User_id)
+	if err != nil {
+		return "", "", apperrors.Unauthorized("user not found")
+	}
+
+	token, newRefreshToken, err := s.generateTokens(ctx, user, family.Family_id)
+	if err != nil {
+		return "", "", err
+	}
+
+	previousHash := family.Refresh_token_hash
+	updatedAt, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+	family.Refresh_token_hash = helpers.HashToken(newRefreshToken)
+	family.User_agent = actor.User_agent
+	family.Ip_address = actor.Ip_address
+	family.ExpiresAt = updatedAt.Add(tokens.RefreshTokenTTL)
+	family.UpdatedAt = updatedAt
+
+	rotated, err := s.repos.TokenFamilies.Rotate(ctx, family, previousHash)
+	if err != nil {
+		return "", "", err
+	}
+
+	// another request rotated the same refresh token first
+	if !rotated {
+		if err := s.RevokeTokenFamily(ctx, family.Family_id); err != nil {
+			return "", "", err
+		}
+		return "", "", errRefreshTokenReuse
+	}
+
+	return token, newRefreshToken, nil
+}
+
+// RevokeTokenFamily stops the family from being refreshed and denylists every access token issued from it
+func (s *Service) RevokeTokenFamily(ctx context.Context, familyID string) error {
+	family, err := s.repos.TokenFamilies.FindByID(ctx, familyID)
+	if err != nil {
+		if err == repositories.ErrNotFound {
+			return apperrors.NotFound("session not found")
+		}
+		return err
+	}
+
+	updatedAt, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+	err = s.repos.TokenFamilies.Revoke(ctx, familyID, updatedAt)
+	if err != nil {
+		return err
+	}
+
+	return s.RevokeToken(ctx, helpers.FamilyRevocationKey(familyID), family.User_id, time.Now().Add(tokens.AccessTokenTTL))
+}
+
+// RevokeUserTokenFamilies logs the user out of every device and returns how many sessions were ended
+func (s *Service) RevokeUserTokenFamilies(ctx context.Context, actor Actor, userID string) (int, error) {
+	families, err := s.repos.TokenFamilies.ListUnrevoked(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, family := range families {
+		if err := s.RevokeTokenFamily(ctx, family.Family_id); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(families), nil
+}
+
+// GetSessions lists the devices the user is currently logged in on
+func (s *Service) GetSessions(ctx context.Context, actor Actor, userID string) ([]models.Session, error) {
+	families, err := s.repos.TokenFamilies.ListUnrevoked(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	sessions := []models.Session{}
+	for _, family := range families {
+		if !family.ExpiresAt.After(now) {
+			continue
+		}
+		sessions = append(sessions, models.Session{
+			Session_id: family.Family_id,
+			User_agent: family.User_agent,
+			Ip_address: family.Ip_address,
+			Current:    family.Family_id == actor.Family_id,
+			CreatedAt:  family.CreatedAt,
+			LastUsedAt: family.UpdatedAt,
+			ExpiresAt:  family.ExpiresAt,
+		})
+	}
+
+	return sessions, nil
+}
+
+// RevokeSession logs the user out of a single device
+func (s *Service) RevokeSession(ctx context.Context, actor Actor, userID string, sessionID string) error {
+	family, err := s.repos.TokenFamilies.FindByID(ctx, sessionID)
+	if err != nil && err != repositories.ErrNotFound {
+		return err
+	}
+	if err == repositories.ErrNotFound || family.User_id != userID {
+		return apperrors.NotFound("session not found")
+	}
+
+	return s.RevokeTokenFamily(ctx, sessionID)
+}
+
+func (s *Service) generateTokens(ctx context.Context, user models.User, familyID string) (string, string, error) {
+	role := helpers.NormalizeRole(user.Role)
+	permissions, err := s.PermissionsForRole(ctx, role)
+	if err != nil {
+		return "", "", err
+	}
+
+	return helpers.GenerateAllTokens(user.Email, user.First_name, user.Last_name, user.User_id, role, permissions, familyID)
+}
@@ -1,149 +1,162 @@
 package services
 
 import (
-	"errors"
-	"log"
-	"strconv"
+	"context"
 	"time"
 
-	"github.com/ShahSau/culinary-bliss/helpers"
+	"github.com/ShahSau/culinary-bliss/apperrors"
+	"github.com/ShahSau/culinary-bliss/listing"
 	"github.com/ShahSau/culinary-bliss/models"
+	"github.com/ShahSau/culinary-bliss/repositories"
 	"github.com/ShahSau/culinary-bliss/types"
-	"github.com/gin-gonic/gin"
-	"go.mongodb.org/mongo-driver/bson"
-	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo"
+	"github.com/ShahSau/culinary-bliss/validation"
 )
 
-func GetUsers(c *gin.Context) (models.ResponseUser, error) {
-	userEmail, _ := c.Get("first_name")
-	var isAdmin = helpers.IsAdmin(userEmail.(string))
+// userListing is what users can be filtered and sorted by
+var userListing = listing.Spec{
+	ID:          "user_id",
+	DefaultSort: "-created_at",
+	Fields: map[string]listing.Field{
+		"email":      {Kind: listing.String, Ops: listing.Equality},
+		"role":       {Kind: listing.String, Ops: listing.Equality},
+		"status":     {Kind: listing.String, Ops: listing.Equality},
+		"first_name": {Kind: listing.String, Ops: listing.Equality, Sortable: true},
+		"last_name":  {Kind: listing.String, Ops: listing.Equality, Sortable: true},
+		"created_at": {Kind: listing.Time, Ops: listing.Range, Sortable: true},
+	},
+}
 
-	if !isAdmin {
-		return models.ResponseUser{}, errors.New("you are not authorized to view this resource")
-	}
-	recordPerPage, err := strconv.Atoi(c.Query("recordPerPage"))
-	if err != nil || recordPerPage < 1 {
-		recordPerPage = 10
+func (s *Service) GetUsers(ctx context.Context, actor Actor, req types.ListRequest) (listing.Page[types.User], error) {
+	query, err := listing.Parse(userListing, req)
+	if err != nil {
+		return listing.Page[types.User]{}, err
 	}
-
-	page, errp := strconv.Atoi(c.Query("page"))
-	if errp != nil || page < 1 {
-		page = 1
+	page, err := s.repos.Users.List(ctx, query)
+	if err != nil {
+		return listing.Page[types.User]{}, err
 	}
 
-	startIndex := (page - 1) * recordPerPage
-	startIndex, err = strconv.Atoi(c.Query("startIndex"))
-
-	matchStage := bson.D{{Key: "$match", Value: bson.D{{}}}}
-	projectStage := bson.D{
-		{
-			Key: "$project", Value: bson.D{
-				{Key: "id", Value: 1},
-				{Key: "first_name", Value: 1},
-				{Key: "last_name", Value: 1},
-				{Key: "email", Value: 1},
-				{Key: "phone", Value: 1},
-				{Key: "role", Value: 1},
-			},
-		},
+	// only the profile is listed, never credentials
+	users := listing.Page[types.User]{Items: []types.User{}, Total: page.Total, Limit: page.Limit, NextCursor: page.NextCursor}
+	for _, user := range page.Items {
+		users.Items = append(users.Items, types.NewUser(user))
 	}
 
-	record := bson.D{{Key: "$skip", Value: recordPerPage * (page - 1)}}
-	limit := bson.D{{Key: "$limit", Value: recordPerPage}}
+	return users, nil
+}
 
-	result, errAgg := userCollection.Aggregate(c.Request.Context(), mongo.Pipeline{matchStage, projectStage, record, limit})
+func (s *Service) GetUser(ctx context.Context, actor Actor, id string) (models.User, error) {
+	return s.findUserByID(ctx, id)
+}
 
-	if errAgg != nil {
-		log.Fatal(errAgg)
-		return models.ResponseUser{}, errAgg
+// UpdateUser changes the profile of a user, the email and password only change through ChangeCredentials
+func (s *Service) UpdateUser(ctx context.Context, actor Actor, id string, req types.UpdateUser) (models.User, error) {
+	if err := validation.Struct(req); err != nil {
+		return models.User{}, err
 	}
-
-	var allUsers []bson.M
-	if err = result.All(c.Request.Context(), &allUsers); err != nil {
-		log.Fatal(err)
-		return models.ResponseUser{}, err
+	updatedUser, err := s.findUserByID(ctx, id)
+	if err != nil {
+		return models.User{}, err
 	}
 
-	var users []models.User
-	for _, user := range allUsers {
-		var userModel models.User
-		bsonBytes, _ := bson.Marshal(user)
-		bson.Unmarshal(bsonBytes, &userModel)
-		users = append(users, userModel)
+	if req.FirstName != nil {
+		updatedUser.First_name = *req.FirstName
 	}
-
-	response := models.ResponseUser{
-		AllUsers:      users,
-		Page:          page,
-		RecordPerPage: recordPerPage,
-		StartIndex:    startIndex,
+	if req.LastName != nil {
+		updatedUser.Last_name = *req.LastName
 	}
-	return response, nil
-}
-
-func GetUser(c *gin.Context, id string) (models.User, error) {
-	userId, err := primitive.ObjectIDFromHex(id)
-
-	var user models.User
-
-	defer c.Request.Body.Close()
-
-	err = userCollection.FindOne(c.Request.Context(), bson.D{{Key: "user_id", Value: userId}}).Decode(&user)
+	updatedUser.UpdatedAt, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
 
+	err = s.repos.Users.Update(ctx, updatedUser)
 	if err != nil {
 		return models.User{}, err
 	}
-
-	return user, nil
+	return updatedUser, nil
 }
 
-func UpdateUser(c *gin.Context, id string, userReq models.User) (models.User, error) {
-	objectID, err := primitive.ObjectIDFromHex(id)
+// ChangeCredentials changes the email or password of the actor's own account once the current password is confirmed.
+// A new password logs out every other session of the user.
+func (s *Service) ChangeCredentials(ctx context.Context, actor Actor, id string, req types.ChangeCredentials) (models.User, error) {
+	if err := validation.Struct(req); err != nil {
+		return models.User{}, err
+	}
+	if actor.User_id == "" || actor.User_id != id {
+		return models.User{}, apperrors.Forbidden("you can only change the email and password of your own account")
+	}
+	user, err := s.findUserByID(ctx, id)
 	if err != nil {
 		return models.User{}, err
 	}
+	if ok, _ := ComparePassword(user.Password, req.CurrentPassword); !ok {
+		return models.User{}, apperrors.Unauthorized("current password is wrong")
+	}
 
-	defer c.Request.Body.Close()
-
-	var updatedUser models.User
-
-	err = userCollection.FindOne(c.Request.Context(), bson.M{"user_id": objectID}).Decode(&updatedUser)
+	// a new address is only used once it is verified, until then the account keeps the old one
+	var newEmail string
+	if req.Email != nil && *req.Email != user.Email {
+		exists, err := s.repos.Users.ExistsByEmail(ctx, *req.Email)
+		if err != nil {
+			return models.User{}, err
+		}
+		if exists {
+			return models.User{}, apperrors.Conflict("email already exists")
+		}
+		newEmail = *req.Email
+	}
+	if req.NewPassword != nil {
+		user.Password = HashPassword(*req.NewPassword)
+	}
+	user.UpdatedAt, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
 
+	err = s.repos.Users.Update(ctx, user)
+	if err == repositories.ErrDuplicate {
+		return models.User{}, apperrors.Conflict("email already exists")
+	}
 	if err != nil {
 		return models.User{}, err
 	}
 
-	updatedUser.Password = userReq.Password
-	updatedUser.First_name = userReq.First_name
-	updatedUser.Last_name = userReq.Last_name
-	updatedUser.Email = userReq.Email
-	updatedUser.ID = objectID
-	updatedUser.UpdatedAt, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+	if req.NewPassword != nil {
+		if err := s.revokeOtherSessions(ctx, actor); err != nil {
+			return models.User{}, err
+		}
+	}
+	if newEmail != "" {
+		if err := s.SendEmailChangeVerification(ctx, user, newEmail); err != nil {
+			return models.User{}, err
+		}
+	}
+	return user, nil
+}
 
-	_, err = userCollection.UpdateOne(c.Request.Context(), bson.M{"user_id": objectID}, bson.D{{Key: "$set", Value: updatedUser}})
+// revokeOtherSessions logs the actor out of every device but the one making the request
+func (s *Service) revokeOtherSessions(ctx context.Context, actor Actor) error {
+	families, err := s.repos.TokenFamilies.ListUnrevoked(ctx, actor.User_id)
 	if err != nil {
-		return models.User{}, err
+		return err
 	}
-	return updatedUser, nil
+	for _, family := range families {
+		if family.Family_id == actor.Family_id {
+			continue
+		}
+		if err := s.RevokeTokenFamily(ctx, family.Family_id); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
-func DeleteUser(c *gin.Context, id string) (models.User, error) {
-	objectID, err := primitive.ObjectIDFromHex(id)
+func (s *Service) DeleteUser(ctx context.Context, actor Actor, id string) (models.User, error) {
+	deletedUser, err := s.findUserByID(ctx, id)
 	if err != nil {
 		return models.User{}, err
 	}
 
-	defer c.Request.Body.Close()
-	userEmail, _ := c.Get("first_name")
-	var isAdmin = helpers.IsAdmin(userEmail.(string))
-
-	if !isAdmin {
-		return models.User{}, errors.New("unauthorized")
+	// the sessions are ended first, a deleted account must not keep refreshing its tokens
+	if _, err := s.RevokeUserTokenFamilies(ctx, actor, id); err != nil {
+		return models.User{}, err
 	}
-	var deletedUser models.User
-
-	_, err = userCollection.DeleteOne(c.Request.Context(), bson.M{"user_id": objectID})
+	err = s.repos.Users.Delete(ctx, id)
 	if err != nil {
 		return models.User{}, err
 	}
@@ -151,26 +164,39 @@ func DeleteUser(c *gin.Context, id string) (models.User, error) {
 	return deletedUser, nil
 }
 
-func ResetPassword(c *gin.Context, userReq types.PasswordReset) (models.User, error) {
-	var foundUser models.User
-	err := userCollection.FindOne(c.Request.Context(), bson.D{{Key: "email", Value: userReq.Email}}).Decode(&foundUser)
+func (s *Service) ResetPassword(ctx context.Context, actor Actor, req types.PasswordReset) (models.User, error) {
+	if err := validation.Struct(req); err != nil {
+		return models.User{}, err
+	}
+	foundUser, err := s.repos.Users.FindByEmail(ctx, req.Email)
 	if err != nil {
+		if err == repositories.ErrNotFound {
+			return models.User{}, apperrors.NotFound("user not found")
+		}
 		return models.User{}, err
 	}
-	passwordIsValid, msg := ComparePassword(foundUser.Password, userReq.OldPassword)
+	// the old password is not enough on its own, the account has to be the caller's
+	if foundUser.User_id != actor.User_id {
+		return models.User{}, apperrors.Forbidden("you can only change the password of your own account")
+	}
+	passwordIsValid, msg := ComparePassword(foundUser.Password, req.OldPassword)
 
 	if !passwordIsValid {
-		return models.User{}, errors.New(msg)
+		return models.User{}, apperrors.Unauthorized(msg)
 	}
 
-	new_password := HashPassword(userReq.NewPassword)
+	new_password := HashPassword(req.NewPassword)
 	foundUser.Password = new_password
 
 	foundUser.UpdatedAt, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
 
-	_, err = userCollection.UpdateOne(c.Request.Context(), bson.D{{Key: "email", Value: userReq.Email}}, bson.D{{Key: "$set", Value: foundUser}})
+	err = s.repos.Users.SetPassword(ctx, foundUser.User_id, new_password, foundUser.UpdatedAt)
 	if err != nil {
 		return models.User{}, err
 	}
+
+	if err := s.revokeOtherSessions(ctx, actor); err != nil {
+		return models.User{}, err
+	}
 	return foundUser, nil
 }
@@ -0,0 +1,174 @@
+// Package testmongo starts a throwaway mongod for integration tests. It runs the binary found on PATH so the tests work offline.
+package testmongo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ErrNoMongod is returned by Start when there is no mongod to run, callers usually skip their tests
+var ErrNoMongod = errors.New("mongod not found, put it on PATH or point MONGOD at it")
+
+// replicaSet is the name of the single node replica set, transactions need one
+const replicaSet = "rs0"
+
+// Server is a mongod listening on a random local port with its data in a temporary directory
+type Server struct {
+	// URI connects straight to the node, which is the primary of its replica set
+	URI string
+	cmd *exec.Cmd
+	dir string
+	// exited is closed once mongod stopped, for whatever reason
+	exited chan struct{}
+}
+
+// Start runs mongod from $MONGOD or PATH as a single node replica set and waits until it accepts transactions
+func Start(ctx context.Context) (*Server, error) {
+	binary := os.Getenv("MONGOD")
+	if binary == "" {
+		var err error
+		if binary, err = exec.LookPath("mongod"); err != nil {
+			return nil, ErrNoMongod
+		}
+	}
+
+	port, err := freePort()
+	if err != nil {
+		return nil, err
+	}
+	dir, err := os.MkdirTemp("", "testmongo-")
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := exec.Command(binary,
+		"--dbpath", dir,
+		"--logpath", filepath.Join(dir, "mongod.log"),
+		"--bind_ip", "127.0.0.1",
+		"--port", strconv.Itoa(port),
+		"--replSet", replicaSet,
+		"--oplogSize", "64",
+		"--wiredTigerCacheSizeGB", "0.25",
+	)
+	if err := cmd.Start(); err != nil {
+		os.RemoveAll(dir)
+		return nil, err
+	}
+	s := &Server{
+		URI:    fmt.Sprintf("mongodb://127.0.0.1:%d/?directConnection=true", port),
+		cmd:    cmd,
+		dir:    dir,
+		exited: make(chan struct{}),
+	}
+	go func() {
+		cmd.Wait()
+		close(s.exited)
+	}()
+
+	if err := s.initiate(ctx, port); err != nil {
+		s.Stop()
+		return nil, err
+	}
+	return s, nil
+}
+
+// initiate turns the node into the primary of its replica set
+func (s *Server) initiate(ctx context.Context, port int) error {
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(s.URI))
+	if err != nil {
+		return err
+	}
+	defer client.Disconnect(context.Background())
+	admin := client.Database("admin")
+
+	err = s.poll(ctx, func() (bool, error) {
+		pingCtx, cancel := context.WithTimeout(ctx, time.Second)
+		defer cancel()
+		return client.Ping(pingCtx, nil) == nil, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	config := bson.D{
+		{Key: "_id", Value: replicaSet},
+		{Key: "members", Value: bson.A{bson.D{{Key: "_id", Value: 0}, {Key: "host", Value: fmt.Sprintf("127.0.0.1:%d", port)}}}},
+	}
+	if err := admin.RunCommand(ctx, bson.D{{Key: "replSetInitiate", Value: config}}).Err(); err != nil {
+		return fmt.Errorf("initiating the replica set: %w", err)
+	}
+
+	return s.poll(ctx, func() (bool, error) {
+		var hello struct {
+			IsWritablePrimary bool `bson:"isWritablePrimary"`
+		}
+		if err := admin.RunCommand(ctx, bson.D{{Key: "hello", Value: 1}}).Decode(&hello); err != nil {
+			return false, nil
+		}
+		return hello.IsWritablePrimary, nil
+	})
+}
+
+// poll calls ready until it reports true, mongod exits or ctx is done
+func (s *Server) poll(ctx context.Context, ready func() (bool, error)) error {
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		ok, err := ready()
+		if err != nil || ok {
+			return err
+		}
+		select {
+		case <-s.exited:
+			return fmt.Errorf("mongod exited: %s", s.logTail())
+		case <-ctx.Done():
+			return fmt.Errorf("waiting for mongod: %w", ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// Stop shuts mongod down and removes its data
+func (s *Server) Stop() error {
+	s.cmd.Process.Signal(os.Interrupt)
+	select {
+	case <-s.exited:
+	case <-time.After(10 * time.Second):
+		s.cmd.Process.Kill()
+		<-s.exited
+	}
+	return os.RemoveAll(s.dir)
+}
+
+// logTail is the end of the mongod log, which tells why it did not start
+func (s *Server) logTail() string {
+	log, err := os.ReadFile(filepath.Join(s.dir, "mongod.log"))
+	if err != nil {
+		return err.Error()
+	}
+	if len(log) > 2048 {
+		log = log[len(log)-2048:]
+	}
+	return string(log)
+}
+
+// freePort asks the kernel for a port nothing listens on
+func freePort() (int, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, err
+	}
+	defer listener.Close()
+	return listener.Addr().(*net.TCPAddr).Port, nil
+}
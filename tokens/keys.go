@@ -0,0 +1,383 @@
+package tokens
+
+import (
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	SigningAlgRS256 = "RS256"
+	SigningAlgEdDSA = "EdDSA"
+)
+
+// rotationCheckInterval is how often the key directory is re-read and the active key checked for its age
+const rotationCheckInterval = time.Hour
+
+// keyIDTimeLayout is how generated key IDs start with the time the key was generated
+const keyIDTimeLayout = "20060102T150405Z"
+
+// SigningKey is a key tokens are signed or verified with. Keys only used for verification have no Private key.
+type SigningKey struct {
+	ID        string
+	Algorithm string
+	Private   crypto.Signer
+	Public    crypto.PublicKey
+	// CreatedAt is read from the ID of generated keys, so copying the key files does not change it.
+	// Keys named otherwise fall back to the modification time of their file.
+	CreatedAt time.Time
+}
+
+// JWK is the public part of a signing key as published in the JWKS document
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+var keyRing = struct {
+	sync.RWMutex
+	keys   map[string]*SigningKey
+	active *SigningKey
+}{keys: map[string]*SigningKey{}}
+
+// Settings are the options main reads from the configuration
+type Settings struct {
+	// KeyDir is where the PEM encoded keys live, one file per key named <kid>.pem
+	KeyDir string
+	// SigningAlg is the algorithm new keys are generated for
+	SigningAlg string
+	// KeyRotation is how long a key signs tokens before a new one replaces it, 0 turns rotation off
+	KeyRotation time.Duration
+	Issuer      string
+	Audience    string
+}
+
+var settings = Settings{
+	KeyDir:      "keys",
+	SigningAlg:  SigningAlgRS256,
+	KeyRotation: 30 * 24 * time.Hour,
+	Issuer:      "culinary-bliss",
+	Audience:    "culinary-bliss-api",
+}
+
+// Configure replaces the settings, call it before LoadSigningKeys
+func Configure(s Settings) {
+	settings = s
+}
+
+func signingKeyDir() string {
+	return settings.KeyDir
+}
+
+func signingAlgorithm() string {
+	if settings.SigningAlg == SigningAlgEdDSA {
+		return SigningAlgEdDSA
+	}
+	return SigningAlgRS256
+}
+
+// KeyRotationInterval is how long a key signs tokens before a new one replaces it
+func KeyRotationInterval() time.Duration {
+	return settings.KeyRotation
+}
+
+// LoadSigningKeys reads every key in the key directory and generates a key if there is none to sign with or the active one is due for rotation
+func LoadSigningKeys() error {
+	if err := reloadSigningKeys(); err != nil {
+		return err
+	}
+	return rotateIfDue()
+}
+
+// rotateIfDue generates a new key when there is none to sign with or the active one has signed for the whole rotation interval
+func rotateIfDue() error {
+	keyRing.RLock()
+	active := keyRing.active
+	keyRing.RUnlock()
+
+	interval := KeyRotationInterval()
+	if active != nil && (interval <= 0 || time.Since(active.CreatedAt) < interval) {
+		return nil
+	}
+	return RotateSigningKey()
+}
+
+// Ready reports whether there is a key to sign new tokens with
+func Ready() error {
+	keyRing.RLock()
+	defer keyRing.RUnlock()
+	if keyRing.active == nil {
+		return errors.New("no active signing key")
+	}
+	return nil
+}
+
+// RotateSigningKey generates a new key, writes it to the key directory and makes it the active signing key.
+// Older keys stay in the key set so tokens they signed can still be verified.
+func RotateSigningKey() error {
+	algorithm := signingAlgorithm()
+
+	var private crypto.Signer
+	var err error
+	if algorithm == SigningAlgEdDSA {
+		_, private, err = ed25519.GenerateKey(rand.Reader)
+	} else {
+		private, err = rsa.GenerateKey(rand.Reader, 2048)
+	}
+	if err != nil {
+		return err
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return err
+	}
+
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return err
+	}
+	kid := time.Now().UTC().Format(keyIDTimeLayout) + "-" + hex.EncodeToString(suffix)
+
+	dir := signingKeyDir()
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	path := filepath.Join(dir, kid+".pem")
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0600); err != nil {
+		return err
+	}
+
+	log.Println("Generated new token signing key", kid)
+	return reloadSigningKeys()
+}
+
+// StartKeyRotation picks up keys added to the key directory and rotates the active key once it is older than the rotation interval.
+// It returns when ctx is done.
+func StartKeyRotation(ctx context.Context) {
+	ticker := time.NewTicker(rotationCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := reloadSigningKeys(); err != nil {
+				log.Println("Error loading signing keys:", err)
+				continue
+			}
+			if err := rotateIfDue(); err != nil {
+				log.Println("Error rotating signing key:", err)
+			}
+		}
+	}
+}
+
+// GetJWKS returns the public keys tokens can currently be verified with
+func GetJWKS() JWKS {
+	keyRing.RLock()
+	defer keyRing.RUnlock()
+
+	jwks := JWKS{Keys: []JWK{}}
+	for _, key := range keyRing.keys {
+		jwk := JWK{Kid: key.ID, Use: "sig", Alg: key.Algorithm}
+		switch public := key.Public.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(public)
+		}
+		jwks.Keys = append(jwks.Keys, jwk)
+	}
+
+	sort.Slice(jwks.Keys, func(i, j int) bool { return jwks.Keys[i].Kid < jwks.Keys[j].Kid })
+	return jwks
+}
+
+// signToken signs the claims with the active key and names the key in the kid header
+func signToken(claims jwt.Claims) (string, error) {
+	keyRing.RLock()
+	active := keyRing.active
+	keyRing.RUnlock()
+
+	if active == nil {
+		return "", errors.New("no signing key loaded")
+	}
+
+	token := jwt.NewWithClaims(jwt.GetSigningMethod(active.Algorithm), claims)
+	token.Header["kid"] = active.ID
+	return token.SignedString(active.Private)
+}
+
+// verificationKey looks up the key named in the kid header and makes sure the token uses that key's algorithm
+func verificationKey(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	keyRing.RLock()
+	key, ok := keyRing.keys[kid]
+	keyRing.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	if token.Method.Alg() != key.Algorithm {
+		return nil, fmt.Errorf("unexpected signing method %q", token.Method.Alg())
+	}
+
+	return key.Public, nil
+}
+
+func reloadSigningKeys() error {
+	files, err := filepath.Glob(filepath.Join(signingKeyDir(), "*.pem"))
+	if err != nil {
+		return err
+	}
+
+	keys := map[string]*SigningKey{}
+	var signing []*SigningKey
+	for _, file := range files {
+		key, err := readSigningKey(file)
+		if err != nil {
+			log.Println("Skipping signing key", file+":", err)
+			continue
+		}
+
+		if key.Private != nil {
+			signing = append(signing, key)
+		}
+		keys[key.ID] = key
+	}
+	sort.Slice(signing, func(i, j int) bool { return newer(signing[j], signing[i]) })
+
+	var active *SigningKey
+	if len(signing) > 0 {
+		active = signing[len(signing)-1]
+	}
+
+	// a key stops signing when the next key is generated, a refresh token lifetime later nothing it signed is still valid
+	for kid, key := range keys {
+		if key == active {
+			continue
+		}
+		for _, next := range signing {
+			if newer(next, key) {
+				if time.Since(next.CreatedAt) > RefreshTokenTTL {
+					delete(keys, kid)
+				}
+				break
+			}
+		}
+	}
+
+	keyRing.Lock()
+	keyRing.keys = keys
+	keyRing.active = active
+	keyRing.Unlock()
+
+	return nil
+}
+
+// newer reports if a was generated after b, keys generated in the same second are ordered by ID
+func newer(a *SigningKey, b *SigningKey) bool {
+	if !a.CreatedAt.Equal(b.CreatedAt) {
+		return a.CreatedAt.After(b.CreatedAt)
+	}
+	return a.ID > b.ID
+}
+
+// readSigningKey parses a PKCS#8 private key or a PKIX public key. Public keys are only used to verify tokens.
+func readSigningKey(file string) (*SigningKey, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(file)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM data found")
+	}
+
+	key := &SigningKey{
+		ID:        strings.TrimSuffix(filepath.Base(file), ".pem"),
+		CreatedAt: info.ModTime(),
+	}
+	if generated, err := time.Parse(keyIDTimeLayout, strings.SplitN(key.ID, "-", 2)[0]); err == nil {
+		key.CreatedAt = generated
+	}
+
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		signer, ok := parsed.(crypto.Signer)
+		if !ok {
+			return nil, fmt.Errorf("unsupported private key type %T", parsed)
+		}
+		key.Private = signer
+		key.Public = signer.Public()
+	case "RSA PRIVATE KEY":
+		parsed, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		key.Private = parsed
+		key.Public = parsed.Public()
+	case "PUBLIC KEY":
+		key.Public, err = x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+
+	switch key.Public.(type) {
+	case *rsa.PublicKey:
+		key.Algorithm = SigningAlgRS256
+	case ed25519.PublicKey:
+		key.Algorithm = SigningAlgEdDSA
+	default:
+		return nil, fmt.Errorf("unsupported key type %T", key.Public)
+	}
+
+	return key, nil
+}
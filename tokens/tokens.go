@@ -0,0 +1,120 @@
+// Package tokens signs and validates the JWTs issued to users
+package tokens
+
+import (
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Claims is the payload of every token
+type Claims struct {
+	Email       string
+	First_name  string
+	Last_name   string
+	User_id     string
+	Role        string
+	Permissions []string
+	Token_type  string
+	Family_id   string
+	jwt.RegisteredClaims
+}
+
+const (
+	AccessToken  = "access"
+	RefreshToken = "refresh"
+	MfaToken     = "mfa"
+)
+
+// MfaTokenTTL is how long a user has to enter their second factor after giving the right password
+const MfaTokenTTL = time.Minute * time.Duration(5)
+
+// AccessTokenTTL is how long an access token stays valid after it is issued
+const AccessTokenTTL = time.Hour * time.Duration(24)
+
+// RefreshTokenTTL is how long a refresh token stays valid after it is issued
+const RefreshTokenTTL = time.Hour * time.Duration(24*7)
+
+// ClockSkew is how far the clocks of the issuer and a verifier may drift apart before time based claims fail
+const ClockSkew = 30 * time.Second
+
+// Errors returned by Parse. Everything but ErrWrongType means the token cannot be trusted to identify anyone.
+var (
+	ErrMalformed        = errors.New("malformed token")
+	ErrUnverifiable     = errors.New("token is not signed by a known key")
+	ErrSignatureInvalid = errors.New("token signature is invalid")
+	ErrExpired          = errors.New("token has expired")
+	ErrNotYetValid      = errors.New("token is not valid yet")
+	ErrClaimsInvalid    = errors.New("token claims are invalid")
+	ErrWrongType        = errors.New("token cannot be used for this request")
+)
+
+// Issuer is the iss claim of issued tokens and the only one accepted
+func Issuer() string {
+	return settings.Issuer
+}
+
+// Audience is the aud claim of issued tokens and the one a token has to name to be accepted
+func Audience() string {
+	return settings.Audience
+}
+
+// Sign fills in the registered claims for a token of the given type and signs it with the active key
+func Sign(claims *Claims, tokenType string, ttl time.Duration) (string, error) {
+	now := time.Now()
+	claims.Token_type = tokenType
+	claims.RegisteredClaims = jwt.RegisteredClaims{
+		ID:        primitive.NewObjectID().Hex(),
+		Subject:   claims.User_id,
+		Issuer:    Issuer(),
+		Audience:  jwt.ClaimStrings{Audience()},
+		IssuedAt:  jwt.NewNumericDate(now),
+		NotBefore: jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+	}
+
+	return signToken(claims)
+}
+
+// Parse verifies the signature and registered claims of a token and that it is of the expected type
+func Parse(signedToken string, tokenType string) (*Claims, error) {
+	parser := jwt.NewParser(
+		jwt.WithValidMethods([]string{SigningAlgRS256, SigningAlgEdDSA}),
+		jwt.WithIssuer(Issuer()),
+		jwt.WithAudience(Audience()),
+		jwt.WithLeeway(ClockSkew),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+	)
+
+	claims := &Claims{}
+	_, err := parser.ParseWithClaims(signedToken, claims, verificationKey)
+	if err != nil {
+		return nil, parseError(err)
+	}
+
+	if claims.Token_type != tokenType {
+		return nil, ErrWrongType
+	}
+
+	return claims, nil
+}
+
+func parseError(err error) error {
+	switch {
+	case errors.Is(err, jwt.ErrTokenMalformed):
+		return ErrMalformed
+	case errors.Is(err, jwt.ErrTokenSignatureInvalid):
+		return ErrSignatureInvalid
+	case errors.Is(err, jwt.ErrTokenUnverifiable):
+		return ErrUnverifiable
+	case errors.Is(err, jwt.ErrTokenExpired):
+		return ErrExpired
+	case errors.Is(err, jwt.ErrTokenNotValidYet), errors.Is(err, jwt.ErrTokenUsedBeforeIssued):
+		return ErrNotYetValid
+	default:
+		return ErrClaimsInvalid
+	}
+}
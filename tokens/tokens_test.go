@@ -0,0 +1,461 @@
+package tokens
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// setupKeys loads a fresh key directory with an active key of each algorithm and returns them
+func setupKeys(t *testing.T) (rsaKey *SigningKey, edKey *SigningKey) {
+	t.Helper()
+	previous := settings
+	t.Cleanup(func() { settings = previous })
+	settings.KeyDir = t.TempDir()
+
+	settings.SigningAlg = SigningAlgEdDSA
+	if err := RotateSigningKey(); err != nil {
+		t.Fatal(err)
+	}
+	edKey = keyRing.active
+
+	// make sure the RSA key is the newer one and becomes the active key
+	backdateKey(t, edKey)
+	settings.SigningAlg = SigningAlgRS256
+	if err := RotateSigningKey(); err != nil {
+		t.Fatal(err)
+	}
+	rsaKey = keyRing.active
+
+	if rsaKey.Algorithm != SigningAlgRS256 || edKey.Algorithm != SigningAlgEdDSA {
+		t.Fatalf("unexpected key setup: %s %s", rsaKey.Algorithm, edKey.Algorithm)
+	}
+	return rsaKey, edKey
+}
+
+// backdateKey renames the key file as if the key had been generated an hour earlier, so a key generated after it is the newer one
+func backdateKey(t *testing.T, key *SigningKey) {
+	t.Helper()
+	renameKey(t, key, time.Now().Add(-time.Hour))
+}
+
+// renameKey gives the key file the ID of a key generated at the given time and updates key to match
+func renameKey(t *testing.T, key *SigningKey, generated time.Time) {
+	t.Helper()
+	generated = generated.UTC().Truncate(time.Second)
+	kid := generated.Format(keyIDTimeLayout) + "-" + strings.SplitN(key.ID, "-", 2)[1]
+	if err := os.Rename(filepath.Join(settings.KeyDir, key.ID+".pem"), filepath.Join(settings.KeyDir, kid+".pem")); err != nil {
+		t.Fatal(err)
+	}
+	key.ID = kid
+	key.CreatedAt = generated
+}
+
+func validClaims(tokenType string) *Claims {
+	now := time.Now()
+	return &Claims{
+		Email:      "jane@example.com",
+		User_id:    "user-1",
+		Role:       "Customer",
+		Token_type: tokenType,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        "jti-1",
+			Issuer:    Issuer(),
+			Audience:  jwt.ClaimStrings{Audience()},
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour)),
+		},
+	}
+}
+
+func signWith(t *testing.T, method jwt.SigningMethod, key interface{}, kid string, claims jwt.Claims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+// replacePayload swaps the claims of a signed token while keeping its header and signature
+func replacePayload(t *testing.T, signed string, claims *Claims) string {
+	t.Helper()
+	parts := strings.Split(signed, ".")
+	forged := signWith(t, jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType, "", claims)
+	return parts[0] + "." + strings.Split(forged, ".")[1] + "." + parts[2]
+}
+
+func TestSignAndParse(t *testing.T) {
+	setupKeys(t)
+
+	for _, tokenType := range []string{AccessToken, RefreshToken, MfaToken} {
+		signed, err := Sign(&Claims{Email: "jane@example.com", User_id: "user-1"}, tokenType, time.Hour)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		claims, err := Parse(signed, tokenType)
+		if err != nil {
+			t.Fatalf("%s: %v", tokenType, err)
+		}
+		if claims.User_id != "user-1" || claims.Subject != "user-1" || claims.Token_type != tokenType || claims.ID == "" {
+			t.Fatalf("%s: unexpected claims %+v", tokenType, claims)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	rsaKey, edKey := setupKeys(t)
+
+	otherRSA, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, otherEd, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	valid := signWith(t, jwt.SigningMethodRS256, rsaKey.Private, rsaKey.ID, validClaims(AccessToken))
+
+	tests := []struct {
+		name      string
+		token     func() string
+		tokenType string
+		err       error
+	}{
+		{
+			name:  "valid RS256",
+			token: func() string { return valid },
+		},
+		{
+			name: "valid EdDSA",
+			token: func() string {
+				return signWith(t, jwt.SigningMethodEdDSA, edKey.Private, edKey.ID, validClaims(AccessToken))
+			},
+		},
+		{
+			name:  "empty",
+			token: func() string { return "" },
+			err:   ErrMalformed,
+		},
+		{
+			name:  "not a jwt",
+			token: func() string { return "not-a-token" },
+			err:   ErrMalformed,
+		},
+		{
+			name:  "bad base64",
+			token: func() string { return "%%%.%%%.%%%" },
+			err:   ErrMalformed,
+		},
+		{
+			name:  "header is not json",
+			token: func() string { return base64.RawURLEncoding.EncodeToString([]byte("nope")) + ".e30.sig" },
+			err:   ErrMalformed,
+		},
+		{
+			name: "expired",
+			token: func() string {
+				claims := validClaims(AccessToken)
+				claims.IssuedAt = jwt.NewNumericDate(time.Now().Add(-2 * time.Hour))
+				claims.NotBefore = claims.IssuedAt
+				claims.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Minute))
+				return signWith(t, jwt.SigningMethodRS256, rsaKey.Private, rsaKey.ID, claims)
+			},
+			err: ErrExpired,
+		},
+		{
+			name: "expired within clock skew",
+			token: func() string {
+				claims := validClaims(AccessToken)
+				claims.IssuedAt = jwt.NewNumericDate(time.Now().Add(-time.Hour))
+				claims.NotBefore = claims.IssuedAt
+				claims.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-ClockSkew / 2))
+				return signWith(t, jwt.SigningMethodRS256, rsaKey.Private, rsaKey.ID, claims)
+			},
+		},
+		{
+			name: "missing expiry",
+			token: func() string {
+				claims := validClaims(AccessToken)
+				claims.ExpiresAt = nil
+				return signWith(t, jwt.SigningMethodRS256, rsaKey.Private, rsaKey.ID, claims)
+			},
+			err: ErrClaimsInvalid,
+		},
+		{
+			name: "not valid yet",
+			token: func() string {
+				claims := validClaims(AccessToken)
+				claims.NotBefore = jwt.NewNumericDate(time.Now().Add(time.Minute))
+				return signWith(t, jwt.SigningMethodRS256, rsaKey.Private, rsaKey.ID, claims)
+			},
+			err: ErrNotYetValid,
+		},
+		{
+			name: "issued in the future",
+			token: func() string {
+				claims := validClaims(AccessToken)
+				claims.IssuedAt = jwt.NewNumericDate(time.Now().Add(time.Minute))
+				return signWith(t, jwt.SigningMethodRS256, rsaKey.Private, rsaKey.ID, claims)
+			},
+			err: ErrNotYetValid,
+		},
+		{
+			name: "not valid yet within clock skew",
+			token: func() string {
+				claims := validClaims(AccessToken)
+				claims.IssuedAt = jwt.NewNumericDate(time.Now().Add(ClockSkew / 2))
+				claims.NotBefore = claims.IssuedAt
+				return signWith(t, jwt.SigningMethodRS256, rsaKey.Private, rsaKey.ID, claims)
+			},
+		},
+		{
+			name: "wrong issuer",
+			token: func() string {
+				claims := validClaims(AccessToken)
+				claims.Issuer = "someone-else"
+				return signWith(t, jwt.SigningMethodRS256, rsaKey.Private, rsaKey.ID, claims)
+			},
+			err: ErrClaimsInvalid,
+		},
+		{
+			name: "wrong audience",
+			token: func() string {
+				claims := validClaims(AccessToken)
+				claims.Audience = jwt.ClaimStrings{"reporting"}
+				return signWith(t, jwt.SigningMethodRS256, rsaKey.Private, rsaKey.ID, claims)
+			},
+			err: ErrClaimsInvalid,
+		},
+		{
+			name: "alg none",
+			token: func() string {
+				return signWith(t, jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType, rsaKey.ID, validClaims(AccessToken))
+			},
+			err: ErrSignatureInvalid,
+		},
+		{
+			name: "HS256 signed with the public key",
+			token: func() string {
+				public := rsaKey.Public.(*rsa.PublicKey)
+				return signWith(t, jwt.SigningMethodHS256, public.N.Bytes(), rsaKey.ID, validClaims(AccessToken))
+			},
+			err: ErrSignatureInvalid,
+		},
+		{
+			name: "PS256 with an RS256 key",
+			token: func() string {
+				return signWith(t, jwt.SigningMethodPS256, rsaKey.Private, rsaKey.ID, validClaims(AccessToken))
+			},
+			err: ErrSignatureInvalid,
+		},
+		{
+			name: "RS256 claiming the EdDSA key",
+			token: func() string {
+				return signWith(t, jwt.SigningMethodRS256, rsaKey.Private, edKey.ID, validClaims(AccessToken))
+			},
+			err: ErrUnverifiable,
+		},
+		{
+			name: "EdDSA claiming the RS256 key",
+			token: func() string {
+				return signWith(t, jwt.SigningMethodEdDSA, edKey.Private, rsaKey.ID, validClaims(AccessToken))
+			},
+			err: ErrUnverifiable,
+		},
+		{
+			name: "unknown kid",
+			token: func() string {
+				return signWith(t, jwt.SigningMethodRS256, otherRSA, "unknown", validClaims(AccessToken))
+			},
+			err: ErrUnverifiable,
+		},
+		{
+			name: "missing kid",
+			token: func() string {
+				return signWith(t, jwt.SigningMethodRS256, rsaKey.Private, "", validClaims(AccessToken))
+			},
+			err: ErrUnverifiable,
+		},
+		{
+			name: "signed by another RSA key",
+			token: func() string {
+				return signWith(t, jwt.SigningMethodRS256, otherRSA, rsaKey.ID, validClaims(AccessToken))
+			},
+			err: ErrSignatureInvalid,
+		},
+		{
+			name: "signed by another Ed25519 key",
+			token: func() string {
+				return signWith(t, jwt.SigningMethodEdDSA, otherEd, edKey.ID, validClaims(AccessToken))
+			},
+			err: ErrSignatureInvalid,
+		},
+		{
+			name: "tampered payload",
+			token: func() string {
+				claims := validClaims(AccessToken)
+				claims.Role = "Admin"
+				claims.Permissions = []string{"roles:manage"}
+				return replacePayload(t, valid, claims)
+			},
+			err: ErrSignatureInvalid,
+		},
+		{
+			name: "tampered signature",
+			token: func() string {
+				parts := strings.Split(valid, ".")
+				signature, _ := base64.RawURLEncoding.DecodeString(parts[2])
+				signature[0] ^= 0xff
+				return parts[0] + "." + parts[1] + "." + base64.RawURLEncoding.EncodeToString(signature)
+			},
+			err: ErrSignatureInvalid,
+		},
+		{
+			name:  "stripped signature",
+			token: func() string { return valid[:strings.LastIndex(valid, ".")+1] },
+			err:   ErrSignatureInvalid,
+		},
+		{
+			name: "refresh token used as access token",
+			token: func() string {
+				return signWith(t, jwt.SigningMethodRS256, rsaKey.Private, rsaKey.ID, validClaims(RefreshToken))
+			},
+			err: ErrWrongType,
+		},
+		{
+			name: "mfa token used as access token",
+			token: func() string {
+				return signWith(t, jwt.SigningMethodRS256, rsaKey.Private, rsaKey.ID, validClaims(MfaToken))
+			},
+			err: ErrWrongType,
+		},
+		{
+			name: "access token used as refresh token",
+			token: func() string {
+				return valid
+			},
+			tokenType: RefreshToken,
+			err:       ErrWrongType,
+		},
+		{
+			name: "untyped token",
+			token: func() string {
+				return signWith(t, jwt.SigningMethodRS256, rsaKey.Private, rsaKey.ID, validClaims(""))
+			},
+			err: ErrWrongType,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tokenType := test.tokenType
+			if tokenType == "" {
+				tokenType = AccessToken
+			}
+
+			claims, err := Parse(test.token(), tokenType)
+			if !errors.Is(err, test.err) {
+				t.Fatalf("expected error %v, got %v", test.err, err)
+			}
+			if test.err == nil && claims.User_id != "user-1" {
+				t.Fatalf("unexpected claims %+v", claims)
+			}
+			if test.err != nil && claims != nil {
+				t.Fatalf("expected no claims, got %+v", claims)
+			}
+		})
+	}
+}
+
+func TestParseAfterRotation(t *testing.T) {
+	setupKeys(t)
+	backdateKey(t, keyRing.active)
+
+	signed, err := Sign(&Claims{User_id: "user-1"}, AccessToken, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := RotateSigningKey(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Parse(signed, AccessToken); err != nil {
+		t.Fatalf("token signed by the previous key: %v", err)
+	}
+
+	jwks := GetJWKS()
+	if len(jwks.Keys) != 3 {
+		t.Fatalf("expected 3 keys in the JWKS, got %d", len(jwks.Keys))
+	}
+}
+
+func TestKeysAreRetiredARefreshTokenLifetimeAfterTheNextKey(t *testing.T) {
+	rsaKey, edKey := setupKeys(t)
+	now := time.Now()
+
+	// the EdDSA key was replaced by the RSA key more than a refresh token lifetime ago, the RSA key by the active key just now
+	renameKey(t, edKey, now.Add(-3*RefreshTokenTTL))
+	renameKey(t, rsaKey, now.Add(-2*RefreshTokenTTL))
+	if err := RotateSigningKey(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := keyRing.keys[edKey.ID]; ok {
+		t.Fatal("expected the key replaced long ago to be retired")
+	}
+	if _, ok := keyRing.keys[rsaKey.ID]; !ok {
+		t.Fatal("expected the key replaced just now to be kept, tokens it signed are still valid")
+	}
+}
+
+func TestKeyCreationTimeDoesNotFollowTheFile(t *testing.T) {
+	rsaKey, edKey := setupKeys(t)
+
+	// copying the keys without keeping their times makes the older key the newer file
+	later := time.Now().Add(time.Hour)
+	if err := os.Chtimes(filepath.Join(settings.KeyDir, edKey.ID+".pem"), later, later); err != nil {
+		t.Fatal(err)
+	}
+	if err := reloadSigningKeys(); err != nil {
+		t.Fatal(err)
+	}
+
+	if keyRing.active.ID != rsaKey.ID {
+		t.Fatalf("expected %s to stay the active key, got %s", rsaKey.ID, keyRing.active.ID)
+	}
+}
+
+func TestLoadSigningKeysRotatesAKeyThatIsDue(t *testing.T) {
+	rsaKey, _ := setupKeys(t)
+	settings.KeyRotation = 24 * time.Hour
+	renameKey(t, rsaKey, time.Now().Add(-48*time.Hour))
+
+	if err := LoadSigningKeys(); err != nil {
+		t.Fatal(err)
+	}
+
+	if keyRing.active.ID == rsaKey.ID {
+		t.Fatal("expected a key older than the rotation interval to be replaced at startup")
+	}
+	if _, ok := keyRing.keys[rsaKey.ID]; !ok {
+		t.Fatal("expected the replaced key to be kept for verification")
+	}
+}
@@ -0,0 +1,7 @@
+package types
+
+type ApiKey struct {
+	Name          string   `json:"name" validate:"required,name"`
+	Restaurant_id string   `json:"restaurant_id" validate:"required"`
+	Permissions   []string `json:"permissions" validate:"required,min=1,dive,required"`
+}
@@ -2,6 +2,12 @@ package types
 
 // Category struct
 type Category struct {
-	Title string
-	Image string
+	Title string `json:"title" validate:"required,name"`
+	Image string `json:"image"`
+}
+
+// CategoryUpdate changes the fields that are sent and leaves the others as they are
+type CategoryUpdate struct {
+	Title *string `json:"title" validate:"omitnil,name"`
+	Image *string `json:"image"`
 }
@@ -0,0 +1,28 @@
+package types
+
+import "github.com/ShahSau/culinary-bliss/money"
+
+type Food struct {
+	Name        string      `json:"name" validate:"required,name"`
+	Description string      `json:"description" validate:"required"`
+	Price       money.Money `json:"price" validate:"required,price"`
+	Image       string      `json:"image" validate:"required"`
+	Menu_id     string      `json:"menu_id" validate:"required"`
+	Modifiers   []Modifier  `json:"modifiers" validate:"unique=Name,dive"`
+}
+
+// FoodUpdate changes the fields that are sent and leaves the others as they are
+type FoodUpdate struct {
+	Name        *string      `json:"name" validate:"omitnil,name"`
+	Description *string      `json:"description" validate:"omitnil,min=1"`
+	Price       *money.Money `json:"price" validate:"omitnil,price"`
+	Image       *string      `json:"image" validate:"omitnil,min=1"`
+	Menu_id     *string      `json:"menu_id" validate:"omitnil,min=1"`
+	Modifiers   *[]Modifier  `json:"modifiers" validate:"omitnil,unique=Name,dive"`
+}
+
+// Modifier is an extra a food can be ordered with, it may be free
+type Modifier struct {
+	Name  string      `json:"name" validate:"required,name"`
+	Price money.Money `json:"price" validate:"min=0"`
+}
@@ -1,6 +1,12 @@
 package types
 
 type Invoice struct {
-	Order_id       string
-	Payment_method string
+	Order_id       string `json:"order_id" validate:"required"`
+	Payment_method string `json:"payment_method" validate:"required,payment_method"`
+}
+
+// InvoiceUpdate changes the fields that are sent and leaves the others as they are
+type InvoiceUpdate struct {
+	Order_id       *string `json:"order_id" validate:"omitnil,min=1"`
+	Payment_method *string `json:"payment_method" validate:"omitnil,payment_method"`
 }
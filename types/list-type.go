@@ -0,0 +1,8 @@
+package types
+
+import "net/url"
+
+// ListRequest asks for one page of a list. Its query holds limit, cursor, sort and filters such as price[gte]=5.
+type ListRequest struct {
+	Query url.Values
+}
@@ -1,6 +1,18 @@
 package types
 
+import "time"
+
 type Menu struct {
-	Name        string
-	Description string
+	Name        string    `json:"name" validate:"required,name"`
+	Description string    `json:"description" validate:"required"`
+	Start_date  time.Time `json:"start_date" validate:"required"`
+	End_date    time.Time `json:"end_date" validate:"required,gtfield=Start_date"`
+}
+
+// MenuUpdate changes the fields that are sent and leaves the others as they are
+type MenuUpdate struct {
+	Name        *string    `json:"name" validate:"omitnil,name"`
+	Description *string    `json:"description" validate:"omitnil,min=1"`
+	Start_date  *time.Time `json:"start_date" validate:"omitnil"`
+	End_date    *time.Time `json:"end_date" validate:"omitnil"`
 }
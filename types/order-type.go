@@ -0,0 +1,68 @@
+package types
+
+import (
+	"github.com/ShahSau/culinary-bliss/models"
+	"github.com/ShahSau/culinary-bliss/money"
+)
+
+// Order opens an order, it starts out PLACED and only moves through the transition endpoints
+type Order struct {
+	Table_id string `json:"table_id" validate:"required"`
+	// Total_amount is optional, if it is sent it must match the total of the order's items, which is none yet
+	Total_amount *money.Money `json:"total_amount" validate:"omitnil,min=0"`
+}
+
+// OrderUpdate changes the fields that are sent and leaves the others as they are
+type OrderUpdate struct {
+	Table_id *string `json:"table_id" validate:"omitnil,min=1"`
+	// Total_amount is optional, if it is sent it must match the total of the order's items
+	Total_amount *money.Money `json:"total_amount" validate:"omitnil,min=0"`
+}
+
+// OrderLine is a food to order, it is priced from the food so the total is only a check
+type OrderLine struct {
+	Food_id   string   `json:"food_id" validate:"required"`
+	Quantity  int      `json:"quantity" validate:"required,quantity"`
+	Portion   string   `json:"portion" validate:"omitempty,portion"`
+	Modifiers []string `json:"modifiers" validate:"dive,required"`
+	// Notes are for the kitchen, e.g. no onions
+	Notes string `json:"notes" validate:"max=200"`
+	// Total_amount is optional, if it is sent it must match the price worked out on the server
+	Total_amount *money.Money `json:"total_amount" validate:"omitnil,price"`
+}
+
+type OrderItem struct {
+	Food_id   string   `json:"food_id" validate:"required"`
+	Order_id  string   `json:"order_id" validate:"required"`
+	Quantity  int      `json:"quantity" validate:"required,quantity"`
+	Portion   string   `json:"portion" validate:"omitempty,portion"`
+	Modifiers []string `json:"modifiers" validate:"dive,required"`
+	Notes     string   `json:"notes" validate:"max=200"`
+	// Total_amount is optional, if it is sent it must match the price worked out on the server
+	Total_amount *money.Money `json:"total_amount" validate:"omitnil,price"`
+}
+
+// OrderItemUpdate changes the fields that are sent and leaves the others as they are
+type OrderItemUpdate struct {
+	Order_id  *string   `json:"order_id" validate:"omitnil,min=1"`
+	Quantity  *int      `json:"quantity" validate:"omitnil,quantity"`
+	Portion   *string   `json:"portion" validate:"omitnil,portion"`
+	Modifiers *[]string `json:"modifiers" validate:"omitnil,dive,required"`
+	Notes     *string   `json:"notes" validate:"omitnil,max=200"`
+	// Total_amount is optional, if it is sent it must match the price worked out on the server
+	Total_amount *money.Money `json:"total_amount" validate:"omitnil,price"`
+}
+
+// OrderItemPack is an order placed together with its items, every item is priced from its food
+type OrderItemPack struct {
+	Table_id    string      `json:"table_id" validate:"required"`
+	Order_items []OrderLine `json:"order_items" validate:"required,min=1,dive"`
+	// Total_amount is optional, if it is sent it must match the total of the items
+	Total_amount *money.Money `json:"total_amount" validate:"omitnil,price"`
+}
+
+// PlacedOrder is an order with the items it was placed with
+type PlacedOrder struct {
+	Order       models.Order       `json:"order"`
+	Order_items []models.OrderItem `json:"order_items"`
+}
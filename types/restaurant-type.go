@@ -1,16 +1,28 @@
 package types
 
 type Restaurant struct {
-	Title       string
-	Image       string
-	Time        string
-	Pickup      bool
-	Delivery    bool
-	Rating      float64
-	RatingCount int
-	Menu        []string
+	Title       string   `json:"title" validate:"required,name"`
+	Image       string   `json:"image" validate:"required"`
+	Time        string   `json:"time" validate:"required"`
+	Pickup      bool     `json:"pickup"`
+	Delivery    bool     `json:"delivery"`
+	Rating      float64  `json:"rating" validate:"rating"`
+	RatingCount int      `json:"ratingCount" validate:"min=0"`
+	Menu        []string `json:"menu" validate:"dive,required"`
+}
+
+// RestaurantUpdate changes the fields that are sent and leaves the others as they are
+type RestaurantUpdate struct {
+	Title       *string   `json:"title" validate:"omitnil,name"`
+	Image       *string   `json:"image" validate:"omitnil,min=1"`
+	Time        *string   `json:"time" validate:"omitnil,min=1"`
+	Pickup      *bool     `json:"pickup"`
+	Delivery    *bool     `json:"delivery"`
+	Rating      *float64  `json:"rating" validate:"omitnil,rating"`
+	RatingCount *int      `json:"ratingCount" validate:"omitnil,min=0"`
+	Menu        *[]string `json:"menu" validate:"omitnil,dive,required"`
 }
 
 type Rating struct {
-	Rating float64
+	Rating float64 `json:"rating" validate:"required,rating"`
 }
@@ -0,0 +1,10 @@
+package types
+
+type RolePermissions struct {
+	Permissions []string `json:"permissions" validate:"required,dive,required"`
+	MfaRequired *bool    `json:"mfa_required"`
+}
+
+type UserRole struct {
+	Role string `json:"role" validate:"required"`
+}
@@ -1,7 +1,17 @@
 package types
 
 type Table struct {
-	Number_of_guests int
-	Table_number     int
-	Table_status     string
+	Number_of_guests int    `json:"number_of_guests" validate:"required,guests"`
+	Table_number     int    `json:"table_number" validate:"required,min=1"`
+	Table_status     string `json:"table_status" validate:"required"`
+	// Restaurant_id is optional, only the API keys of the restaurant can use a table that has one
+	Restaurant_id string `json:"restaurant_id"`
+}
+
+// TableUpdate changes the fields that are sent and leaves the others as they are
+type TableUpdate struct {
+	Number_of_guests *int    `json:"number_of_guests" validate:"omitnil,guests"`
+	Table_number     *int    `json:"table_number" validate:"omitnil,min=1"`
+	Table_status     *string `json:"table_status" validate:"omitnil,min=1"`
+	Restaurant_id    *string `json:"restaurant_id"`
 }
@@ -1,20 +1,104 @@
 package types
 
+import (
+	"time"
+
+	"github.com/ShahSau/culinary-bliss/models"
+)
+
 type Loginuser struct {
-	Email    string `json:"email" binding:"required"`
-	Password string `json:"password" binding:"required"`
+	Email    string `json:"email" validate:"required"`
+	Password string `json:"password" validate:"required"`
 }
 
 type RegisterUser struct {
-	FirstName string `json:"first_name" binding:"required"`
-	LastName  string `json:"last_name" binding:"required"`
-	Email     string `json:"email" binding:"required"`
-	Password  string `json:"password" binding:"required"`
-	Phone     string `json:"phone" binding:"required"`
+	FirstName string `json:"first_name" validate:"required,name"`
+	LastName  string `json:"last_name" validate:"required,name"`
+	Email     string `json:"email" validate:"required,email"`
+	Password  string `json:"password" validate:"required"`
+	Phone     string `json:"phone" validate:"required,phone"`
 }
 
 type PasswordReset struct {
-	Email       string `json:"email" binding:"required"`
-	OldPassword string `json:"old_password" binding:"required"`
-	NewPassword string `json:"new_password" binding:"required"`
+	Email       string `json:"email" validate:"required,email"`
+	OldPassword string `json:"old_password" validate:"required"`
+	NewPassword string `json:"new_password" validate:"required"`
+}
+
+type RefreshToken struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+type ForgotPassword struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type TokenPasswordReset struct {
+	Token       string `json:"token" validate:"required"`
+	NewPassword string `json:"new_password" validate:"required"`
+}
+
+type VerifyEmail struct {
+	Token string `json:"token" validate:"required"`
+}
+
+type MfaLogin struct {
+	MfaToken     string `json:"mfa_token" validate:"required"`
+	Code         string `json:"code"`
+	RecoveryCode string `json:"recovery_code"`
+}
+
+type MfaToken struct {
+	MfaToken string `json:"mfa_token" validate:"required"`
+}
+
+type MfaCode struct {
+	Code string `json:"code" validate:"required"`
+}
+
+// UpdateUser changes the fields that are sent and leaves the others as they are
+type UpdateUser struct {
+	FirstName *string `json:"first_name" validate:"omitnil,name"`
+	LastName  *string `json:"last_name" validate:"omitnil,name"`
+}
+
+// ChangeCredentials changes the email or password of the user's own account, proven with the current password
+type ChangeCredentials struct {
+	CurrentPassword string  `json:"current_password" validate:"required"`
+	Email           *string `json:"email" validate:"omitnil,email"`
+	NewPassword     *string `json:"new_password" validate:"omitnil,min=1"`
+}
+
+// User is a user the way the API shows it, never with the password or tokens
+type User struct {
+	User_id     string     `json:"user_id"`
+	First_name  string     `json:"first_name"`
+	Last_name   string     `json:"last_name"`
+	Email       string     `json:"email"`
+	Avatar      string     `json:"avatar"`
+	Phone       string     `json:"phone"`
+	Role        string     `json:"role"`
+	Status      string     `json:"status"`
+	Mfa_enabled bool       `json:"mfa_enabled"`
+	VerifiedAt  *time.Time `json:"verified_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at,omitempty"`
+	UpdatedAt   time.Time  `json:"updated_at,omitempty"`
+}
+
+// NewUser is the user as the API shows it
+func NewUser(user models.User) User {
+	return User{
+		User_id:     user.User_id,
+		First_name:  user.First_name,
+		Last_name:   user.Last_name,
+		Email:       user.Email,
+		Avatar:      user.Avatar,
+		Phone:       user.Phone,
+		Role:        user.Role,
+		Status:      user.Status,
+		Mfa_enabled: user.Mfa_enabled,
+		VerifiedAt:  user.VerifiedAt,
+		CreatedAt:   user.CreatedAt,
+		UpdatedAt:   user.UpdatedAt,
+	}
 }
@@ -0,0 +1,134 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/ShahSau/culinary-bliss/apperrors"
+	"github.com/ShahSau/culinary-bliss/money"
+	"github.com/go-playground/validator/v10"
+)
+
+// aliases name the rules shared by the create and update requests of a resource, use them in validate tags
+var aliases = map[string]string{
+	"price":          "gt=0",
+	"portion":        "oneof=S M L",
+	"quantity":       "min=1,max=99",
+	"payment_method": "oneof=CARD CASH",
+	"guests":         "min=1,max=20",
+	"rating":         "min=0,max=5",
+	"name":           "min=1,max=100",
+}
+
+// phonePattern accepts international numbers with an optional leading + and single spaces or dashes between digit groups
+var phonePattern = regexp.MustCompile(`^\+?[0-9]{1,4}([ -]?[0-9]{2,4}){2,5}$`)
+
+var validate = newValidator()
+
+func newValidator() *validator.Validate {
+	v := validator.New(validator.WithRequiredStructEnabled())
+	// report fields by the name clients send them with
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+	// amounts are checked by their minor units, so price and min=0 work on them as on numbers
+	v.RegisterCustomTypeFunc(func(field reflect.Value) interface{} {
+		return field.Interface().(money.Money).Amount
+	}, money.Money{})
+	for alias, tags := range aliases {
+		v.RegisterAlias(alias, tags)
+	}
+	if err := v.RegisterValidation("phone", func(fl validator.FieldLevel) bool {
+		return phonePattern.MatchString(fl.Field().String())
+	}); err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// Struct checks req against its validate tags. The error lists every invalid field.
+func Struct(req interface{}) error {
+	err := validate.Struct(req)
+	if err == nil {
+		return nil
+	}
+
+	var invalid validator.ValidationErrors
+	if !errors.As(err, &invalid) {
+		return err
+	}
+	fields := make([]apperrors.FieldError, 0, len(invalid))
+	for _, fe := range invalid {
+		fields = append(fields, apperrors.FieldError{Field: fieldPath(fe), Message: message(fe)})
+	}
+	return apperrors.Invalid(fields...)
+}
+
+// fieldPath is the path to the field without the name of the request type, e.g. permissions[1]
+func fieldPath(fe validator.FieldError) string {
+	namespace := fe.Namespace()
+	if i := strings.Index(namespace, "."); i >= 0 {
+		return namespace[i+1:]
+	}
+	return namespace
+}
+
+func message(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "email":
+		return "must be a valid email address"
+	case "phone":
+		return "must be a valid phone number"
+	case "price":
+		return "must be greater than 0"
+	case "portion":
+		return "must be one of S, M, L"
+	case "payment_method":
+		return "must be one of CARD, CASH"
+	case "guests":
+		return "must be between 1 and 20"
+	case "quantity":
+		return "must be between 1 and 99"
+	case "rating":
+		return "must be between 0 and 5"
+	case "name":
+		return "must be between 1 and 100 characters"
+	case "oneof":
+		return "must be one of " + strings.Join(strings.Fields(fe.Param()), ", ")
+	case "unique":
+		return "must not contain duplicates"
+	case "gtfield":
+		return "must be after " + strings.ToLower(fe.Param())
+	case "gt":
+		return "must be greater than " + fe.Param()
+	case "gte":
+		return "must be at least " + fe.Param()
+	case "min":
+		if fe.Kind() == reflect.String {
+			return fmt.Sprintf("must have at least %s characters", fe.Param())
+		}
+		if fe.Kind() == reflect.Slice {
+			return fmt.Sprintf("must have at least %s entries", fe.Param())
+		}
+		return "must be at least " + fe.Param()
+	case "max":
+		if fe.Kind() == reflect.String {
+			return fmt.Sprintf("must have at most %s characters", fe.Param())
+		}
+		if fe.Kind() == reflect.Slice {
+			return fmt.Sprintf("must have at most %s entries", fe.Param())
+		}
+		return "must be at most " + fe.Param()
+	default:
+		return "is invalid"
+	}
+}
@@ -0,0 +1,91 @@
+package validation
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ShahSau/culinary-bliss/apperrors"
+	"github.com/ShahSau/culinary-bliss/money"
+	"github.com/ShahSau/culinary-bliss/types"
+)
+
+// fields returns the field errors of err, or fails the test if it is not a validation error
+func fields(t *testing.T, err error) map[string]string {
+	t.Helper()
+	var domain *apperrors.Error
+	if !errors.As(err, &domain) || !errors.Is(err, apperrors.ErrValidation) {
+		t.Fatalf("expected a validation error, got %v", err)
+	}
+	found := map[string]string{}
+	for _, field := range domain.Fields {
+		found[field.Field] = field.Message
+	}
+	return found
+}
+
+func TestStruct(t *testing.T) {
+	start := time.Now()
+	badPrice := money.MustParse("-1")
+	badPortion := "XL"
+	badQuantity := 0
+	empty := ""
+
+	tests := []struct {
+		name string
+		req  interface{}
+		want map[string]string
+	}{
+		{"valid food", types.Food{Name: "Soup", Description: "Hot", Price: money.MustParse("4.5"), Image: "soup.png", Menu_id: "m1"}, nil},
+		{"missing food fields", types.Food{Price: money.MustParse("-2")}, map[string]string{
+			"name": "is required", "description": "is required", "price": "must be greater than 0", "image": "is required", "menu_id": "is required",
+		}},
+		{"empty food update", types.FoodUpdate{}, nil},
+		{"food update shares the price rule", types.FoodUpdate{Price: &badPrice, Name: &empty}, map[string]string{
+			"price": "must be greater than 0", "name": "must be between 1 and 100 characters",
+		}},
+		{"menu ending before it starts", types.Menu{Name: "Lunch", Description: "Noon", Start_date: start, End_date: start.Add(-time.Hour)}, map[string]string{
+			"end_date": "must be after start_date",
+		}},
+		{"too many guests", types.Table{Number_of_guests: 40, Table_number: 1, Table_status: "FREE"}, map[string]string{
+			"number_of_guests": "must be between 1 and 20",
+		}},
+		{"unknown portion", types.OrderItemUpdate{Portion: &badPortion, Quantity: &badQuantity}, map[string]string{
+			"portion": "must be one of S, M, L", "quantity": "must be between 1 and 99",
+		}},
+		{"duplicate modifier", types.Food{Name: "Soup", Description: "Hot", Price: money.MustParse("4.5"), Image: "soup.png", Menu_id: "m1", Modifiers: []types.Modifier{
+			{Name: "Bread", Price: money.MustParse("1")}, {Name: "Bread", Price: money.MustParse("2")},
+		}}, map[string]string{
+			"modifiers": "must not contain duplicates",
+		}},
+		{"negative modifier", types.FoodUpdate{Modifiers: &[]types.Modifier{{Name: "Bread", Price: money.MustParse("0")}, {Name: "Cream", Price: money.MustParse("-1")}}}, map[string]string{
+			"modifiers[1].price": "must be at least 0",
+		}},
+		{"unknown payment method", types.Invoice{Order_id: "o1", Payment_method: "CHEQUE"}, map[string]string{
+			"payment_method": "must be one of CARD, CASH",
+		}},
+		{"bad contact details", types.RegisterUser{FirstName: "Ada", LastName: "Lovelace", Email: "ada", Password: "secret", Phone: "call me"}, map[string]string{
+			"email": "must be a valid email address", "phone": "must be a valid phone number",
+		}},
+		{"valid phone", types.RegisterUser{FirstName: "Ada", LastName: "Lovelace", Email: "ada@example.com", Password: "secret", Phone: "+1-202-555-0143"}, nil},
+		{"empty permission", types.ApiKey{Name: "printer", Restaurant_id: "r1", Permissions: []string{"orders:read", ""}}, map[string]string{
+			"permissions[1]": "is required",
+		}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := Struct(test.req)
+			if test.want == nil {
+				if err != nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				return
+			}
+			if got := fields(t, err); !reflect.DeepEqual(got, test.want) {
+				t.Fatalf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+}